@@ -17,7 +17,9 @@ package cli
 
 import (
 	"github.com/sigstore/cosign/cmd/cosign/cli/attach"
+	"github.com/sigstore/cosign/cmd/cosign/cli/generate"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
 	"github.com/spf13/cobra"
 )
 
@@ -58,16 +60,39 @@ func attachSBOM() *cobra.Command {
 	o := &options.AttachSBOMOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "sbom",
-		Short:   "Attach sbom to the supplied container image",
-		Example: "  cosign attach sbom <image uri>",
-		Args:    cobra.ExactArgs(1),
+		Use:   "sbom",
+		Short: "Attach sbom to the supplied container image",
+		Example: `  cosign attach sbom <image uri>
+
+  # attach an sbom and sign it with a local key pair file
+  cosign attach sbom --sbom <sbom path> --sign --key cosign.key <image uri>
+
+  # attach an sbom and sign it with Google sign-in (experimental)
+  COSIGN_EXPERIMENTAL=1 cosign attach sbom --sbom <sbom path> --sign <image uri>`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			mediaType, err := o.MediaType()
 			if err != nil {
 				return err
 			}
-			return attach.SBOMCmd(cmd.Context(), o.Registry, o.SBOM, mediaType, args[0])
+			if !o.Sign {
+				return attach.SBOMCmd(cmd.Context(), o.Registry, o.SBOM, mediaType, args[0])
+			}
+			ko := sign.KeyOpts{
+				KeyRef:                   o.Key,
+				PassFunc:                 generate.GetPass,
+				Sk:                       o.SecurityKey.Use,
+				Slot:                     o.SecurityKey.Slot,
+				FulcioURL:                o.Fulcio.URL,
+				IDToken:                  o.Fulcio.IdentityToken,
+				InsecureSkipFulcioVerify: o.Fulcio.InsecureSkipFulcioVerify,
+				RekorURL:                 o.Rekor.URL,
+				OIDCIssuer:               o.OIDC.Issuer,
+				OIDCClientID:             o.OIDC.ClientID,
+				OIDCClientSecret:         o.OIDC.ClientSecret,
+				OIDCProvider:             o.OIDC.ProviderName(),
+			}
+			return attach.SignedSBOMCmd(cmd.Context(), ko, o.Registry, o.SBOM, mediaType, args[0], o.Cert, o.Force)
 		},
 	}
 
@@ -17,7 +17,6 @@ package attach
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -26,38 +25,70 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
 	"github.com/sigstore/cosign/pkg/oci/static"
 )
 
 func SBOMCmd(ctx context.Context, regOpts options.RegistryOptions, sbomRef string, sbomType types.MediaType, imageRef string) error {
-	ref, err := name.ParseReference(imageRef)
+	_, err := uploadSBOM(ctx, regOpts, sbomRef, sbomType, imageRef)
+	return err
+}
+
+// SignedSBOMCmd attaches an SBOM like SBOMCmd, and signs the uploaded SBOM
+// manifest so that "cosign download sbom --verify" and
+// "cosign verify --attachment sbom" can check who attached it.
+func SignedSBOMCmd(ctx context.Context, ko sign.KeyOpts, regOpts options.RegistryOptions, sbomRef string, sbomType types.MediaType, imageRef string, certPath string, force bool) error {
+	digest, err := uploadSBOM(ctx, regOpts, sbomRef, sbomType, imageRef)
 	if err != nil {
 		return err
 	}
 
+	// Sign the digest we just uploaded rather than the SBOM tag, which could
+	// have been moved since.
+	fmt.Fprintf(os.Stderr, "Signing SBOM [%s].\n", digest.Name())
+	if err := sign.SignCmd(ctx, ko, regOpts, nil, []string{digest.Name()}, certPath, true, "", "", "", force, false, ""); err != nil {
+		return errors.Wrap(err, "signing sbom")
+	}
+	return nil
+}
+
+func uploadSBOM(ctx context.Context, regOpts options.RegistryOptions, sbomRef string, sbomType types.MediaType, imageRef string) (name.Digest, error) {
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return name.Digest{}, err
+	}
+
 	b, err := sbomBytes(sbomRef)
 	if err != nil {
-		return err
+		return name.Digest{}, err
 	}
 
 	remoteOpts, err := regOpts.ClientOpts(ctx)
 	if err != nil {
-		return err
+		return name.Digest{}, err
 	}
 
 	dstRef, err := ociremote.SBOMTag(ref, remoteOpts...)
 	if err != nil {
-		return err
+		return name.Digest{}, err
 	}
 
 	fmt.Fprintf(os.Stderr, "Uploading SBOM file for [%s] to [%s] with mediaType [%s].\n", ref.Name(), dstRef.Name(), sbomType)
 	img, err := static.NewFile(b, static.WithLayerMediaType(sbomType))
 	if err != nil {
-		return err
+		return name.Digest{}, err
+	}
+	if err := remote.Write(dstRef, img, regOpts.GetRegistryClientOpts(ctx)...); err != nil {
+		return name.Digest{}, err
+	}
+	h, err := img.Digest()
+	if err != nil {
+		return name.Digest{}, err
 	}
-	return remote.Write(dstRef, img, regOpts.GetRegistryClientOpts(ctx)...)
+	return dstRef.Context().Digest(h.String()), nil
 }
 
 func sbomBytes(sbomRef string) ([]byte, error) {
@@ -0,0 +1,148 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attest
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
+	"github.com/sigstore/cosign/internal/pkg/cosign/payload"
+	"github.com/sigstore/cosign/pkg/blob"
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/pkg/types"
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/models"
+)
+
+// AttestBlobCmd creates an in-toto attestation whose subject is the sha256
+// digest of the blob at artifactPath, and writes the signed DSSE envelope
+// to outputAttestation, or stdout if empty.
+// nolint
+func AttestBlobCmd(ctx context.Context, ko sign.KeyOpts, artifactPath string, certPath string, predicatePath string,
+	predicateType string, outputAttestation string, outputCertificate string, timeout time.Duration) error {
+	// A key file or token is required unless we're in experimental mode!
+	if options.EnableExperimental() {
+		if options.NOf(ko.KeyRef, ko.Sk) > 1 {
+			return &options.KeyParseError{}
+		}
+	} else {
+		if !options.OneOf(ko.KeyRef, ko.Sk) {
+			return &options.KeyParseError{}
+		}
+	}
+
+	if _, err := options.ParsePredicateType(predicateType); err != nil {
+		return err
+	}
+
+	if timeout != 0 {
+		var cancelFn context.CancelFunc
+		ctx, cancelFn = context.WithTimeout(ctx, timeout)
+		defer cancelFn()
+	}
+
+	digest, err := fileDigest(artifactPath)
+	if err != nil {
+		return errors.Wrap(err, "hashing artifact")
+	}
+
+	sv, err := sign.SignerFromKeyOpts(ctx, certPath, ko)
+	if err != nil {
+		return errors.Wrap(err, "getting signer")
+	}
+	defer sv.Close()
+
+	fmt.Fprintln(os.Stderr, "Using payload from:", predicatePath)
+	predicate, err := os.Open(predicatePath)
+	if err != nil {
+		return err
+	}
+	defer predicate.Close()
+
+	sh, err := attestation.GenerateStatement(attestation.GenerateOpts{
+		Predicate: predicate,
+		Type:      predicateType,
+		Digest:    digest,
+		Repo:      filepath.Base(artifactPath),
+	})
+	if err != nil {
+		return err
+	}
+
+	statement, err := json.Marshal(sh)
+	if err != nil {
+		return err
+	}
+	att, _, err := payload.NewDSSEAttestor(types.IntotoPayloadType, sv).DSSEAttest(ctx, bytes.NewReader(statement))
+	if err != nil {
+		return errors.Wrap(err, "signing")
+	}
+	envelope, err := att.Payload()
+	if err != nil {
+		return err
+	}
+
+	if options.EnableExperimental() {
+		if _, err := uploadToTlog(ctx, sv, ko.RekorURL, func(r *client.Rekor, b []byte) (*models.LogEntryAnon, error) {
+			return cosign.TLogUploadInTotoAttestation(ctx, r, envelope, b)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if outputAttestation != "" {
+		if err := os.WriteFile(outputAttestation, envelope, 0600); err != nil {
+			return errors.Wrap(err, "create attestation file")
+		}
+		fmt.Fprintf(os.Stderr, "Attestation wrote in the file %s\n", outputAttestation)
+	} else {
+		fmt.Println(string(envelope))
+	}
+
+	if outputCertificate != "" && len(sv.Cert) > 0 {
+		if err := os.WriteFile(outputCertificate, sv.Cert, 0600); err != nil {
+			return errors.Wrap(err, "create certificate file")
+		}
+		fmt.Fprintf(os.Stderr, "Certificate wrote in the file %s\n", outputCertificate)
+	}
+	return nil
+}
+
+// fileDigest returns the hex encoded sha256 digest of the file at path.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	digest, err := blob.Digest(f, crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest), nil
+}
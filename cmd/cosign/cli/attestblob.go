@@ -0,0 +1,81 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/attest"
+	"github.com/sigstore/cosign/cmd/cosign/cli/generate"
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
+)
+
+func AttestBlob() *cobra.Command {
+	o := &options.AttestBlobOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "attest-blob",
+		Short: "Attest the supplied blob.",
+		Long: `Create an in-toto attestation whose subject is the sha256 digest of the supplied blob.
+The signed DSSE envelope is written to stdout, or to the file given by --output-attestation.`,
+		Example: `  cosign attest-blob --key <key path>|<kms uri> --predicate <path> [--type <type>] [--output-attestation <path>] <blob>
+
+  # attest a blob with Google sign-in (experimental)
+  COSIGN_EXPERIMENTAL=1 cosign attest-blob --predicate <FILE> --type <TYPE> --output-attestation <ATT> --output-certificate <CERT> <BLOB>
+
+  # attest a blob with a local key pair file
+  cosign attest-blob --predicate <FILE> --type <TYPE> --key cosign.key <BLOB>
+
+  # attest a blob with a key pair stored in Azure Key Vault
+  cosign attest-blob --predicate <FILE> --type <TYPE> --key azurekms://[VAULT_NAME][VAULT_URI]/[KEY] <BLOB>
+
+  # attest a blob with a key pair stored in AWS KMS
+  cosign attest-blob --predicate <FILE> --type <TYPE> --key awskms://[ENDPOINT]/[ID/ALIAS/ARN] <BLOB>
+
+  # attest a blob with a key pair stored in Google Cloud KMS
+  cosign attest-blob --predicate <FILE> --type <TYPE> --key gcpkms://projects/[PROJECT]/locations/global/keyRings/[KEYRING]/cryptoKeys/[KEY]/versions/[VERSION] <BLOB>
+
+  # attest a blob with a key pair stored in Hashicorp Vault
+  cosign attest-blob --predicate <FILE> --type <TYPE> --key hashivault://[KEY] <BLOB>`,
+
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ko := sign.KeyOpts{
+				KeyRef:                   o.Key,
+				PassFunc:                 generate.GetPass,
+				Sk:                       o.SecurityKey.Use,
+				Slot:                     o.SecurityKey.Slot,
+				FulcioURL:                o.Fulcio.URL,
+				IDToken:                  o.Fulcio.IdentityToken,
+				InsecureSkipFulcioVerify: o.Fulcio.InsecureSkipFulcioVerify,
+				RekorURL:                 o.Rekor.URL,
+				OIDCIssuer:               o.OIDC.Issuer,
+				OIDCClientID:             o.OIDC.ClientID,
+				OIDCClientSecret:         o.OIDC.ClientSecret,
+				OIDCProvider:             o.OIDC.ProviderName(),
+			}
+			if err := attest.AttestBlobCmd(cmd.Context(), ko, args[0], o.Cert, o.Predicate.Path,
+				o.Predicate.Type, o.OutputAttestation, o.OutputCertificate, o.Timeout); err != nil {
+				return errors.Wrapf(err, "attesting %s", args[0])
+			}
+			return nil
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
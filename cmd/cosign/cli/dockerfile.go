@@ -83,20 +83,34 @@ Shell-like variables in the Dockerfile's FROM lines will be substituted with val
 			if err != nil {
 				return err
 			}
+			annotationMatchers, err := o.AnnotationMatchers()
+			if err != nil {
+				return err
+			}
 			v := &dockerfile.VerifyDockerfileCommand{
 				VerifyCommand: verify.VerifyCommand{
-					RegistryOptions: o.Registry,
-					CheckClaims:     o.CheckClaims,
-					KeyRef:          o.Key,
-					CertRef:         o.CertVerify.Cert,
-					CertEmail:       o.CertVerify.CertEmail,
-					CertOidcIssuer:  o.CertVerify.CertOidcIssuer,
-					Sk:              o.SecurityKey.Use,
-					Slot:            o.SecurityKey.Slot,
-					Output:          o.Output,
-					RekorURL:        o.Rekor.URL,
-					Attachment:      o.Attachment,
-					Annotations:     annotations,
+					RegistryOptions:    o.Registry,
+					CheckClaims:        o.CheckClaims,
+					KeyRef:             o.Key,
+					CertRef:            o.CertVerify.Cert,
+					CertEmail:          o.CertVerify.CertEmail,
+					CertOidcIssuer:     o.CertVerify.CertOidcIssuer,
+					Sk:                 o.SecurityKey.Use,
+					Slot:               o.SecurityKey.Slot,
+					PIVAttestation:     o.PIVAttestation,
+					Output:             o.Output,
+					RekorURL:           o.Rekor.URL,
+					Attachment:         o.Attachment,
+					Annotations:        annotations,
+					AnnotationMatchers: annotationMatchers,
+					MediaType:          o.MediaType,
+					Recursive:          o.Recursive,
+					Platform:           o.Platform,
+					StrictIdentity:     o.StrictIdentity,
+					IdentityAliases:    o.IdentityAliases,
+					MaxAge:             o.MaxAge,
+					LegacySHA256:       o.LegacySHA256,
+					RevocationList:     o.RevocationList,
 				},
 				BaseOnly: o.BaseImageOnly,
 			}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/download"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/verify"
 )
 
 func Download() *cobra.Command {
@@ -56,15 +57,36 @@ func downloadSignature() *cobra.Command {
 }
 
 func downloadSBOM() *cobra.Command {
-	o := &options.RegistryOptions{}
+	o := &options.DownloadSBOMOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "sbom",
-		Short:   "Download SBOMs from the supplied container image",
-		Example: "  cosign download sbom <image uri>",
-		Args:    cobra.ExactArgs(1),
+		Use:   "sbom",
+		Short: "Download SBOMs from the supplied container image",
+		Example: `  cosign download sbom <image uri>
+
+  # only download the sbom if it was signed with a given key
+  cosign download sbom --verify --key cosign.pub <image uri>
+
+  # only download the sbom if it was signed by a given identity (experimental)
+  COSIGN_EXPERIMENTAL=1 cosign download sbom --verify --cert-email <email> --cert-oidc-issuer <issuer> <image uri>`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			_, err := download.SBOMCmd(cmd.Context(), *o, args[0], cmd.OutOrStdout())
+			if !o.Verify {
+				_, err := download.SBOMCmd(cmd.Context(), o.Registry, args[0], cmd.OutOrStdout())
+				return err
+			}
+			v := &verify.VerifyCommand{
+				RegistryOptions: o.Registry,
+				CheckClaims:     o.CheckClaims,
+				KeyRef:          o.Key,
+				CertRef:         o.CertVerify.Cert,
+				CertEmail:       o.CertVerify.CertEmail,
+				CertOidcIssuer:  o.CertVerify.CertOidcIssuer,
+				Sk:              o.SecurityKey.Use,
+				Slot:            o.SecurityKey.Slot,
+				RekorURL:        o.Rekor.URL,
+			}
+			_, err := download.VerifiedSBOMCmd(cmd.Context(), v, args[0], cmd.OutOrStdout())
 			return err
 		},
 	}
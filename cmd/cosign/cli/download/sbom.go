@@ -22,11 +22,29 @@ import (
 	"os"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/verify"
+	"github.com/sigstore/cosign/pkg/cosign"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
 )
 
 func SBOMCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef string, out io.Writer) ([]string, error) {
+	return sbom(ctx, regOpts, nil, imageRef, out)
+}
+
+// VerifiedSBOMCmd downloads the SBOM of imageRef like SBOMCmd, but only
+// writes it out once a signature of the SBOM manifest was verified with c.
+func VerifiedSBOMCmd(ctx context.Context, c *verify.VerifyCommand, imageRef string, out io.Writer) ([]string, error) {
+	co, closeKeys, err := c.CheckOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeKeys()
+	return sbom(ctx, c.RegistryOptions, co, imageRef, out)
+}
+
+func sbom(ctx context.Context, regOpts options.RegistryOptions, co *cosign.CheckOpts, imageRef string, out io.Writer) ([]string, error) {
 	ref, err := name.ParseReference(imageRef)
 	if err != nil {
 		return nil, err
@@ -47,6 +65,25 @@ func SBOMCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef stri
 		return nil, err
 	}
 
+	if co != nil {
+		// Verify the manifest we fetched, whose layer is fetched by digest,
+		// rather than resolving the SBOM tag again.
+		sbomTag, err := ociremote.SBOMTag(ref, ociremoteOpts...)
+		if err != nil {
+			return nil, err
+		}
+		h, err := file.Digest()
+		if err != nil {
+			return nil, err
+		}
+		sbomRef := sbomTag.Context().Digest(h.String())
+		_, bundleVerified, err := cosign.VerifyImageSignatures(ctx, sbomRef, co)
+		if err != nil {
+			return nil, errors.Wrapf(err, "verifying sbom %s", sbomRef)
+		}
+		verify.PrintVerificationHeader(sbomRef.Name(), co, bundleVerified)
+	}
+
 	// "attach sbom" attaches a single static.NewFile
 	sboms := make([]string, 0, 1)
 
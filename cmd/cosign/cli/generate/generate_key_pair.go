@@ -22,6 +22,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sigstore/cosign/pkg/cosign/git"
@@ -40,7 +41,17 @@ var (
 )
 
 // nolint
-func GenerateKeyPairCmd(ctx context.Context, kmsVal string, args []string) error {
+func GenerateKeyPairCmd(ctx context.Context, kmsVal, keyAlgorithm string, rotate bool, rotateExpiry time.Duration, args []string) error {
+	if keyAlgorithm == "" {
+		keyAlgorithm = cosign.DefaultKeyAlgorithm
+	}
+	if keyAlgorithm != cosign.DefaultKeyAlgorithm && (kmsVal != "" || len(args) > 0) {
+		return errors.New("--key-algorithm is only supported for key pairs written to disk")
+	}
+	if rotate && len(args) == 0 {
+		return errors.New("--rotate is only supported for key pairs in Kubernetes secrets and git providers")
+	}
+
 	if kmsVal != "" {
 		k, err := kms.Get(ctx, kmsVal, crypto.SHA256)
 		if err != nil {
@@ -72,15 +83,21 @@ func GenerateKeyPairCmd(ctx context.Context, kmsVal string, args []string) error
 
 		switch provider {
 		case "k8s":
+			if rotate {
+				return kubernetes.RotateKeyPairSecret(ctx, targetRef, GetPass, rotateExpiry)
+			}
 			return kubernetes.KeyPairSecret(ctx, targetRef, GetPass)
 		case gitlab.ReferenceScheme, github.ReferenceScheme:
+			if rotate {
+				return git.GetProvider(provider).RotateSecret(ctx, targetRef, GetPass, rotateExpiry)
+			}
 			return git.GetProvider(provider).PutSecret(ctx, targetRef, GetPass)
 		}
 
 		return fmt.Errorf("undefined provider: %s", provider)
 	}
 
-	keys, err := cosign.GenerateKeyPair(GetPass)
+	keys, err := cosign.GenerateKeyPairWithAlgorithm(GetPass, keyAlgorithm)
 	if err != nil {
 		return err
 	}
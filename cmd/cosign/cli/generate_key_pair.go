@@ -29,11 +29,14 @@ func GenerateKeyPair() *cobra.Command {
 		Use:   "generate-key-pair",
 		Short: "Generates a key-pair.",
 		Long:  "Generates a key-pair for signing.",
-		Example: `  cosign generate-key-pair [--kms KMSPATH]
+		Example: `  cosign generate-key-pair [--kms KMSPATH] [--key-algorithm ALGORITHM] [--rotate [--rotate-expiry DURATION]]
 
   # generate key-pair and write to cosign.key and cosign.pub files
   cosign generate-key-pair
 
+  # generate an ECDSA P-384 key-pair, whose signatures use SHA-384
+  cosign generate-key-pair --key-algorithm ecdsa-p384
+
   # generate a key-pair in Azure Key Vault
   cosign generate-key-pair --kms azurekms://[VAULT_NAME][VAULT_URI]/[KEY]
 
@@ -58,12 +61,15 @@ func GenerateKeyPair() *cobra.Command {
   # generate a key-pair in GitLab with project id
   cosign generate-key-pair gitlab://[PROJECT_ID]
 
+  # rotate the key-pair of a Kubernetes Secret, still accepting the previous public key for 3 days
+  cosign generate-key-pair --rotate --rotate-expiry 72h k8s://[NAMESPACE]/[NAME]
+
 CAVEATS:
   This command interactively prompts for a password. You can use
   the COSIGN_PASSWORD environment variable to provide one.`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return generate.GenerateKeyPairCmd(cmd.Context(), o.KMS, args)
+			return generate.GenerateKeyPairCmd(cmd.Context(), o.KMS, o.KeyAlgorithm, o.Rotate, o.RotateExpiry, args)
 		},
 	}
 
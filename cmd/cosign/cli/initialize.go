@@ -37,6 +37,17 @@ The following options are used by default:
 To provide an out-of-band trusted initial root.json, use the -root flag with a file or URL reference.
 This will enable you to point cosign to a separate TUF root.
 
+The -mirror flag selects the remote TUF repository by URL scheme:
+ - http:// and https:// URLs of repositories served over HTTP(S),
+ - file:// URLs of repositories in a local directory, e.g. for air-gapped environments,
+ - oci:// references of repositories stored as OCI artifacts in a registry,
+ - gs:// URLs, or plain names, of GCS buckets.
+Later commands keep using the mirror given to initialize.
+
+Verifications update expired TUF metadata from the mirror before using it. With -offline, or
+SIGSTORE_TUF_OFFLINE=true, they never contact the mirror and fail closed once the metadata expires
+instead; use "cosign tuf refresh" to update it explicitly.
+
 Any updated TUF repository will be written to $HOME/.sigstore/root/.
 
 Trusted keys and certificate used in cosign verification (e.g. verifying Fulcio issued certificates
@@ -50,9 +61,16 @@ cosign initialize
 cosign initialize -root <url>
 
 # initialize with an out-of-band root key file and custom repository mirror.
-cosign initialize -mirror <url> -root <url>`,
+cosign initialize -mirror <url> -root <url>
+
+# initialize from a repository stored in a local directory, or in a registry.
+cosign initialize -mirror file:///path/to/repository -root <url>
+cosign initialize -mirror oci://registry.example.com/tuf/repository:latest -root <url>
+
+# initialize without updating the metadata before verifications.
+cosign initialize -offline`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return initialize.DoInitialize(cmd.Context(), o.Root, o.Mirror)
+			return initialize.DoInitialize(cmd.Context(), o.Root, o.Mirror, o.Offline)
 		},
 	}
 
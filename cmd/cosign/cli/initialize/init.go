@@ -18,14 +18,12 @@ package initialize
 import (
 	"context"
 	_ "embed" // To enable the `go:embed` directive.
-	"net/url"
 
 	"github.com/sigstore/cosign/pkg/blob"
 	"github.com/sigstore/cosign/pkg/cosign/tuf"
-	"github.com/theupdateframework/go-tuf/client"
 )
 
-func DoInitialize(ctx context.Context, root, mirror string) error {
+func DoInitialize(ctx context.Context, root, mirror string, offline bool) error {
 	// Get the initial trusted root contents.
 	var rootFileBytes []byte
 	var err error
@@ -37,15 +35,5 @@ func DoInitialize(ctx context.Context, root, mirror string) error {
 	}
 
 	// Initialize the remote repository.
-	var remote client.RemoteStore
-	if _, parseErr := url.ParseRequestURI(mirror); parseErr != nil {
-		remote, err = tuf.GcsRemoteStore(ctx, mirror, nil, nil)
-	} else {
-		remote, err = client.HTTPRemoteStore(mirror, nil, nil)
-	}
-	if err != nil {
-		return err
-	}
-
-	return tuf.Initialize(remote, rootFileBytes)
+	return tuf.Initialize(ctx, mirror, rootFileBytes, offline)
 }
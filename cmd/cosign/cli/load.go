@@ -17,12 +17,18 @@ package cli
 
 import (
 	"context"
+	"fmt"
+	"sort"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/pkg/errors"
+	"github.com/sigstore/cosign/cmd/cosign/cli/fulcio"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/rekor"
+	"github.com/sigstore/cosign/pkg/cosign"
 	"github.com/sigstore/cosign/pkg/oci/layout"
 	"github.com/sigstore/cosign/pkg/oci/remote"
+	sigs "github.com/sigstore/cosign/pkg/signature"
 	"github.com/spf13/cobra"
 )
 
@@ -30,13 +36,29 @@ func Load() *cobra.Command {
 	o := &options.LoadOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "load",
-		Short:   "Load a signed image on disk to a remote registry",
-		Long:    "Load a signed image on disk to a remote registry",
-		Example: `  cosign load --dir <path to directory> <IMAGE>`,
-		Args:    cobra.ExactArgs(1),
+		Use:   "load",
+		Short: "Load a signed image on disk to a remote registry",
+		Long: `Load a signed image on disk to a remote registry.
+
+When the directory holds several images saved by 'cosign save', the image
+argument may be omitted to load each image to the reference it was saved from.
+
+If a key or certificate identity is provided, the signatures of every image are
+verified before anything is pushed.`,
+		Example: `  cosign load --dir <path to directory> <IMAGE>
+
+  # load every image to the reference it was saved from
+  cosign load --dir <path to directory>
+
+  # verify signatures and attestations with a public key before loading
+  cosign load --dir <path to directory> --key cosign.pub --verify-attestations <IMAGE>`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return LoadCmd(cmd.Context(), *o, args[0])
+			imageRef := ""
+			if len(args) == 1 {
+				imageRef = args[0]
+			}
+			return LoadCmd(cmd.Context(), *o, imageRef)
 		},
 	}
 
@@ -44,16 +66,97 @@ func Load() *cobra.Command {
 	return cmd
 }
 
+// LoadCmd pushes the images saved in opts.Directory, along with their
+// signatures, attestations and attachments. If imageRef is empty, each
+// image is pushed to the reference it was saved from.
 func LoadCmd(ctx context.Context, opts options.LoadOptions, imageRef string) error {
-	ref, err := name.ParseReference(imageRef)
+	// get the signed images from disk
+	entities, err := layout.SignedEntities(opts.Directory)
 	if err != nil {
-		return errors.Wrapf(err, "parsing image name %s", imageRef)
+		return errors.Wrap(err, "signed entities")
+	}
+	if len(entities) == 0 {
+		return fmt.Errorf("no images found in %s", opts.Directory)
+	}
+	if imageRef != "" && len(entities) != 1 {
+		return fmt.Errorf("%s holds %d images, omit the image to load each to the reference it was saved from", opts.Directory, len(entities))
 	}
 
-	// get the signed image from disk
-	sii, err := layout.SignedImageIndex(opts.Directory)
-	if err != nil {
-		return errors.Wrap(err, "signed image index")
+	refNames := make([]string, 0, len(entities))
+	for refName := range entities {
+		refNames = append(refNames, refName)
+	}
+	sort.Strings(refNames)
+
+	refs := make(map[string]name.Reference, len(entities))
+	for _, refName := range refNames {
+		dst := imageRef
+		if dst == "" {
+			dst = refName
+		}
+		if dst == "" {
+			return errors.New("image was saved without a reference, specify the image to load it to")
+		}
+		ref, err := name.ParseReference(dst)
+		if err != nil {
+			return errors.Wrapf(err, "parsing image name %s", dst)
+		}
+		refs[refName] = ref
+	}
+
+	// Verify everything before pushing anything.
+	if opts.Verify() {
+		co, err := loadCheckOpts(ctx, opts)
+		if err != nil {
+			return err
+		}
+		// Signatures and attestations claim the image digest differently.
+		sigCo, attCo := *co, *co
+		sigCo.ClaimVerifier = cosign.SimpleClaimVerifier
+		attCo.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
+		for _, refName := range refNames {
+			se := entities[refName]
+			if _, _, err := cosign.VerifyEntitySignatures(ctx, se, &sigCo); err != nil {
+				return errors.Wrapf(err, "verifying signatures for %s", refs[refName].Name())
+			}
+			if opts.VerifyAttestations {
+				if _, _, err := cosign.VerifyEntityAttestations(ctx, se, &attCo); err != nil {
+					return errors.Wrapf(err, "verifying attestations for %s", refs[refName].Name())
+				}
+			}
+		}
+	}
+
+	for _, refName := range refNames {
+		if err := remote.WriteSignedEntity(ctx, refs[refName], entities[refName], layout.AttachmentNames); err != nil {
+			return errors.Wrapf(err, "loading %s", refs[refName].Name())
+		}
+	}
+	return nil
+}
+
+func loadCheckOpts(ctx context.Context, opts options.LoadOptions) (*cosign.CheckOpts, error) {
+	co := &cosign.CheckOpts{
+		CertEmail:      opts.CertEmail,
+		CertOidcIssuer: opts.CertOidcIssuer,
+	}
+	if opts.Key != "" {
+		pubKey, err := sigs.PublicKeyFromKeyRef(ctx, opts.Key)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading public key")
+		}
+		co.SigVerifier = pubKey
+	} else {
+		co.RootCerts = fulcio.GetRoots()
+		// Without a bundle, the certificate is only valid if the signature
+		// was logged while it was.
+		if opts.Rekor.URL != "" {
+			rekorClient, err := rekor.NewClient(opts.Rekor.URL)
+			if err != nil {
+				return nil, errors.Wrap(err, "creating Rekor client")
+			}
+			co.RekorClient = rekorClient
+		}
 	}
-	return remote.WriteSignedImageIndexImages(ref, sii)
+	return co, nil
 }
@@ -21,6 +21,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/sigstore/cosign/pkg/cosign"
 	sigs "github.com/sigstore/cosign/pkg/signature"
 )
 
@@ -51,3 +52,46 @@ func (o *AnnotationOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringSliceVarP(&o.Annotations, "annotations", "a", nil,
 		"extra key=value pairs to sign")
 }
+
+// AnnotationMatchOptions is the wrapper for the annotations to match with
+// patterns when verifying.
+type AnnotationMatchOptions struct {
+	AnnotationGlobs   []string
+	AnnotationRegexps []string
+}
+
+var _ Interface = (*AnnotationMatchOptions)(nil)
+
+// AnnotationMatchers returns the matchers of the key=pattern flags.
+func (o *AnnotationMatchOptions) AnnotationMatchers() ([]cosign.AnnotationMatcher, error) {
+	var matchers []cosign.AnnotationMatcher
+	for _, flags := range []struct {
+		values []string
+		parse  func(key, pattern string) (cosign.AnnotationMatcher, error)
+	}{
+		{o.AnnotationGlobs, cosign.GlobAnnotationMatcher},
+		{o.AnnotationRegexps, cosign.RegexpAnnotationMatcher},
+	} {
+		for _, a := range flags.values {
+			kv := strings.SplitN(a, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("unable to parse annotation pattern: %s", a)
+			}
+			m, err := flags.parse(kv[0], kv[1])
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, m)
+		}
+	}
+	return matchers, nil
+}
+
+// AddFlags implements Interface
+func (o *AnnotationMatchOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&o.AnnotationGlobs, "annotation-glob", nil,
+		"key=glob pairs of annotations the signatures must have, with a value matching the glob, where * matches any characters")
+
+	cmd.Flags().StringArrayVar(&o.AnnotationRegexps, "annotation-regexp", nil,
+		"key=regexp pairs of annotations the signatures must have, with a value fully matching the regular expression")
+}
@@ -48,7 +48,16 @@ func (o *AttachSignatureOptions) AddFlags(cmd *cobra.Command) {
 type AttachSBOMOptions struct {
 	SBOM     string
 	SBOMType string
-	Registry RegistryOptions
+	Sign     bool
+	Key      string
+	Cert     string
+	Force    bool
+
+	Rekor       RekorOptions
+	Fulcio      FulcioOptions
+	OIDC        OIDCOptions
+	SecurityKey SecurityKeyOptions
+	Registry    RegistryOptions
 }
 
 var _ Interface = (*AttachSBOMOptions)(nil)
@@ -62,6 +71,23 @@ func (o *AttachSBOMOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.SBOMType, "type", "spdx",
 		"type of sbom (spdx|cyclonedx|syft)")
+
+	cmd.Flags().BoolVar(&o.Sign, "sign", false,
+		"whether to sign the uploaded sbom, using the same signing flags as 'cosign sign'")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or Kubernetes Secret, used with --sign")
+
+	cmd.Flags().StringVar(&o.Cert, "cert", "",
+		"path to the x509 certificate to include in the Signature, used with --sign")
+
+	cmd.Flags().BoolVarP(&o.Force, "force", "f", false,
+		"skip warnings and confirmations")
+
+	o.Rekor.AddFlags(cmd)
+	o.Fulcio.AddFlags(cmd)
+	o.OIDC.AddFlags(cmd)
+	o.SecurityKey.AddFlags(cmd)
 }
 
 func (o *AttachSBOMOptions) MediaType() (types.MediaType, error) {
@@ -0,0 +1,51 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// DownloadSBOMOptions is the top level wrapper for the download sbom command.
+type DownloadSBOMOptions struct {
+	Verify      bool
+	Key         string
+	CheckClaims bool
+
+	SecurityKey SecurityKeyOptions
+	CertVerify  CertVerifyOptions
+	Rekor       RekorOptions
+	Registry    RegistryOptions
+}
+
+var _ Interface = (*DownloadSBOMOptions)(nil)
+
+// AddFlags implements Interface
+func (o *DownloadSBOMOptions) AddFlags(cmd *cobra.Command) {
+	o.SecurityKey.AddFlags(cmd)
+	o.CertVerify.AddFlags(cmd)
+	o.Rekor.AddFlags(cmd)
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().BoolVar(&o.Verify, "verify", false,
+		"verify the signature of the sbom before writing it out, using the same verification flags as 'cosign verify'")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret, used with --verify")
+
+	cmd.Flags().BoolVar(&o.CheckClaims, "check-claims", true,
+		"whether to check the claims found, used with --verify")
+}
@@ -16,13 +16,25 @@
 package options
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/pkg/cosign"
 )
 
 // GenerateKeyPairOptions is the top level wrapper for the generate-key-pair command.
 type GenerateKeyPairOptions struct {
 	// KMS Key Management Service
 	KMS string
+	// KeyAlgorithm is the algorithm of a key pair written to disk
+	KeyAlgorithm string
+	// Rotate keeps accepting the public key of the key pair being replaced
+	Rotate bool
+	// RotateExpiry is how long the public key of the key pair being replaced is accepted
+	RotateExpiry time.Duration
 }
 
 var _ Interface = (*GenerateKeyPairOptions)(nil)
@@ -31,4 +43,14 @@ var _ Interface = (*GenerateKeyPairOptions)(nil)
 func (o *GenerateKeyPairOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.KMS, "kms", "",
 		"create key pair in KMS service to use for signing")
+
+	cmd.Flags().StringVar(&o.KeyAlgorithm, "key-algorithm", cosign.DefaultKeyAlgorithm,
+		fmt.Sprintf("algorithm of the generated key pair (%s)", strings.Join(cosign.KeyAlgorithms, "|")))
+
+	cmd.Flags().BoolVar(&o.Rotate, "rotate", false,
+		"replace the key pair of a Kubernetes secret or git provider, keeping its public key as cosign.pub.1 (or COSIGN_PUBLIC_KEY_1), "+
+			"which verifiers accept until --rotate-expiry")
+
+	cmd.Flags().DurationVar(&o.RotateExpiry, "rotate-expiry", cosign.DefaultRotationExpiry,
+		"how long the public key of a key pair replaced with --rotate is still accepted")
 }
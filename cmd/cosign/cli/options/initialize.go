@@ -21,8 +21,9 @@ import (
 
 // InitializeOptions is the top level wrapper for the initialize command.
 type InitializeOptions struct {
-	Mirror string
-	Root   string
+	Mirror  string
+	Root    string
+	Offline bool
 }
 
 var _ Interface = (*InitializeOptions)(nil)
@@ -30,8 +31,12 @@ var _ Interface = (*InitializeOptions)(nil)
 // AddFlags implements Interface
 func (o *InitializeOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Mirror, "mirror", "sigstore-tuf-root",
-		"GCS bucket to a SigStore TUF repository or HTTP(S) base URL")
+		"GCS bucket to a SigStore TUF repository, or HTTP(S) base URL, file:// path or oci:// reference of a TUF repository")
 
 	cmd.Flags().StringVar(&o.Root, "root", "",
 		"path to trusted initial root. defaults to embedded root")
+
+	cmd.Flags().BoolVar(&o.Offline, "offline", false,
+		"never update the TUF metadata after initializing it, failing verifications once it expires "+
+			"instead, until `cosign tuf refresh` or another `cosign initialize`")
 }
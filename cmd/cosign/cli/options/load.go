@@ -21,14 +21,39 @@ import (
 
 // LoadOptions is the top level wrapper for the load command.
 type LoadOptions struct {
-	Directory string
+	Directory          string
+	Key                string
+	CertEmail          string
+	CertOidcIssuer     string
+	VerifyAttestations bool
+
+	Rekor RekorOptions
 }
 
 var _ Interface = (*LoadOptions)(nil)
 
 // AddFlags implements Interface
 func (o *LoadOptions) AddFlags(cmd *cobra.Command) {
+	o.Rekor.AddFlags(cmd)
+
 	cmd.Flags().StringVar(&o.Directory, "dir", "",
 		"path to directory where the signed image is stored on disk")
 	_ = cmd.MarkFlagRequired("dir")
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret to verify signatures with before loading")
+
+	cmd.Flags().StringVar(&o.CertEmail, "cert-email", "",
+		"the email expected in a valid Fulcio certificate to verify signatures with before loading")
+
+	cmd.Flags().StringVar(&o.CertOidcIssuer, "cert-oidc-issuer", "",
+		"the OIDC issuer expected in a valid Fulcio certificate, e.g. https://token.actions.githubusercontent.com or https://oauth2.sigstore.dev/auth")
+
+	cmd.Flags().BoolVar(&o.VerifyAttestations, "verify-attestations", false,
+		"whether to also require valid attestations before loading")
+}
+
+// Verify returns true if signatures should be verified before loading.
+func (o *LoadOptions) Verify() bool {
+	return o.Key != "" || o.CertEmail != "" || o.CertOidcIssuer != ""
 }
@@ -16,14 +16,21 @@
 package options
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
 )
 
+// OIDCProviderEnv forces the provider of ambient OIDC credentials when
+// --oidc-provider isn't set.
+const OIDCProviderEnv = "COSIGN_OIDC_PROVIDER"
+
 // OIDCOptions is the wrapper for OIDC related options.
 type OIDCOptions struct {
 	Issuer       string
 	ClientID     string
 	ClientSecret string
+	Provider     string
 }
 
 var _ Interface = (*OIDCOptions)(nil)
@@ -38,4 +45,17 @@ func (o *OIDCOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.ClientSecret, "oidc-client-secret", "",
 		"[EXPERIMENTAL] OIDC client secret for application")
+
+	cmd.Flags().StringVar(&o.Provider, "oidc-provider", "",
+		"[EXPERIMENTAL] provider of ambient OIDC credentials to use instead of detecting one, "+
+			"uses environment variable "+OIDCProviderEnv+" if empty")
+}
+
+// ProviderName returns the provider of ambient OIDC credentials the flags or
+// the environment force, if any.
+func (o *OIDCOptions) ProviderName() string {
+	if o.Provider != "" {
+		return o.Provider
+	}
+	return os.Getenv(OIDCProviderEnv)
 }
@@ -16,7 +16,13 @@
 package options
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
 )
 
 // PKCS11ToolListTokens is the wrapper for `pkcs11-tool list-tokens` related options.
@@ -52,3 +58,70 @@ func (o *PKCS11ToolListKeysUrisOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Pin, "pin", "",
 		"pin of the PKCS11 slot, uses environment variable COSIGN_PKCS11_PIN if empty")
 }
+
+// PKCS11ToolGenerateKeyOptions is the wrapper for `pkcs11-tool generate-key` related options.
+type PKCS11ToolGenerateKeyOptions struct {
+	ModulePath   string
+	SlotID       uint
+	Pin          string
+	KeyLabel     string
+	KeyID        string
+	KeyAlgorithm string
+}
+
+var _ Interface = (*PKCS11ToolGenerateKeyOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PKCS11ToolGenerateKeyOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.ModulePath, "module-path", "",
+		"absolute path to the PKCS11 module")
+
+	cmd.Flags().UintVar(&o.SlotID, "slot-id", 0,
+		"id of the PKCS11 slot, uses 0 if empty")
+
+	cmd.Flags().StringVar(&o.Pin, "pin", "",
+		"pin of the PKCS11 slot, uses environment variable COSIGN_PKCS11_PIN if empty")
+
+	cmd.Flags().StringVar(&o.KeyLabel, "key-label", "",
+		"label of the generated key")
+
+	cmd.Flags().StringVar(&o.KeyID, "key-id", "",
+		"hex encoded id of the generated key, a random one is used if empty")
+
+	cmd.Flags().StringVar(&o.KeyAlgorithm, "key-algorithm", cosign.DefaultKeyAlgorithm,
+		fmt.Sprintf("algorithm of the generated key (%s)", strings.Join(pkcs11key.KeyAlgorithms, "|")))
+}
+
+// PKCS11ToolImportCertOptions is the wrapper for `pkcs11-tool import-cert` related options.
+type PKCS11ToolImportCertOptions struct {
+	ModulePath string
+	SlotID     uint
+	Pin        string
+	KeyLabel   string
+	KeyID      string
+	Cert       string
+}
+
+var _ Interface = (*PKCS11ToolImportCertOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PKCS11ToolImportCertOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.ModulePath, "module-path", "",
+		"absolute path to the PKCS11 module")
+
+	cmd.Flags().UintVar(&o.SlotID, "slot-id", 0,
+		"id of the PKCS11 slot, uses 0 if empty")
+
+	cmd.Flags().StringVar(&o.Pin, "pin", "",
+		"pin of the PKCS11 slot, uses environment variable COSIGN_PKCS11_PIN if empty")
+
+	cmd.Flags().StringVar(&o.KeyLabel, "key-label", "",
+		"label of the key the certificate is for")
+
+	cmd.Flags().StringVar(&o.KeyID, "key-id", "",
+		"hex encoded id of the key the certificate is for")
+
+	cmd.Flags().StringVar(&o.Cert, "cert", "",
+		"path to the PEM certificate of the key, e.g. from Fulcio or an internal CA")
+	_ = cmd.MarkFlagRequired("cert")
+}
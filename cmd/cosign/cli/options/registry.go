@@ -17,6 +17,7 @@ package options
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net/http"
 
 	"github.com/google/go-containerregistry/pkg/authn"
@@ -31,6 +32,7 @@ import (
 type RegistryOptions struct {
 	AllowInsecure      bool
 	KubernetesKeychain bool
+	StorageMode        string
 	RefOpts            ReferenceOptions
 }
 
@@ -44,11 +46,27 @@ func (o *RegistryOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&o.KubernetesKeychain, "k8s-keychain", false,
 		"whether to use the kubernetes keychain instead of the default keychain (supports workload identity).")
 
+	cmd.Flags().StringVar(&o.StorageMode, "storage-mode", string(ociremote.StorageModeTags),
+		"how signatures and attestations are stored in the registry: 'tags' for the sha256-<digest>.sig tag scheme, "+
+			"'referrers' for one artifact manifest each, discovered through the OCI Referrers API")
+
 	o.RefOpts.AddFlags(cmd)
 }
 
 func (o *RegistryOptions) ClientOpts(ctx context.Context) ([]ociremote.Option, error) {
-	opts := []ociremote.Option{ociremote.WithRemoteOptions(o.GetRegistryClientOpts(ctx)...)}
+	opts := []ociremote.Option{
+		ociremote.WithRemoteOptions(o.GetRegistryClientOpts(ctx)...),
+		ociremote.WithContext(ctx),
+		ociremote.WithKeychain(o.keychain(ctx)),
+		ociremote.WithTransport(o.transport()),
+	}
+	switch mode := ociremote.StorageMode(o.StorageMode); mode {
+	case "", ociremote.StorageModeTags:
+	case ociremote.StorageModeReferrers:
+		opts = append(opts, ociremote.WithStorageMode(mode))
+	default:
+		return nil, fmt.Errorf("invalid --storage-mode %q, expected %q or %q", o.StorageMode, ociremote.StorageModeTags, ociremote.StorageModeReferrers)
+	}
 	if o.RefOpts.TagPrefix != "" {
 		opts = append(opts, ociremote.WithPrefix(o.RefOpts.TagPrefix))
 	}
@@ -66,20 +84,31 @@ func (o *RegistryOptions) GetRegistryClientOpts(ctx context.Context) []remote.Op
 	opts := []remote.Option{
 		remote.WithContext(ctx),
 		remote.WithUserAgent(UserAgent()),
+		remote.WithAuthFromKeychain(o.keychain(ctx)),
+	}
+
+	if o != nil && o.AllowInsecure {
+		opts = append(opts, remote.WithTransport(o.transport()))
 	}
+	return opts
+}
 
-	if o.KubernetesKeychain {
+// keychain returns the keychain registry credentials are resolved with.
+func (o *RegistryOptions) keychain(ctx context.Context) authn.Keychain {
+	if o != nil && o.KubernetesKeychain {
 		kc, err := k8schain.NewNoClient(ctx)
 		if err != nil {
 			panic(err.Error())
 		}
-		opts = append(opts, remote.WithAuthFromKeychain(kc))
-	} else {
-		opts = append(opts, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+		return kc
 	}
+	return authn.DefaultKeychain
+}
 
+// transport returns the transport registries are reached through.
+func (o *RegistryOptions) transport() http.RoundTripper {
 	if o != nil && o.AllowInsecure {
-		opts = append(opts, remote.WithTransport(&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}})) // #nosec G402
+		return &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}} // #nosec G402
 	}
-	return opts
+	return remote.DefaultTransport
 }
@@ -0,0 +1,78 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+)
+
+// RevocationListOptions is the wrapper for the options rejecting the
+// signatures revoked by a signed revocation list.
+type RevocationListOptions struct {
+	Source    string
+	Key       string
+	MinIssued string
+}
+
+var _ Interface = (*RevocationListOptions)(nil)
+
+// AddFlags implements Interface
+func (o *RevocationListOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Source, "revocation-list", "",
+		"signed revocation list of the signatures to reject: a file path, oci://<image> or tuf://<target>")
+
+	cmd.Flags().StringVar(&o.Key, "revocation-list-key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret the revocation list is signed with, required with --revocation-list")
+
+	cmd.Flags().StringVar(&o.MinIssued, "revocation-list-min-issued", "",
+		"reject revocation lists issued before this RFC 3339 time, e.g. that of the last list seen, so an older list can't be replayed")
+}
+
+// RevocationListSignOptions is the top level wrapper for the `revocation-list sign` command.
+type RevocationListSignOptions struct {
+	Key         string
+	Expiry      time.Duration
+	SecurityKey SecurityKeyOptions
+}
+
+var _ Interface = (*RevocationListSignOptions)(nil)
+
+// AddFlags implements Interface
+func (o *RevocationListSignOptions) AddFlags(cmd *cobra.Command) {
+	o.SecurityKey.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or Kubernetes Secret")
+
+	cmd.Flags().DurationVar(&o.Expiry, "expiry", cosign.DefaultRevocationListExpiry,
+		"how long the revocation list is valid after it is issued, unless it has an expires time")
+}
+
+// RevocationListUploadOptions is the top level wrapper for the `revocation-list upload` command.
+type RevocationListUploadOptions struct {
+	Registry RegistryOptions
+}
+
+var _ Interface = (*RevocationListUploadOptions)(nil)
+
+// AddFlags implements Interface
+func (o *RevocationListUploadOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+}
@@ -19,7 +19,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// SaveOptions is the top level wrapper for the load command.
+// SaveOptions is the top level wrapper for the save command.
 type SaveOptions struct {
 	Directory string
 }
@@ -29,6 +29,6 @@ var _ Interface = (*SaveOptions)(nil)
 // AddFlags implements Interface
 func (o *SaveOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Directory, "dir", "",
-		"path to dir where the signed image should be stored on disk")
+		"path to dir where the signed images should be stored on disk")
 	_ = cmd.MarkFlagRequired("dir")
 }
@@ -35,3 +35,25 @@ func (o *SecurityKeyOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.Slot, "slot", "",
 		"security key slot to use for generated key (default: signature) (authentication|signature|card-authentication|key-management)")
 }
+
+// PIVAttestationOptions is the wrapper for the options requiring signatures to
+// be made with a key generated on a PIV hardware token.
+type PIVAttestationOptions struct {
+	Root          string
+	PINPolicies   []string
+	TouchPolicies []string
+}
+
+var _ Interface = (*PIVAttestationOptions)(nil)
+
+// AddFlags implements Interface
+func (o *PIVAttestationOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Root, "piv-attestation-root", "",
+		"path to the PEM manufacturer CA certificates (e.g. the Yubico PIV root CA) the PIV attestation of the signing key must chain to, requiring the key to be generated on a hardware token")
+
+	cmd.Flags().StringSliceVar(&o.PINPolicies, "piv-pin-policy", nil,
+		"PIN policies accepted for the signing key, with --piv-attestation-root (never|once|always)")
+
+	cmd.Flags().StringSliceVar(&o.TouchPolicies, "piv-touch-policy", nil,
+		"touch policies accepted for the signing key, with --piv-attestation-root (never|always|cached)")
+}
@@ -31,6 +31,8 @@ type SignOptions struct {
 	Force             bool
 	Recursive         bool
 	Attachment        string
+	TimestampClaim    bool
+	AuditAnnotations  bool
 
 	Rekor       RekorOptions
 	Fulcio      FulcioOptions
@@ -77,4 +79,10 @@ func (o *SignOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.Attachment, "attachment", "",
 		"related image attachment to sign (sbom), default none")
+
+	cmd.Flags().BoolVar(&o.TimestampClaim, "timestamp-claim", false,
+		"include the signing time as the optional timestamp claim of the payload, for 'cosign verify --max-age'")
+
+	cmd.Flags().BoolVar(&o.AuditAnnotations, "audit-annotations", false,
+		"annotate the signature with the cosign version, and the CI run URL and commit SHA when running in a detected CI system")
 }
@@ -58,18 +58,19 @@ var _ Interface = (*SignatureDigestOptions)(nil)
 func (o *SignatureDigestOptions) AddFlags(cmd *cobra.Command) {
 	validSignatureDigestAlgorithms := strings.Join(supportedSignatureAlgorithmNames(), "|")
 
-	cmd.Flags().StringVar(&o.AlgorithmName, "signature-digest-algorithm", "sha256",
-		fmt.Sprintf("digest algorithm to use when processing a signature (%s)", validSignatureDigestAlgorithms))
+	cmd.Flags().StringVar(&o.AlgorithmName, "signature-digest-algorithm", "",
+		fmt.Sprintf("digest algorithm to use when processing a signature (%s), defaults to the one matching the key", validSignatureDigestAlgorithms))
 }
 
 // HashAlgorithm converts the algorithm's name - provided as a string - into a crypto.Hash algorithm.
+// Returns zero if no algorithm was given, leaving the choice to the key (see cosign.HashAlgorithmForKey).
 // Returns an error if the algorithm name doesn't match a supported algorithm, and defaults to SHA256
 // in the event that the given algorithm is invalid.
 func (o *SignatureDigestOptions) HashAlgorithm() (crypto.Hash, error) {
 	normalizedAlgo := strings.ToLower(strings.TrimSpace(o.AlgorithmName))
 
 	if normalizedAlgo == "" {
-		return crypto.SHA256, nil
+		return 0, nil
 	}
 
 	algo, exists := supportedSignatureAlgorithms[normalizedAlgo]
@@ -0,0 +1,181 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// TufStatusOptions is the wrapper for `tuf status` related options.
+type TufStatusOptions struct {
+	OutputJSON bool
+}
+
+var _ Interface = (*TufStatusOptions)(nil)
+
+// AddFlags implements Interface
+func (o *TufStatusOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.OutputJSON, "json", false,
+		"print JSON instead of text")
+}
+
+// TufRefreshOptions is the wrapper for `tuf refresh` related options.
+type TufRefreshOptions struct {
+	Force bool
+}
+
+var _ Interface = (*TufRefreshOptions)(nil)
+
+// AddFlags implements Interface
+func (o *TufRefreshOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.Force, "force", false,
+		"update the TUF metadata and targets even if none of the metadata expired")
+}
+
+// TufRepositoryOptions is the wrapper for the repository of the commands
+// managing a TUF repository.
+type TufRepositoryOptions struct {
+	Dir string
+}
+
+var _ Interface = (*TufRepositoryOptions)(nil)
+
+// AddFlags implements Interface
+func (o *TufRepositoryOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Dir, "repo", ".",
+		"directory of the TUF repository, whose repository/ subdirectory gets published")
+}
+
+// TufInitOptions is the wrapper for `tuf init` related options.
+type TufInitOptions struct {
+	Repository         TufRepositoryOptions
+	RootKeys           []string
+	RootThreshold      int
+	TargetsKeys        []string
+	TargetsThreshold   int
+	SnapshotKeys       []string
+	SnapshotThreshold  int
+	TimestampKeys      []string
+	TimestampThreshold int
+	Expires            time.Duration
+}
+
+var _ Interface = (*TufInitOptions)(nil)
+
+// AddFlags implements Interface
+func (o *TufInitOptions) AddFlags(cmd *cobra.Command) {
+	o.Repository.AddFlags(cmd)
+
+	cmd.Flags().StringSliceVar(&o.RootKeys, "root-key", nil,
+		"path to the public key file, KMS URI or PKCS11 URI of a key of the root role, can be repeated")
+
+	cmd.Flags().IntVar(&o.RootThreshold, "root-threshold", 1,
+		"number of keys of the root role that must sign its metadata")
+
+	cmd.Flags().StringSliceVar(&o.TargetsKeys, "targets-key", nil,
+		"path to the public key file, KMS URI or PKCS11 URI of a key of the targets role, can be repeated")
+
+	cmd.Flags().IntVar(&o.TargetsThreshold, "targets-threshold", 1,
+		"number of keys of the targets role that must sign its metadata")
+
+	cmd.Flags().StringSliceVar(&o.SnapshotKeys, "snapshot-key", nil,
+		"path to the public key file, KMS URI or PKCS11 URI of a key of the snapshot role, can be repeated")
+
+	cmd.Flags().IntVar(&o.SnapshotThreshold, "snapshot-threshold", 1,
+		"number of keys of the snapshot role that must sign its metadata")
+
+	cmd.Flags().StringSliceVar(&o.TimestampKeys, "timestamp-key", nil,
+		"path to the public key file, KMS URI or PKCS11 URI of a key of the timestamp role, can be repeated")
+
+	cmd.Flags().IntVar(&o.TimestampThreshold, "timestamp-threshold", 1,
+		"number of keys of the timestamp role that must sign its metadata")
+
+	cmd.Flags().DurationVar(&o.Expires, "expires", 365*24*time.Hour,
+		"validity of the root metadata")
+}
+
+// TufAddTargetOptions is the wrapper for `tuf add-target` related options.
+type TufAddTargetOptions struct {
+	Repository TufRepositoryOptions
+	Custom     string
+	Expires    time.Duration
+}
+
+var _ Interface = (*TufAddTargetOptions)(nil)
+
+// AddFlags implements Interface
+func (o *TufAddTargetOptions) AddFlags(cmd *cobra.Command) {
+	o.Repository.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Custom, "custom", "",
+		"custom JSON metadata of the target")
+
+	cmd.Flags().DurationVar(&o.Expires, "expires", 90*24*time.Hour,
+		"validity of the targets metadata")
+}
+
+// TufSignOptions is the wrapper for `tuf sign` related options.
+type TufSignOptions struct {
+	Repository TufRepositoryOptions
+	Key        string
+	Roles      []string
+}
+
+var _ Interface = (*TufSignOptions)(nil)
+
+// AddFlags implements Interface
+func (o *TufSignOptions) AddFlags(cmd *cobra.Command) {
+	o.Repository.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the private key file, KMS URI or PKCS11 URI to sign with")
+	_ = cmd.MarkFlagRequired("key")
+
+	cmd.Flags().StringSliceVar(&o.Roles, "role", nil,
+		"role to sign the metadata of (root, targets, snapshot or timestamp), defaults to all the roles of the key")
+}
+
+// TufPublishOptions is the wrapper for `tuf publish` related options.
+type TufPublishOptions struct {
+	Repository       TufRepositoryOptions
+	Keys             []string
+	SnapshotExpires  time.Duration
+	TimestampExpires time.Duration
+	To               string
+	Registry         RegistryOptions
+}
+
+var _ Interface = (*TufPublishOptions)(nil)
+
+// AddFlags implements Interface
+func (o *TufPublishOptions) AddFlags(cmd *cobra.Command) {
+	o.Repository.AddFlags(cmd)
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringSliceVar(&o.Keys, "key", nil,
+		"path to the private key file, KMS URI or PKCS11 URI of a snapshot or timestamp key to sign with, can be repeated")
+
+	cmd.Flags().DurationVar(&o.SnapshotExpires, "snapshot-expires", 7*24*time.Hour,
+		"validity of the snapshot metadata")
+
+	cmd.Flags().DurationVar(&o.TimestampExpires, "timestamp-expires", 24*time.Hour,
+		"validity of the timestamp metadata")
+
+	cmd.Flags().StringVar(&o.To, "to", "",
+		"also copy the published repository to a file:// directory, or push it to an oci:// reference")
+}
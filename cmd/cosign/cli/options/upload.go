@@ -53,3 +53,41 @@ func (o *UploadWASMOptions) AddFlags(cmd *cobra.Command) {
 		"path to the wasm file to upload")
 	_ = cmd.MarkFlagRequired("file")
 }
+
+// UploadHelmOptions is the top level wrapper for the `upload helm` command.
+type UploadHelmOptions struct {
+	File       string
+	Provenance string
+	Registry   RegistryOptions
+}
+
+var _ Interface = (*UploadHelmOptions)(nil)
+
+// AddFlags implements Interface
+func (o *UploadHelmOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.File, "file", "f", "",
+		"path to the packaged chart to upload, as built by 'helm package'")
+	_ = cmd.MarkFlagRequired("file")
+
+	cmd.Flags().StringVar(&o.Provenance, "provenance", "",
+		"path to the provenance file of the chart, as built by 'helm package --sign'")
+}
+
+// UploadBundleOptions is the top level wrapper for the `upload bundle` command.
+type UploadBundleOptions struct {
+	File     string
+	Registry RegistryOptions
+}
+
+var _ Interface = (*UploadBundleOptions)(nil)
+
+// AddFlags implements Interface
+func (o *UploadBundleOptions) AddFlags(cmd *cobra.Command) {
+	o.Registry.AddFlags(cmd)
+
+	cmd.Flags().StringVarP(&o.File, "file", "f", "",
+		"path to the OPA bundle to upload, as built by 'opa build'")
+	_ = cmd.MarkFlagRequired("file")
+}
@@ -16,7 +16,11 @@
 package options
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/pkg/cosign"
 )
 
 // VerifyOptions is the top level wrapper for the `verify` command.
@@ -27,13 +31,24 @@ type VerifyOptions struct {
 	Output       string
 	SignatureRef string
 	LocalImage   bool
+	MediaType    string
+	Recursive    bool
+	Platform     string
+
+	StrictIdentity  bool
+	IdentityAliases []string
+	MaxAge          time.Duration
+	LegacySHA256    bool
 
 	SecurityKey     SecurityKeyOptions
+	PIVAttestation  PIVAttestationOptions
+	RevocationList  RevocationListOptions
 	CertVerify      CertVerifyOptions
 	Rekor           RekorOptions
 	Registry        RegistryOptions
 	SignatureDigest SignatureDigestOptions
 	AnnotationOptions
+	AnnotationMatchOptions
 }
 
 var _ Interface = (*VerifyOptions)(nil)
@@ -41,11 +56,14 @@ var _ Interface = (*VerifyOptions)(nil)
 // AddFlags implements Interface
 func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 	o.SecurityKey.AddFlags(cmd)
+	o.PIVAttestation.AddFlags(cmd)
+	o.RevocationList.AddFlags(cmd)
 	o.Rekor.AddFlags(cmd)
 	o.CertVerify.AddFlags(cmd)
 	o.Registry.AddFlags(cmd)
 	o.SignatureDigest.AddFlags(cmd)
 	o.AnnotationOptions.AddFlags(cmd)
+	o.AnnotationMatchOptions.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Key, "key", "",
 		"path to the public key file, KMS URI or Kubernetes Secret")
@@ -64,6 +82,27 @@ func (o *VerifyOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().BoolVar(&o.LocalImage, "local-image", false,
 		"whether the specified image is a path to an image saved locally via 'cosign save'")
+
+	cmd.Flags().StringVar(&o.MediaType, "media-type", "",
+		"require the signed artifact to have a layer of this media type, or to be an artifact of this type ("+cosign.ArtifactTypeNames()+"); with --recursive, only the images of the index are checked")
+
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false,
+		"if a multi-arch image is specified, additionally verify each discrete image, failing if any is unsigned")
+
+	cmd.Flags().StringVar(&o.Platform, "platform", "",
+		"only verify the image of this platform (os/arch[/variant]) of a multi-arch image, and the multi-arch image itself with --recursive")
+
+	cmd.Flags().BoolVar(&o.StrictIdentity, "strict-identity", false,
+		"require the docker-reference claim of signatures to name the verified repository, or one of --identity-alias")
+
+	cmd.Flags().StringSliceVar(&o.IdentityAliases, "identity-alias", nil,
+		"other repository accepted as docker-reference with --strict-identity, e.g. the repository a mirror was copied from")
+
+	cmd.Flags().DurationVar(&o.MaxAge, "max-age", 0,
+		"require signatures to carry a timestamp claim no older than this, see 'cosign sign --timestamp-claim'")
+
+	cmd.Flags().BoolVar(&o.LegacySHA256, "allow-legacy-sha256", false,
+		"also accept the SHA-256 signatures of P-384 and P-521 keys, made by cosign versions signing with SHA-256 for all key types")
 }
 
 // VerifyAttestationOptions is the top level wrapper for the `verify attestation` command.
@@ -72,13 +111,15 @@ type VerifyAttestationOptions struct {
 	CheckClaims bool
 	Output      string
 
-	SecurityKey SecurityKeyOptions
-	Rekor       RekorOptions
-	CertVerify  CertVerifyOptions
-	Registry    RegistryOptions
-	Predicate   PredicateRemoteOptions
-	Policies    []string
-	LocalImage  bool
+	SecurityKey    SecurityKeyOptions
+	Rekor          RekorOptions
+	CertVerify     CertVerifyOptions
+	Registry       RegistryOptions
+	Predicate      PredicateRemoteOptions
+	RevocationList RevocationListOptions
+	Policies       []string
+	LocalImage     bool
+	LegacySHA256   bool
 }
 
 var _ Interface = (*VerifyAttestationOptions)(nil)
@@ -88,6 +129,7 @@ func (o *VerifyAttestationOptions) AddFlags(cmd *cobra.Command) {
 	o.SecurityKey.AddFlags(cmd)
 	o.Rekor.AddFlags(cmd)
 	o.CertVerify.AddFlags(cmd)
+	o.RevocationList.AddFlags(cmd)
 	o.Registry.AddFlags(cmd)
 	o.Predicate.AddFlags(cmd)
 
@@ -105,13 +147,17 @@ func (o *VerifyAttestationOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().BoolVar(&o.LocalImage, "local-image", false,
 		"whether the specified image is a path to an image saved locally via 'cosign save'")
+
+	cmd.Flags().BoolVar(&o.LegacySHA256, "allow-legacy-sha256", false,
+		"also accept the SHA-256 signatures of P-384 and P-521 keys, made by cosign versions signing with SHA-256 for all key types")
 }
 
 // VerifyBlobOptions is the top level wrapper for the `verify blob` command.
 type VerifyBlobOptions struct {
-	Key        string
-	Signature  string
-	BundlePath string
+	Key          string
+	Signature    string
+	BundlePath   string
+	LegacySHA256 bool
 
 	SecurityKey SecurityKeyOptions
 	CertVerify  CertVerifyOptions
@@ -136,6 +182,48 @@ func (o *VerifyBlobOptions) AddFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.BundlePath, "bundle", "",
 		"path to bundle FILE")
+
+	cmd.Flags().BoolVar(&o.LegacySHA256, "allow-legacy-sha256", false,
+		"also accept the SHA-256 signatures of P-384 and P-521 keys, made by cosign versions signing with SHA-256 for all key types")
+}
+
+// VerifyBlobAttestationOptions is the top level wrapper for the `verify-blob-attestation` command.
+type VerifyBlobAttestationOptions struct {
+	Key          string
+	Signature    string
+	CheckClaims  bool
+	Policies     []string
+	LegacySHA256 bool
+
+	SecurityKey SecurityKeyOptions
+	CertVerify  CertVerifyOptions
+	Rekor       RekorOptions
+	Predicate   PredicateRemoteOptions
+}
+
+var _ Interface = (*VerifyBlobAttestationOptions)(nil)
+
+// AddFlags implements Interface
+func (o *VerifyBlobAttestationOptions) AddFlags(cmd *cobra.Command) {
+	o.SecurityKey.AddFlags(cmd)
+	o.Rekor.AddFlags(cmd)
+	o.CertVerify.AddFlags(cmd)
+	o.Predicate.AddFlags(cmd)
+
+	cmd.Flags().StringVar(&o.Key, "key", "",
+		"path to the public key file, KMS URI or Kubernetes Secret")
+
+	cmd.Flags().StringVar(&o.Signature, "signature", "",
+		"attestation content or path or remote URL")
+
+	cmd.Flags().BoolVar(&o.CheckClaims, "check-claims", true,
+		"whether to check the claims found")
+
+	cmd.Flags().StringSliceVar(&o.Policies, "policy", nil,
+		"specify CUE or Rego files will be using for validation")
+
+	cmd.Flags().BoolVar(&o.LegacySHA256, "allow-legacy-sha256", false,
+		"also accept the SHA-256 signatures of P-384 and P-521 keys, made by cosign versions signing with SHA-256 for all key types")
 }
 
 // VerifyBlobOptions is the top level wrapper for the `verify blob` command.
@@ -35,6 +35,8 @@ func PKCS11Tool() *cobra.Command {
 	cmd.AddCommand(
 		pkcs11ToolListTokens(),
 		PKCS11ToolListKeysUrisOptions(),
+		pkcs11ToolGenerateKey(),
+		pkcs11ToolImportCert(),
 	)
 
 	// TODO: drop -f in favor of --no-input only
@@ -78,3 +80,44 @@ func PKCS11ToolListKeysUrisOptions() *cobra.Command {
 
 	return cmd
 }
+
+func pkcs11ToolGenerateKey() *cobra.Command {
+	o := &options.PKCS11ToolGenerateKeyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "generate-key",
+		Short: "generate-key generates a signing key pair in a PKCS11 token",
+		Long: `generate-key generates a signing key pair in a PKCS11 token, with the given label and/or ID,
+and prints its PKCS11 URI and its public key. The private key never leaves the token.`,
+		Example: `  cosign pkcs11-tool generate-key --module-path /usr/lib/softhsm/libsofthsm2.so --slot-id 0 --key-label signing-key [--key-algorithm rsa-3072]`,
+		Args:    cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pkcs11cli.GenerateKeyCmd(cmd.Context(), o.ModulePath, o.SlotID, o.Pin, o.KeyLabel, o.KeyID, o.KeyAlgorithm)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func pkcs11ToolImportCert() *cobra.Command {
+	o := &options.PKCS11ToolImportCertOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import-cert",
+		Short: "import-cert stores the certificate of a key in a PKCS11 token",
+		Long: `import-cert stores the certificate of the key with the given label or ID in a PKCS11 token,
+where signing with the key's URI picks it up. Only the first certificate of the file is imported,
+and it must be for the key.`,
+		Example: `  cosign pkcs11-tool import-cert --module-path /usr/lib/softhsm/libsofthsm2.so --slot-id 0 --key-label signing-key --cert signing-key.crt`,
+		Args:    cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pkcs11cli.ImportCertCmd(cmd.Context(), o.ModulePath, o.SlotID, o.Pin, o.KeyLabel, o.KeyID, o.Cert, pkcs11ToolForce)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
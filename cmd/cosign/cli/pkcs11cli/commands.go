@@ -24,10 +24,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/manifoldco/promptui"
 	"github.com/miekg/pkcs11"
 	"github.com/pkg/errors"
 	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"golang.org/x/term"
 )
 
@@ -240,3 +243,140 @@ func ListKeysUrisCmd(ctx context.Context, modulePath string, slotID uint, pin st
 
 	return nil
 }
+
+// keyURIConfig returns the configuration of the key labelled keyLabel, or
+// with the hex encoded keyID, in the token of slotID.
+func keyURIConfig(ctx context.Context, modulePath string, slotID uint, pin, keyLabel, keyID string) (*pkcs11key.Pkcs11UriConfig, error) {
+	if modulePath == "" || !filepath.IsAbs(modulePath) {
+		return nil, flag.ErrHelp
+	}
+	if keyLabel == "" && keyID == "" {
+		return nil, errors.New("one of --key-label and --key-id must be set")
+	}
+	var keyIDBytes []byte
+	if keyID != "" {
+		var err error
+		if keyIDBytes, err = hex.DecodeString(keyID); err != nil {
+			return nil, errors.Wrap(err, "decode key id")
+		}
+	}
+	var keyLabelBytes []byte
+	if keyLabel != "" {
+		keyLabelBytes = []byte(keyLabel)
+	}
+
+	// Keep the token label in the URIs, as list-keys-uris does.
+	tokens, err := GetTokens(ctx, modulePath)
+	if err != nil {
+		return nil, err
+	}
+	var tokenLabel string
+	bTokenFound := false
+	for _, token := range tokens {
+		if token.Slot == slotID {
+			tokenLabel = token.TokenInfo.Label
+			bTokenFound = true
+			break
+		}
+	}
+	if !bTokenFound {
+		return nil, fmt.Errorf("no token found in slot '%d'", slotID)
+	}
+
+	slotIDInt := int(slotID)
+	return pkcs11key.NewPkcs11UriConfigFromInput(modulePath, &slotIDInt, tokenLabel, keyLabelBytes, keyIDBytes, pin), nil
+}
+
+func GenerateKeyCmd(ctx context.Context, modulePath string, slotID uint, pin, keyLabel, keyID, algorithm string) error {
+	config, err := keyURIConfig(ctx, modulePath, slotID, pin, keyLabel, keyID)
+	if err != nil {
+		return err
+	}
+
+	key, err := pkcs11key.GenerateKeyWithURIConfig(config, algorithm, true)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+
+	pub, err := key.PublicKey()
+	if err != nil {
+		return err
+	}
+	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+	if err != nil {
+		return err
+	}
+
+	// Do not leak the PIN in the printed URI.
+	config.Pin = ""
+	uri, err := config.Construct()
+	if err != nil {
+		return errors.Wrap(err, "construct pkcs11 uri")
+	}
+
+	fmt.Fprintf(os.Stderr, "Generated %s key in slot '%d' of PKCS11 module '%s'\n", algorithm, slotID, modulePath)
+	fmt.Fprintf(os.Stdout, "URI: %s\n", uri)
+	fmt.Fprintf(os.Stdout, "ID: %s\n", hex.EncodeToString(config.KeyID))
+	fmt.Fprintf(os.Stdout, "%s", pemBytes)
+
+	return nil
+}
+
+func ImportCertCmd(ctx context.Context, modulePath string, slotID uint, pin, keyLabel, keyID, certPath string, force bool) error {
+	config, err := keyURIConfig(ctx, modulePath, slotID, pin, keyLabel, keyID)
+	if err != nil {
+		return err
+	}
+
+	pemBytes, err := os.ReadFile(filepath.Clean(certPath))
+	if err != nil {
+		return errors.Wrap(err, "read certificate")
+	}
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(pemBytes)
+	if err != nil {
+		return errors.Wrap(err, "parse certificate")
+	}
+	if len(certs) == 0 {
+		return errors.New("no certificate found")
+	}
+
+	key, err := pkcs11key.GetKeyWithURIConfig(config, true)
+	if err != nil {
+		return err
+	}
+	defer key.Close()
+	if _, err := key.SignerVerifier(); err != nil {
+		return errors.Wrap(err, "key not found")
+	}
+
+	existing, err := key.Certificate()
+	if err != nil {
+		return err
+	}
+	if existing != nil && !force && !Confirm("Importing certificate. This will replace the previous certificate of the key.") {
+		return nil
+	}
+
+	// Only the leaf certificate is stored with the key, any chain stays with the verifiers.
+	if err := key.ImportCertificate(certs[0], true); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Imported certificate for '%s' into slot '%d' of PKCS11 module '%s'\n", certs[0].Subject, slotID, modulePath)
+	return nil
+}
+
+var Confirm = func(p string) bool {
+	prompt := promptui.Prompt{
+		Label:     p,
+		IsConfirm: true,
+	}
+
+	result, err := prompt.Run()
+	if err != nil {
+		fmt.Println(err)
+		return false
+	}
+	return strings.ToLower(result) == "y"
+}
@@ -0,0 +1,106 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/generate"
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/revocation"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
+)
+
+func RevocationList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revocation-list",
+		Short: "Provides utilities for signing and publishing lists of revoked signatures.",
+	}
+
+	cmd.AddCommand(
+		revocationListSign(),
+		revocationListUpload(),
+	)
+
+	return cmd
+}
+
+func revocationListSign() *cobra.Command {
+	o := &options.RevocationListSignOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Signs a revocation list, outputting the signed list to stdout",
+		Long: `Signs a JSON revocation list, outputting the signed list to stdout, for verify commands to
+reject the signatures it revokes with --revocation-list. The list is issued now and expires
+after --expiry, unless it has "issued" and "expires" times.
+
+The list revokes the signatures matching all the fields of one of its revocations:
+
+  {
+    "revocations": [
+      {"keyId": "<hex SHA-256 of the DER public key>", "signedAfter": "2021-11-01T00:00:00Z", "reason": "key leaked"},
+      {"identity": "jdoe@example.com"},
+      {"certSerial": "<hex serial>"},
+      {"signatureDigest": "sha256:<hex>"}
+    ]
+  }`,
+		Example: `  cosign revocation-list sign --key <key path>|<kms uri> <LIST> > <SIGNED LIST>`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !options.OneOf(o.Key, o.SecurityKey.Use) {
+				return &options.KeyParseError{}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ko := sign.KeyOpts{
+				KeyRef:   o.Key,
+				PassFunc: generate.GetPass,
+				Sk:       o.SecurityKey.Use,
+				Slot:     o.SecurityKey.Slot,
+			}
+			return revocation.SignCmd(cmd.Context(), ko, args[0], o.Expiry, os.Stdout)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func revocationListUpload() *cobra.Command {
+	o := &options.RevocationListUploadOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "upload",
+		Short: "Uploads a signed revocation list to a registry",
+		Long: `Uploads a signed revocation list to a registry, for verify commands to fetch with
+--revocation-list oci://<IMAGE>. Signed revocation lists can also be added to a TUF
+repository with "cosign tuf add-target", and fetched with --revocation-list tuf://<TARGET>.`,
+		Example: `  cosign revocation-list upload <SIGNED LIST> <IMAGE>`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return revocation.UploadCmd(cmd.Context(), o.Registry, args[0], args[1])
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,92 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/sigstore/cosign/pkg/types"
+)
+
+// SignCmd signs the JSON encoded cosign.RevocationList at listPath, writing
+// the cosign.SignedRevocationList to out. The list is issued now and expires
+// after expiry unless it says otherwise.
+func SignCmd(ctx context.Context, ko sign.KeyOpts, listPath string, expiry time.Duration, out io.Writer) error {
+	b, err := os.ReadFile(filepath.Clean(listPath))
+	if err != nil {
+		return err
+	}
+	rl := &cosign.RevocationList{}
+	if err := json.Unmarshal(b, rl); err != nil {
+		return errors.Wrapf(err, "parsing revocation list %s", listPath)
+	}
+	if rl.Issued.IsZero() {
+		rl.Issued = time.Now().UTC().Truncate(time.Second)
+	}
+	if rl.Expires.IsZero() {
+		rl.Expires = rl.Issued.Add(expiry)
+	}
+
+	sv, err := sign.SignerFromKeyOpts(ctx, "", ko)
+	if err != nil {
+		return errors.Wrap(err, "getting signer")
+	}
+	defer sv.Close()
+
+	signed, err := cosign.SignRevocationList(ctx, sv, rl)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(signed))
+	return err
+}
+
+// UploadCmd uploads the cosign.SignedRevocationList at signedPath to
+// imageRef, for verifiers to fetch with --revocation-list oci://<imageRef>.
+func UploadCmd(ctx context.Context, regOpts options.RegistryOptions, signedPath, imageRef string) error {
+	b, err := os.ReadFile(filepath.Clean(signedPath))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &cosign.SignedRevocationList{}); err != nil {
+		return errors.Wrapf(err, "parsing signed revocation list %s", signedPath)
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Uploading revocation list from [%s] to [%s].\n", signedPath, ref.Name())
+	img, err := static.NewFile(b, static.WithLayerMediaType(types.RevocationListMediaType))
+	if err != nil {
+		return err
+	}
+	return remote.Write(ref, img, regOpts.GetRegistryClientOpts(ctx)...)
+}
@@ -31,13 +31,16 @@ func Save() *cobra.Command {
 	o := &options.SaveOptions{}
 
 	cmd := &cobra.Command{
-		Use:     "save",
-		Short:   "Save the container image and associated signatures to disk at the specified directory.",
-		Long:    "Save the container image and associated signatures to disk at the specified directory.",
-		Example: `  cosign save --dir <path to directory> <IMAGE>`,
-		Args:    cobra.ExactArgs(1),
+		Use:   "save",
+		Short: "Save the container images and associated signatures to disk at the specified directory.",
+		Long:  "Save the container images and associated signatures, attestations and attachments to disk at the specified directory.",
+		Example: `  cosign save --dir <path to directory> <IMAGE>
+
+  # save several images into the same directory
+  cosign save --dir <path to directory> <IMAGE> <IMAGE>`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return SaveCmd(cmd.Context(), *o, args[0])
+			return SaveCmd(cmd.Context(), *o, args...)
 		},
 	}
 
@@ -45,31 +48,19 @@ func Save() *cobra.Command {
 	return cmd
 }
 
-func SaveCmd(ctx context.Context, opts options.SaveOptions, imageRef string) error {
-	ref, err := name.ParseReference(imageRef)
-	if err != nil {
-		return errors.Wrapf(err, "parsing image name %s", imageRef)
-	}
-
-	se, err := ociremote.SignedEntity(ref)
-	if err != nil {
-		return errors.Wrap(err, "signed entity")
-	}
-
-	if _, ok := se.(oci.SignedImage); ok {
-		si, err := ociremote.SignedImage(ref)
+func SaveCmd(ctx context.Context, opts options.SaveOptions, imageRefs ...string) error {
+	entities := make(map[string]oci.SignedEntity, len(imageRefs))
+	for _, imageRef := range imageRefs {
+		ref, err := name.ParseReference(imageRef)
 		if err != nil {
-			return errors.Wrap(err, "getting signed image")
+			return errors.Wrapf(err, "parsing image name %s", imageRef)
 		}
-		return layout.WriteSignedImage(opts.Directory, si)
-	}
 
-	if _, ok := se.(oci.SignedImageIndex); ok {
-		sii, err := ociremote.SignedImageIndex(ref)
+		se, err := ociremote.SignedEntity(ref)
 		if err != nil {
-			return errors.Wrap(err, "getting signed image index")
+			return errors.Wrapf(err, "signed entity %s", imageRef)
 		}
-		return layout.WriteSignedImageIndex(opts.Directory, sii)
+		entities[ref.Name()] = se
 	}
-	return errors.New("unknown signed entity")
+	return layout.WriteSignedEntities(opts.Directory, entities)
 }
@@ -17,6 +17,7 @@ package cli
 
 import (
 	"flag"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -24,6 +25,7 @@ import (
 	"github.com/sigstore/cosign/cmd/cosign/cli/generate"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
+	"github.com/sigstore/cosign/pkg/cosign"
 )
 
 func Sign() *cobra.Command {
@@ -84,11 +86,28 @@ func Sign() *cobra.Command {
 				OIDCIssuer:               o.OIDC.Issuer,
 				OIDCClientID:             o.OIDC.ClientID,
 				OIDCClientSecret:         o.OIDC.ClientSecret,
+				OIDCProvider:             o.OIDC.ProviderName(),
 			}
 			annotationsMap, err := o.AnnotationsMap()
 			if err != nil {
 				return err
 			}
+			if o.TimestampClaim {
+				if annotationsMap.Annotations == nil {
+					annotationsMap.Annotations = map[string]interface{}{}
+				}
+				annotationsMap.Annotations[cosign.TimestampClaim] = time.Now().Unix()
+			}
+			if o.AuditAnnotations {
+				if annotationsMap.Annotations == nil {
+					annotationsMap.Annotations = map[string]interface{}{}
+				}
+				for k, v := range sign.AuditAnnotations(cmd.Context()) {
+					if _, ok := annotationsMap.Annotations[k]; !ok {
+						annotationsMap.Annotations[k] = v
+					}
+				}
+			}
 			if err := sign.SignCmd(cmd.Context(), ko, o.Registry, annotationsMap.Annotations, args, o.Cert, o.Upload, o.OutputSignature, o.OutputCertificate, o.PayloadPath, o.Force, o.Recursive, o.Attachment); err != nil {
 				if o.Attachment == "" {
 					return errors.Wrapf(err, "signing %v", args)
@@ -0,0 +1,52 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sign
+
+import (
+	"context"
+
+	"github.com/sigstore/cosign/pkg/providers"
+	"github.com/sigstore/cosign/pkg/version"
+)
+
+// Audit annotations record, in the optional section of signature payloads,
+// how the signature was made.
+const (
+	// ToolVersionAnnotation is the version of cosign that made the signature.
+	ToolVersionAnnotation = "dev.sigstore.cosign/tool-version"
+	// CIRunURLAnnotation is the URL of the CI run that made the signature.
+	CIRunURLAnnotation = "dev.sigstore.cosign/ci-run-url"
+	// CommitSHAAnnotation is the commit the CI run that made the signature built.
+	CommitSHAAnnotation = "dev.sigstore.cosign/commit-sha"
+)
+
+// AuditAnnotations returns the audit annotations of a signature made in this
+// execution context, describing the CI build it runs in, if any, as detected
+// by the registered providers.
+func AuditAnnotations(ctx context.Context) map[string]interface{} {
+	ann := map[string]interface{}{
+		ToolVersionAnnotation: version.GitVersion,
+	}
+	if b, ok := providers.DescribeBuild(ctx); ok {
+		if b.RunURL != "" {
+			ann[CIRunURLAnnotation] = b.RunURL
+		}
+		if b.CommitSHA != "" {
+			ann[CommitSHAAnnotation] = b.CommitSHA
+		}
+	}
+	return ann
+}
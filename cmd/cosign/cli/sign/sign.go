@@ -39,6 +39,7 @@ import (
 	icos "github.com/sigstore/cosign/internal/pkg/cosign"
 	ifulcio "github.com/sigstore/cosign/internal/pkg/cosign/fulcio"
 	ipayload "github.com/sigstore/cosign/internal/pkg/cosign/payload"
+	ipiv "github.com/sigstore/cosign/internal/pkg/cosign/piv"
 	irekor "github.com/sigstore/cosign/internal/pkg/cosign/rekor"
 	"github.com/sigstore/cosign/pkg/cosign"
 	"github.com/sigstore/cosign/pkg/cosign/pivkey"
@@ -205,6 +206,9 @@ func signDigest(ctx context.Context, digest name.Digest, payload []byte, ko KeyO
 
 	var s icos.Signer
 	s = ipayload.NewSigner(sv)
+	if sv.PIVAttestation != nil {
+		s = ipiv.NewSigner(s, sv.PIVAttestation)
+	}
 	if sv.Cert != nil {
 		s = ifulcio.NewSigner(s, sv.Cert, sv.Chain)
 	}
@@ -308,8 +312,23 @@ func signerFromSecurityKey(keySlot string) (*SignerVerifier, error) {
 		}
 	}
 
+	// Attach the attestation of the slot, proving the key was generated on
+	// the token, so that verifiers can require it.
+	var attestation []byte
+	slotCert, err := sk.Attest()
+	if err == nil {
+		var deviceCert *x509.Certificate
+		if deviceCert, err = sk.GetAttestationCertificate(); err == nil {
+			attestation, err = cosign.PIVAttestationChain(slotCert, deviceCert)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: no attestation retrieved from the PIV token: %v\n", err)
+	}
+
 	return &SignerVerifier{
 		Cert:           pemBytes,
+		PIVAttestation: attestation,
 		SignerVerifier: sv,
 		close:          sk.Close,
 	}, nil
@@ -397,8 +416,8 @@ func keylessSigner(ctx context.Context, ko KeyOpts) (*SignerVerifier, error) {
 		return nil, errors.Wrap(err, "creating Fulcio client")
 	}
 	tok := ko.IDToken
-	if providers.Enabled(ctx) {
-		tok, err = providers.Provide(ctx, "sigstore")
+	if ko.OIDCProvider != "" || providers.Enabled(ctx) {
+		tok, err = providers.ProvideFrom(ctx, ko.OIDCProvider, "sigstore")
 		if err != nil {
 			return nil, errors.Wrap(err, "fetching ambient OIDC credentials")
 		}
@@ -440,6 +459,8 @@ func SignerFromKeyOpts(ctx context.Context, certPath string, ko KeyOpts) (*Signe
 type SignerVerifier struct {
 	Cert  []byte
 	Chain []byte
+	// PIVAttestation is the PEM attestation chain of the PIV slot holding the key, if any.
+	PIVAttestation []byte
 	signature.SignerVerifier
 	close func()
 }
@@ -18,7 +18,10 @@ package sign
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -32,6 +35,7 @@ import (
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/cmd/cosign/cli/rekor"
+	"github.com/sigstore/cosign/pkg/blob"
 	"github.com/sigstore/cosign/pkg/cosign"
 	signatureoptions "github.com/sigstore/sigstore/pkg/signature/options"
 )
@@ -49,26 +53,36 @@ type KeyOpts struct {
 	OIDCClientSecret string
 	BundlePath       string
 
+	// OIDCProvider forces the provider of ambient OIDC credentials, which
+	// is otherwise the first enabled one.
+	OIDCProvider string
+
 	// Modeled after InsecureSkipVerify in tls.Config, this disables
 	// verifying the SCT.
 	InsecureSkipFulcioVerify bool
+
+	// LegacySHA256 also accepts, when verifying, the SHA-256 signatures of
+	// P-384 and P-521 keys, see cosign.LegacySHA256Verifier.
+	LegacySHA256 bool
 }
 
 // nolint
 func SignBlobCmd(ctx context.Context, ko KeyOpts, regOpts options.RegistryOptions, payloadPath string, b64 bool, outputSignature string, outputCertificate string, timeout time.Duration) ([]byte, error) {
-	var payload []byte
+	var payload io.ReadCloser
 	var err error
 	var rekorBytes []byte
 
 	if payloadPath == "-" {
-		payload, err = io.ReadAll(os.Stdin)
+		payload = io.NopCloser(os.Stdin)
 	} else {
 		fmt.Fprintln(os.Stderr, "Using payload from:", payloadPath)
-		payload, err = os.ReadFile(filepath.Clean(payloadPath))
-	}
-	if err != nil {
-		return nil, err
+		payload, err = os.Open(filepath.Clean(payloadPath))
+		if err != nil {
+			return nil, err
+		}
 	}
+	defer payload.Close()
+
 	if timeout != 0 {
 		var cancelFn context.CancelFunc
 		ctx, cancelFn = context.WithTimeout(ctx, timeout)
@@ -81,7 +95,7 @@ func SignBlobCmd(ctx context.Context, ko KeyOpts, regOpts options.RegistryOption
 	}
 	defer sv.Close()
 
-	sig, err := sv.SignMessage(bytes.NewReader(payload), signatureoptions.WithContext(ctx))
+	sig, digest, hashAlgorithm, err := signBlob(ctx, sv, payload)
 	if err != nil {
 		return nil, errors.Wrap(err, "signing blob")
 	}
@@ -97,7 +111,7 @@ func SignBlobCmd(ctx context.Context, ko KeyOpts, regOpts options.RegistryOption
 		if err != nil {
 			return nil, err
 		}
-		entry, err := cosign.TLogUpload(ctx, rekorClient, sig, payload, rekorBytes)
+		entry, err := cosign.TLogUploadDigest(ctx, rekorClient, sig, digest, hashAlgorithm, rekorBytes)
 		if err != nil {
 			return nil, err
 		}
@@ -114,6 +128,14 @@ func SignBlobCmd(ctx context.Context, ko KeyOpts, regOpts options.RegistryOption
 	if ko.BundlePath != "" {
 		signedPayload.Base64Signature = base64.StdEncoding.EncodeToString(sig)
 		signedPayload.Cert = base64.StdEncoding.EncodeToString(rekorBytes)
+		if len(sv.Chain) > 0 {
+			signedPayload.Chain = base64.StdEncoding.EncodeToString(sv.Chain)
+		}
+		digestAlgorithm, err := cosign.HashedRekordAlgorithm(hashAlgorithm)
+		if err != nil {
+			digestAlgorithm = "sha256"
+		}
+		signedPayload.PayloadDigest = digestAlgorithm + ":" + hex.EncodeToString(digest)
 
 		contents, err := json.Marshal(signedPayload)
 		if err != nil {
@@ -155,3 +177,33 @@ func SignBlobCmd(ctx context.Context, ko KeyOpts, regOpts options.RegistryOption
 
 	return sig, nil
 }
+
+// signBlob signs the payload with sv and returns the signature along with the payload's
+// digest and the hash algorithm it was computed with. Keys that sign over a digest get
+// the payload streamed through the hash matching the key, so blobs of any size can be
+// signed without holding them in memory. ED25519 keys sign the message itself, so the
+// payload is read in full; the hash algorithm is then zero and the digest is sha256.
+func signBlob(ctx context.Context, sv *SignerVerifier, payload io.Reader) ([]byte, []byte, crypto.Hash, error) {
+	pub, err := sv.PublicKey(signatureoptions.WithContext(ctx))
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	hashAlgorithm := cosign.HashAlgorithmForKey(pub)
+	if hashAlgorithm == 0 {
+		message, err := io.ReadAll(payload)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		sig, err := sv.SignMessage(bytes.NewReader(message), signatureoptions.WithContext(ctx))
+		digest := sha256.Sum256(message)
+		return sig, digest[:], 0, err
+	}
+
+	digest, err := blob.Digest(payload, hashAlgorithm)
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "hashing blob")
+	}
+	sig, err := sv.SignMessage(nil, signatureoptions.WithContext(ctx),
+		signatureoptions.WithDigest(digest), signatureoptions.WithCryptoSignerOpts(hashAlgorithm))
+	return sig, digest, hashAlgorithm, err
+}
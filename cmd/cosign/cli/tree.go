@@ -0,0 +1,48 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/tree"
+)
+
+func Tree() *cobra.Command {
+	o := &options.TreeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Display the signatures, attestations and attachments of an image and of each of its child manifests.",
+		Example: `  cosign tree <IMAGE>
+
+  # output the tree as JSON
+  cosign tree --json <IMAGE>`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return flag.ErrHelp
+			}
+			return tree.TreeCmd(cmd.Context(), o.Registry, args[0], o.OutputJSON, os.Stdout)
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
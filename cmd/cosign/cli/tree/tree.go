@@ -0,0 +1,443 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tree
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/pkg/errors"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/pkg/oci"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/oci/walk"
+	sigs "github.com/sigstore/cosign/pkg/signature"
+)
+
+// attachmentNames are the attachments looked up for each manifest.
+var attachmentNames = []string{cosign.SBOM}
+
+// Signer identifies who made a signature or an attestation.
+type Signer struct {
+	// Identity is the email or URI of the signing certificate, if any.
+	Identity string `json:"identity,omitempty"`
+	// Issuer is the OIDC issuer of the signing certificate, if any.
+	Issuer string `json:"issuer,omitempty"`
+	// KeyID is the hex SHA-256 of the DER public key of the signer, if known.
+	KeyID string `json:"keyId,omitempty"`
+	// TlogIndex is the index of the entry in the transparency log, if bundled.
+	TlogIndex *int64 `json:"tlogIndex,omitempty"`
+}
+
+// Signature is a signature of a manifest.
+type Signature struct {
+	Digest string `json:"digest"`
+	Signer
+}
+
+// Attestation is an attestation of a manifest.
+type Attestation struct {
+	Digest        string `json:"digest"`
+	PredicateType string `json:"predicateType,omitempty"`
+	Signer
+}
+
+// Attachment is a file attached to a manifest.
+type Attachment struct {
+	Name      string `json:"name"`
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Node is a manifest with everything attached to it, and its child manifests.
+type Node struct {
+	Reference    string        `json:"reference"`
+	MediaType    string        `json:"mediaType"`
+	Platform     string        `json:"platform,omitempty"`
+	Signatures   []Signature   `json:"signatures"`
+	Attestations []Attestation `json:"attestations"`
+	Attachments  []Attachment  `json:"attachments"`
+	Children     []*Node       `json:"children,omitempty"`
+}
+
+// TreeCmd prints the signatures, attestations and attachments of imageRef and
+// of each of its child manifests, as a tree or as JSON.
+func TreeCmd(ctx context.Context, regOpts options.RegistryOptions, imageRef string, outputJSON bool, out io.Writer) error {
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return err
+	}
+	ociremoteOpts, err := regOpts.ClientOpts(ctx)
+	if err != nil {
+		return errors.Wrap(err, "constructing client options")
+	}
+	se, err := ociremote.SignedEntity(ref, ociremoteOpts...)
+	if err != nil {
+		return err
+	}
+
+	root, err := Tree(ctx, ref.Context(), se)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(root)
+	}
+	printNode(out, root, "", "")
+	return nil
+}
+
+// Tree walks se, in repo, and returns its node.
+func Tree(ctx context.Context, repo name.Repository, se oci.SignedEntity) (*Node, error) {
+	var root *Node
+	nodes := map[string]*Node{}
+	// The platforms and children of each index, which walk visits before its children.
+	platforms := map[string]string{}
+	children := map[string][]string{}
+
+	if err := walk.SignedEntity(ctx, se, func(ctx context.Context, se oci.SignedEntity) error {
+		n, h, err := newNode(repo, se)
+		if err != nil {
+			return err
+		}
+		n.Platform = platforms[h.String()]
+		nodes[h.String()] = n
+		if root == nil {
+			root = n
+		}
+
+		if sii, ok := se.(oci.SignedImageIndex); ok {
+			im, err := sii.IndexManifest()
+			if err != nil {
+				return err
+			}
+			for _, desc := range im.Manifests {
+				if desc.Platform != nil {
+					platforms[desc.Digest.String()] = options.PlatformString(desc.Platform)
+				}
+				children[h.String()] = append(children[h.String()], desc.Digest.String())
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for parent, digests := range children {
+		for _, d := range digests {
+			// Manifests walk doesn't descend into, such as attestations stored
+			// as index children, are not listed.
+			if n, ok := nodes[d]; ok {
+				nodes[parent].Children = append(nodes[parent].Children, n)
+			}
+		}
+	}
+	return root, nil
+}
+
+func newNode(repo name.Repository, se oci.SignedEntity) (*Node, v1.Hash, error) {
+	var h v1.Hash
+	var mt string
+	switch e := se.(type) {
+	case oci.SignedImageIndex:
+		d, err := e.Digest()
+		if err != nil {
+			return nil, h, err
+		}
+		m, err := e.MediaType()
+		if err != nil {
+			return nil, h, err
+		}
+		h, mt = d, string(m)
+	case oci.SignedImage:
+		d, err := e.Digest()
+		if err != nil {
+			return nil, h, err
+		}
+		m, err := e.MediaType()
+		if err != nil {
+			return nil, h, err
+		}
+		h, mt = d, string(m)
+	default:
+		return nil, h, fmt.Errorf("unexpected signed entity %T", se)
+	}
+
+	n := &Node{
+		Reference:    repo.Digest(h.String()).String(),
+		MediaType:    mt,
+		Signatures:   []Signature{},
+		Attestations: []Attestation{},
+		Attachments:  []Attachment{},
+	}
+
+	sigs, err := se.Signatures()
+	if err != nil {
+		return nil, h, errors.Wrap(err, "fetching signatures")
+	}
+	sl, err := sigs.Get()
+	if err != nil {
+		return nil, h, errors.Wrap(err, "fetching signatures")
+	}
+	for _, sig := range sl {
+		d, err := sig.Digest()
+		if err != nil {
+			return nil, h, err
+		}
+		s, err := signerOf(sig)
+		if err != nil {
+			return nil, h, err
+		}
+		n.Signatures = append(n.Signatures, Signature{Digest: d.String(), Signer: s})
+	}
+
+	atts, err := se.Attestations()
+	if err != nil {
+		return nil, h, errors.Wrap(err, "fetching attestations")
+	}
+	al, err := atts.Get()
+	if err != nil {
+		return nil, h, errors.Wrap(err, "fetching attestations")
+	}
+	for _, att := range al {
+		d, err := att.Digest()
+		if err != nil {
+			return nil, h, err
+		}
+		s, err := signerOf(att)
+		if err != nil {
+			return nil, h, err
+		}
+		payload, err := att.Payload()
+		if err != nil {
+			return nil, h, err
+		}
+		a := Attestation{Digest: d.String(), PredicateType: predicateType(payload), Signer: s}
+		if a.KeyID == "" {
+			a.KeyID = envelopeKeyID(payload)
+		}
+		n.Attestations = append(n.Attestations, a)
+	}
+
+	for _, attName := range attachmentNames {
+		f, err := se.Attachment(attName)
+		if errors.Is(err, oci.ErrAttachmentNotFound) {
+			continue
+		} else if err != nil {
+			return nil, h, errors.Wrapf(err, "fetching %s", attName)
+		}
+		a, err := newAttachment(attName, f)
+		if err != nil {
+			return nil, h, err
+		}
+		n.Attachments = append(n.Attachments, a)
+	}
+
+	return n, h, nil
+}
+
+func newAttachment(attName string, f oci.File) (Attachment, error) {
+	mt, err := f.FileMediaType()
+	if err != nil {
+		return Attachment{}, err
+	}
+	payload, err := f.Payload()
+	if err != nil {
+		return Attachment{}, err
+	}
+	return Attachment{
+		Name:      attName,
+		MediaType: string(mt),
+		Digest:    fmt.Sprintf("sha256:%x", sha256.Sum256(payload)),
+		Size:      int64(len(payload)),
+	}, nil
+}
+
+// signerOf returns what sig tells about who made it: its certificate, or the
+// public key recorded in its transparency log bundle.
+func signerOf(sig oci.Signature) (Signer, error) {
+	var s Signer
+	b, err := sig.Bundle()
+	if err != nil {
+		return s, err
+	}
+	var bundleKey []byte
+	if b != nil {
+		index := b.Payload.LogIndex
+		s.TlogIndex = &index
+		bundleKey = bundlePublicKey(b)
+	}
+
+	cert, err := sig.Cert()
+	if err != nil {
+		return s, err
+	}
+	if cert == nil && len(bundleKey) > 0 {
+		if certs, err := cryptoutils.UnmarshalCertificatesFromPEM(bundleKey); err == nil && len(certs) > 0 {
+			cert = certs[0]
+		}
+	}
+
+	if cert != nil {
+		s.Identity, s.Issuer = sigs.CertSubject(cert), sigs.CertIssuerExtension(cert)
+		s.KeyID = cosign.KeyID(cert.PublicKey)
+	} else if len(bundleKey) > 0 {
+		if pub, err := cryptoutils.UnmarshalPEMToPublicKey(bundleKey); err == nil {
+			s.KeyID = cosign.KeyID(pub)
+		}
+	}
+	return s, nil
+}
+
+// bundlePublicKey returns the PEM public key or certificate recorded in the
+// rekord, hashedrekord or intoto entry of b.
+func bundlePublicKey(b *bundle.RekorBundle) []byte {
+	body, ok := b.Payload.Body.(string)
+	if !ok {
+		return nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil
+	}
+	var entry struct {
+		Spec struct {
+			Signature struct {
+				PublicKey struct {
+					Content string `json:"content"`
+				} `json:"publicKey"`
+			} `json:"signature"`
+			PublicKey string `json:"publicKey"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil
+	}
+	content := entry.Spec.Signature.PublicKey.Content
+	if content == "" {
+		content = entry.Spec.PublicKey
+	}
+	pemBytes, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil
+	}
+	return pemBytes
+}
+
+// predicateType returns the predicate type of the in-toto statement of a DSSE envelope.
+func predicateType(envelope []byte) string {
+	var env dsse.Envelope
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		return ""
+	}
+	statement, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return ""
+	}
+	var header in_toto.StatementHeader
+	if err := json.Unmarshal(statement, &header); err != nil {
+		return ""
+	}
+	return header.PredicateType
+}
+
+// envelopeKeyID returns the key ID of the first signature of a DSSE envelope.
+func envelopeKeyID(envelope []byte) string {
+	var env dsse.Envelope
+	if err := json.Unmarshal(envelope, &env); err != nil || len(env.Signatures) == 0 {
+		return ""
+	}
+	return env.Signatures[0].KeyID
+}
+
+func printNode(out io.Writer, n *Node, prefix, childPrefix string) {
+	title := fmt.Sprintf("%s (%s)", n.Reference, n.MediaType)
+	if n.Platform != "" {
+		title = fmt.Sprintf("%s (%s, %s)", n.Reference, n.Platform, n.MediaType)
+	}
+	fmt.Fprintf(out, "%s%s\n", prefix, title)
+
+	var lines []string
+	for _, s := range n.Signatures {
+		lines = append(lines, "signature "+s.Digest+signerString(s.Signer))
+	}
+	for _, a := range n.Attestations {
+		pt := a.PredicateType
+		if pt == "" {
+			pt = "unknown predicate"
+		}
+		lines = append(lines, fmt.Sprintf("attestation %s %s%s", a.Digest, pt, signerString(a.Signer)))
+	}
+	for _, a := range n.Attachments {
+		lines = append(lines, fmt.Sprintf("%s %s %s (%d bytes)", a.Name, a.Digest, a.MediaType, a.Size))
+	}
+
+	count := len(lines) + len(n.Children)
+	i := 0
+	for _, l := range lines {
+		i++
+		if i == count {
+			fmt.Fprintf(out, "%s└── %s\n", childPrefix, l)
+		} else {
+			fmt.Fprintf(out, "%s├── %s\n", childPrefix, l)
+		}
+	}
+	for _, c := range n.Children {
+		i++
+		if i == count {
+			printNode(out, c, childPrefix+"└── ", childPrefix+"    ")
+		} else {
+			printNode(out, c, childPrefix+"├── ", childPrefix+"│   ")
+		}
+	}
+}
+
+func signerString(s Signer) string {
+	var parts []string
+	if s.Identity != "" {
+		parts = append(parts, "identity="+s.Identity)
+	}
+	if s.Issuer != "" {
+		parts = append(parts, "issuer="+s.Issuer)
+	}
+	if s.KeyID != "" {
+		parts = append(parts, "key="+s.KeyID)
+	}
+	if s.TlogIndex != nil {
+		parts = append(parts, fmt.Sprintf("tlog=%d", *s.TlogIndex))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, " ")
+}
@@ -0,0 +1,210 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tree
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	ggcrmutate "github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/sigstore/cosign/test"
+)
+
+func TestTree(t *testing.T) {
+	ctx := context.Background()
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var adds []ggcrmutate.IndexAddendum
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := random.Image(300, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adds = append(adds, ggcrmutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: arch}},
+		})
+	}
+	ii := ggcrmutate.AppendManifests(empty.Index, adds...)
+	indexDigest, err := ii.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(repo.Digest(indexDigest.String()), ii); err != nil {
+		t.Fatal(err)
+	}
+	im, err := ii.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := im.Manifests[0].Digest
+
+	rootCert, rootKey, _ := test.GenerateRootCa()
+	leafCert, _, _ := test.GenerateLeafCert("subject@mail.com", "https://issuer.example.com", rootCert, rootKey)
+	certPEM, err := cryptoutils.MarshalCertificateToPEM(leafCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := static.NewSignature([]byte("payload"), "c2ln",
+		static.WithCertChain(certPEM, nil),
+		static.WithBundle(&bundle.RekorBundle{Payload: bundle.RekorPayload{LogIndex: 42}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	se, err := ociremote.SignedImageIndex(repo.Digest(indexDigest.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := mutate.AttachSignatureToEntity(se, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ociremote.WriteSignatures(repo, signed); err != nil {
+		t.Fatal(err)
+	}
+
+	statement := base64.StdEncoding.EncodeToString([]byte(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2"}`))
+	att, err := static.NewAttestation([]byte(`{"payloadType":"application/vnd.in-toto+json","payload":"` + statement + `","signatures":[{"keyid":"mykey","sig":"c2ln"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sbom, err := static.NewFile([]byte("sbom"), static.WithLayerMediaType("text/spdx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	si, err := ociremote.SignedImage(repo.Digest(child.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attested, err := mutate.AttachAttestationToEntity(si, att)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ociremote.WriteAttestations(repo, attested); err != nil {
+		t.Fatal(err)
+	}
+	attached, err := mutate.AttachFileToEntity(si, "sbom", sbom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ociremote.WriteAttachment(repo, attached, "sbom"); err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := Tree(ctx, repo, se)
+	if err != nil {
+		t.Fatalf("Tree() = %v", err)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("got %d children, wanted 2", len(root.Children))
+	}
+	if len(root.Signatures) != 1 {
+		t.Fatalf("got %d signatures, wanted 1", len(root.Signatures))
+	}
+	got := root.Signatures[0]
+	if got.Identity != "subject@mail.com" || got.Issuer != "https://issuer.example.com" {
+		t.Errorf("signer = %s %s, wanted subject@mail.com https://issuer.example.com", got.Identity, got.Issuer)
+	}
+	if got.KeyID != cosign.KeyID(leafCert.PublicKey) {
+		t.Errorf("key ID = %s, wanted %s", got.KeyID, cosign.KeyID(leafCert.PublicKey))
+	}
+	if got.TlogIndex == nil || *got.TlogIndex != 42 {
+		t.Errorf("tlog index = %v, wanted 42", got.TlogIndex)
+	}
+
+	var c *Node
+	for _, n := range root.Children {
+		if n.Reference == repo.Digest(child.String()).String() {
+			c = n
+		}
+	}
+	if c == nil {
+		t.Fatalf("child %s not found", child)
+	}
+	if c.Platform != "linux/amd64" {
+		t.Errorf("platform = %s, wanted linux/amd64", c.Platform)
+	}
+	if len(c.Signatures) != 0 {
+		t.Errorf("got %d child signatures, wanted 0", len(c.Signatures))
+	}
+	if len(c.Attestations) != 1 || c.Attestations[0].PredicateType != "https://slsa.dev/provenance/v0.2" || c.Attestations[0].KeyID != "mykey" {
+		t.Errorf("attestations = %+v, wanted one SLSA provenance by mykey", c.Attestations)
+	}
+	if len(c.Attachments) != 1 || c.Attachments[0].Name != "sbom" || c.Attachments[0].MediaType != "text/spdx" || c.Attachments[0].Size != 4 {
+		t.Errorf("attachments = %+v, wanted one 4 byte text/spdx sbom", c.Attachments)
+	}
+
+	var out bytes.Buffer
+	printNode(&out, root, "", "")
+	for _, want := range []string{
+		"├── signature ",
+		"identity=subject@mail.com issuer=https://issuer.example.com",
+		"tlog=42",
+		"attestation ",
+		"https://slsa.dev/provenance/v0.2 key=mykey",
+		"sbom sha256:",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out.String(), want)
+		}
+	}
+	if !strings.Contains(out.String(), "└── "+repo.Digest(im.Manifests[1].Digest.String()).String()) {
+		t.Errorf("output %q does not end with the last child", out.String())
+	}
+
+	var decoded Node
+	out.Reset()
+	if err := TreeCmd(ctx, options.RegistryOptions{}, repo.Digest(indexDigest.String()).String(), true, &out); err != nil {
+		t.Fatalf("TreeCmd() = %v", err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Children) != 2 || len(decoded.Signatures) != 1 {
+		t.Errorf("decoded tree = %+v, wanted 2 children and 1 signature", decoded)
+	}
+}
@@ -0,0 +1,188 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/tufcli"
+)
+
+func TUF() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tuf",
+		Short: "Provides utilities for inspecting and updating the TUF metadata used for verification.",
+	}
+
+	cmd.AddCommand(
+		tufStatus(),
+		tufRefresh(),
+		tufInit(),
+		tufAddTarget(),
+		tufSign(),
+		tufPublish(),
+	)
+
+	return cmd
+}
+
+func tufStatus() *cobra.Command {
+	o := &options.TufStatusOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Prints the versions and expiries of the TUF metadata, and the trusted targets",
+		Long: `Prints the versions and expiries of the TUF root, targets, snapshot and timestamp metadata,
+the trusted targets, and where they come from, without updating them.`,
+		Example: `  cosign tuf status [--json]`,
+		Args:    cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tufcli.StatusCmd(cmd.Context(), os.Stdout, o.OutputJSON)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func tufRefresh() *cobra.Command {
+	o := &options.TufRefreshOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Updates the TUF metadata and targets from the mirror",
+		Long: `Updates the TUF metadata and targets from the mirror given to "cosign initialize" if any of
+the metadata expired, or always with --force. This also works in offline mode.`,
+		Example: `  cosign tuf refresh [--force]`,
+		Args:    cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tufcli.RefreshCmd(cmd.Context(), os.Stdout, o.Force)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func tufInit() *cobra.Command {
+	o := &options.TufInitOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Stages the root metadata of a new TUF repository, to verify with a private trust root",
+		Long: `Stages the root metadata of a new TUF repository in the --repo directory, listing the keys of
+the root, targets, snapshot and timestamp roles. The keys can be public key files, KMS URIs or
+PKCS11 URIs, no private key is stored in the repository.
+
+The repository is then populated with "cosign tuf add-target", signed with "cosign tuf sign",
+and published with "cosign tuf publish".`,
+		Example: `  cosign tuf init --repo <dir> --root-key <key> [--root-key <key>...] [--root-threshold <n>] --targets-key <key> --snapshot-key <key> --timestamp-key <key>
+
+  # initialize a repository whose root is signed by two out of three KMS keys
+  cosign tuf init --repo tuf --root-key gcpkms://.../root1 --root-key gcpkms://.../root2 --root-key gcpkms://.../root3 --root-threshold 2 \
+    --targets-key targets.pub --snapshot-key online.pub --timestamp-key online.pub`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tufcli.InitCmd(cmd.Context(), o.Repository.Dir, map[string]tufcli.RoleKeyRefs{
+				"root":      {KeyRefs: o.RootKeys, Threshold: o.RootThreshold},
+				"targets":   {KeyRefs: o.TargetsKeys, Threshold: o.TargetsThreshold},
+				"snapshot":  {KeyRefs: o.SnapshotKeys, Threshold: o.SnapshotThreshold},
+				"timestamp": {KeyRefs: o.TimestampKeys, Threshold: o.TimestampThreshold},
+			}, o.Expires)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func tufAddTarget() *cobra.Command {
+	o := &options.TufAddTargetOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "add-target",
+		Short: "Stages a target, such as a Fulcio root or a Rekor or CT log public key, in a TUF repository",
+		Long: `Stages the file at PATH as the target NAME of the TUF repository in the --repo directory.
+The targets metadata then needs to be signed with "cosign tuf sign".`,
+		Example: `  cosign tuf add-target --repo <dir> [--custom <json>] NAME PATH
+
+  # add the public key of a private Rekor instance
+  cosign tuf add-target --repo tuf --custom '{"sigstore":{"status":"Active"}}' rekor.pub ./rekor.pub`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tufcli.AddTargetCmd(cmd.Context(), o.Repository.Dir, args[0], args[1], o.Custom, o.Expires)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func tufSign() *cobra.Command {
+	o := &options.TufSignOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Signs the staged metadata of a TUF repository",
+		Long: `Signs the staged metadata of the TUF repository in the --repo directory with the given key,
+for the roles listed with --role, or all the roles the key belongs to. Each key holder runs this
+in turn until the threshold of each role is met.`,
+		Example: `  cosign tuf sign --repo <dir> --key <key> [--role <role>...]
+
+  # sign the root with a key held in a hardware token
+  cosign tuf sign --repo tuf --key pkcs11:token=root;object=root?module-path=/usr/lib/libykcs11.so --role root`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tufcli.SignCmd(cmd.Context(), o.Repository.Dir, o.Key, o.Roles)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func tufPublish() *cobra.Command {
+	o := &options.TufPublishOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Signs the snapshot and timestamp metadata of a TUF repository and publishes it",
+		Long: `Stages the snapshot and timestamp metadata of the TUF repository in the --repo directory,
+signed with the given keys, and commits the staged metadata and targets to its repository/
+subdirectory. With --to, the repository is also copied to a file:// directory or pushed to an
+oci:// reference, from where "cosign initialize --mirror" can use it.`,
+		Example: `  cosign tuf publish --repo <dir> --key <key> [--key <key>...] [--to file://<dir>|oci://<ref>]
+
+  # publish the repository to a registry
+  cosign tuf publish --repo tuf --key online.key --to oci://registry.example.com/sigstore/tuf:latest`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return tufcli.PublishCmd(cmd.Context(), o.Repository.Dir, o.Keys, o.SnapshotExpires, o.TimestampExpires, o.To, o.Registry)
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
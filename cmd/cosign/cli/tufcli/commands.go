@@ -0,0 +1,86 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tufcli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/pkg/cosign/tuf"
+)
+
+// StatusCmd prints the status of the TUF metadata and targets verifications use.
+func StatusCmd(ctx context.Context, w io.Writer, outputJSON bool) error {
+	status, err := tuf.GetStatus(ctx)
+	if err != nil {
+		return errors.Wrap(err, "getting TUF status")
+	}
+	if outputJSON {
+		b, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	source := "embedded"
+	if status.Cached {
+		source = "cached"
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Mirror:\t%s\n", status.Mirror)
+	fmt.Fprintf(tw, "Offline:\t%t\n", status.Offline)
+	fmt.Fprintf(tw, "Metadata:\t%s\n", source)
+	fmt.Fprintf(tw, "Targets:\t%s\n", strings.Join(status.Targets, ", "))
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "ROLE\tVERSION\tEXPIRES")
+	now := time.Now()
+	for _, name := range []string{"root.json", "targets.json", "snapshot.json", "timestamp.json"} {
+		md, ok := status.Metadata[name]
+		if !ok {
+			fmt.Fprintf(tw, "%s\t-\tmissing\n", strings.TrimSuffix(name, ".json"))
+			continue
+		}
+		expires := md.Expires.UTC().Format(time.RFC3339)
+		if !md.Expires.After(now) {
+			expires += " (expired)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", strings.TrimSuffix(name, ".json"), md.Version, expires)
+	}
+	return tw.Flush()
+}
+
+// RefreshCmd updates the TUF metadata and targets if they expired, or always with force.
+func RefreshCmd(ctx context.Context, w io.Writer, force bool) error {
+	updated, err := tuf.Refresh(ctx, force)
+	if err != nil {
+		return errors.Wrap(err, "refreshing TUF metadata")
+	}
+	if updated {
+		fmt.Fprintln(w, "TUF metadata and targets updated")
+	} else {
+		fmt.Fprintln(w, "TUF metadata is up to date, use --force to update it anyway")
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tufcli
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/generate"
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/pkg/cosign/tuf"
+	sigs "github.com/sigstore/cosign/pkg/signature"
+)
+
+// RoleKeyRefs are the references to the keys of a role, and how many of them
+// must sign its metadata.
+type RoleKeyRefs struct {
+	KeyRefs   []string
+	Threshold int
+}
+
+// InitCmd stages the root of a new TUF repository in dir, with the keys of roles.
+func InitCmd(ctx context.Context, dir string, roles map[string]RoleKeyRefs, expires time.Duration) error {
+	keys := make(map[string]tuf.RoleKeys, len(roles))
+	for role, refs := range roles {
+		rk := tuf.RoleKeys{Threshold: refs.Threshold}
+		for _, ref := range refs.KeyRefs {
+			pub, err := publicKey(ctx, ref)
+			if err != nil {
+				return errors.Wrapf(err, "loading %s key %s", role, ref)
+			}
+			rk.Keys = append(rk.Keys, pub)
+		}
+		keys[role] = rk
+	}
+	if err := tuf.InitRepository(dir, keys, time.Now().Add(expires)); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Staged root.json, sign it with `cosign tuf sign` using the root keys")
+	return nil
+}
+
+func publicKey(ctx context.Context, keyRef string) (crypto.PublicKey, error) {
+	v, err := sigs.PublicKeyFromKeyRef(ctx, keyRef)
+	if err != nil {
+		return nil, err
+	}
+	if pkcs11Key, ok := v.(*pkcs11key.Key); ok {
+		defer pkcs11Key.Close()
+	}
+	return v.PublicKey()
+}
+
+// AddTargetCmd stages the file at path as the target name of the TUF repository in dir.
+func AddTargetCmd(ctx context.Context, dir, name, path, custom string, expires time.Duration) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "reading target %s", path)
+	}
+	var customJSON json.RawMessage
+	if custom != "" {
+		if !json.Valid([]byte(custom)) {
+			return fmt.Errorf("invalid custom metadata %q, expected JSON", custom)
+		}
+		customJSON = json.RawMessage(custom)
+	}
+	if err := tuf.AddTarget(dir, name, content, customJSON, time.Now().Add(expires)); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Staged targets.json, sign it with `cosign tuf sign` using the targets keys")
+	return nil
+}
+
+// SignCmd signs the staged metadata of the TUF repository in dir with the key
+// keyRef refers to, for roles, or all its roles if empty.
+func SignCmd(ctx context.Context, dir, keyRef string, roles []string) error {
+	sv, err := sigs.SignerVerifierFromKeyRef(ctx, keyRef, generate.GetPass)
+	if err != nil {
+		return errors.Wrap(err, "reading key")
+	}
+	if pkcs11Key, ok := sv.(*pkcs11key.Key); ok {
+		defer pkcs11Key.Close()
+	}
+	signed, err := tuf.SignRepository(dir, sv, roles)
+	if err != nil {
+		return err
+	}
+	if len(signed) == 0 {
+		return errors.New("the key is not a key of any role of the repository")
+	}
+	return nil
+}
+
+// PublishCmd stages the snapshot and timestamp metadata of the TUF repository
+// in dir, signed by the keys keyRefs refer to, commits it, and copies or pushes
+// the result to to, if set.
+func PublishCmd(ctx context.Context, dir string, keyRefs []string, snapshotExpires, timestampExpires time.Duration, to string, regOpts options.RegistryOptions) error {
+	var svs []signature.Signer
+	for _, ref := range keyRefs {
+		sv, err := sigs.SignerVerifierFromKeyRef(ctx, ref, generate.GetPass)
+		if err != nil {
+			return errors.Wrapf(err, "reading key %s", ref)
+		}
+		if pkcs11Key, ok := sv.(*pkcs11key.Key); ok {
+			defer pkcs11Key.Close()
+		}
+		svs = append(svs, sv)
+	}
+	now := time.Now()
+	if err := tuf.PublishRepository(dir, svs, now.Add(snapshotExpires), now.Add(timestampExpires)); err != nil {
+		return err
+	}
+
+	repo, err := filepath.Abs(tuf.RepositoryPath(dir))
+	if err != nil {
+		return err
+	}
+	mirror := "file://" + repo
+	switch {
+	case to == "":
+	case strings.HasPrefix(to, "file://"):
+		if err := copyDir(repo, strings.TrimPrefix(to, "file://")); err != nil {
+			return errors.Wrapf(err, "copying repository to %s", to)
+		}
+		mirror = to
+	case strings.HasPrefix(to, "oci://"):
+		ref, err := name.ParseReference(strings.TrimPrefix(to, "oci://"))
+		if err != nil {
+			return err
+		}
+		if err := tuf.PushRepository(ctx, dir, ref, regOpts.GetRegistryClientOpts(ctx)...); err != nil {
+			return err
+		}
+		mirror = to
+	default:
+		return fmt.Errorf("unsupported destination %s, expected a file:// or oci:// URL", to)
+	}
+	fmt.Fprintf(os.Stderr, "Published the repository, use it with:\n  cosign initialize --mirror %s --root %s\n", mirror, filepath.Join(repo, "root.json"))
+	return nil
+}
+
+// copyDir copies the files of the directory src to dst.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if fi.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
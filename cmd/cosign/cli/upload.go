@@ -33,6 +33,8 @@ func Upload() *cobra.Command {
 	cmd.AddCommand(
 		uploadBlob(),
 		uploadWASM(),
+		uploadHelm(),
+		uploadBundle(),
 	)
 
 	return cmd
@@ -96,3 +98,42 @@ func uploadWASM() *cobra.Command {
 
 	return cmd
 }
+
+func uploadHelm() *cobra.Command {
+	o := &options.UploadHelmOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "helm",
+		Short: "Upload a packaged Helm chart to the supplied container image reference",
+		Example: `  cosign upload helm -f mychart-0.1.0.tgz <image uri>
+
+  # upload a chart with its provenance file
+  cosign upload helm -f mychart-0.1.0.tgz --provenance mychart-0.1.0.tgz.prov <image uri>`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return upload.HelmCmd(cmd.Context(), o.Registry, o.File, o.Provenance, args[0])
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
+
+func uploadBundle() *cobra.Command {
+	o := &options.UploadBundleOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "bundle",
+		Short:   "Upload an Open Policy Agent bundle to the supplied container image reference",
+		Example: "  cosign upload bundle -f bundle.tar.gz <image uri>",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return upload.BundleCmd(cmd.Context(), o.Registry, o.File, args[0])
+		},
+	}
+
+	o.AddFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,101 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upload
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ggcrstatic "github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/sigstore/cosign/pkg/types"
+)
+
+// HelmCmd uploads a packaged Helm chart, and optionally its provenance file,
+// the way "helm push" does, so that it can be signed and verified like an image.
+func HelmCmd(ctx context.Context, regOpts options.RegistryOptions, chartPath, provenancePath, imageRef string) error {
+	chart, err := os.ReadFile(filepath.Clean(chartPath))
+	if err != nil {
+		return err
+	}
+	config, err := helmChartConfig(chart)
+	if err != nil {
+		return errors.Wrapf(err, "reading chart %s", chartPath)
+	}
+	layers := []v1.Layer{ggcrstatic.NewLayer(chart, types.HelmChartContentLayerMediaType)}
+	if provenancePath != "" {
+		prov, err := os.ReadFile(filepath.Clean(provenancePath))
+		if err != nil {
+			return err
+		}
+		layers = append(layers, ggcrstatic.NewLayer(prov, types.HelmChartProvenanceLayerMediaType))
+	}
+
+	ref, err := name.ParseReference(imageRef)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Uploading Helm chart from [%s] to [%s].\n", chartPath, ref.Name())
+	img, err := static.NewArtifact(config, types.HelmChartConfigMediaType, layers...)
+	if err != nil {
+		return err
+	}
+	return remote.Write(ref, img, regOpts.GetRegistryClientOpts(ctx)...)
+}
+
+// helmChartConfig returns the metadata of a packaged chart, its Chart.yaml as
+// JSON, which Helm stores as the config of charts in registries.
+func helmChartConfig(chart []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(chart))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, errors.New("no Chart.yaml found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		// The chart metadata is at the root of the chart directory, not in
+		// the charts it depends on.
+		if hdr.Typeflag != tar.TypeReg || path.Base(hdr.Name) != "Chart.yaml" || path.Dir(path.Dir(path.Clean(hdr.Name))) != "." {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		return yaml.YAMLToJSON(b)
+	}
+}
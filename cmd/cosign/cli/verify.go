@@ -59,6 +59,12 @@ against the transparency log.`,
   # verify image with public key provided by URL
   cosign verify --key https://host.for/[FILE] <IMAGE>
 
+  # verify a multi-arch image and each of its discrete images
+  cosign verify --key cosign.pub --recursive <MULTI-ARCH IMAGE>
+
+  # verify only the linux/amd64 image of a multi-arch image
+  cosign verify --key cosign.pub --platform linux/amd64 <MULTI-ARCH IMAGE>
+
   # verify image with public key stored in Google Cloud KMS
   cosign verify --key gcpkms://projects/[PROJECT]/locations/global/keyRings/[KEYRING]/cryptoKeys/[KEY] <IMAGE>
 
@@ -72,7 +78,13 @@ against the transparency log.`,
   cosign verify --key gitlab://[OWNER]/[PROJECT_NAME] <IMAGE>
 
   # verify image with public key stored in GitLab with project id
-  cosign verify --key gitlab://[PROJECT_ID] <IMAGE>`,
+  cosign verify --key gitlab://[PROJECT_ID] <IMAGE>
+
+  # verify image with public key stored in a GitHub repository variable, or committed as cosign.pub
+  cosign verify --key github://[OWNER]/[REPO] <IMAGE>
+
+  # verify image with public key committed as cosign.pub at a git ref of a GitHub repository
+  cosign verify --key github://[OWNER]/[REPO]@[REF] <IMAGE>`,
 
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -80,6 +92,10 @@ against the transparency log.`,
 			if err != nil {
 				return err
 			}
+			annotationMatchers, err := o.AnnotationMatchers()
+			if err != nil {
+				return err
+			}
 
 			hashAlgorithm, err := o.SignatureDigest.HashAlgorithm()
 			if err != nil {
@@ -87,21 +103,31 @@ against the transparency log.`,
 			}
 
 			v := verify.VerifyCommand{
-				RegistryOptions: o.Registry,
-				CheckClaims:     o.CheckClaims,
-				KeyRef:          o.Key,
-				CertRef:         o.CertVerify.Cert,
-				CertEmail:       o.CertVerify.CertEmail,
-				CertOidcIssuer:  o.CertVerify.CertOidcIssuer,
-				Sk:              o.SecurityKey.Use,
-				Slot:            o.SecurityKey.Slot,
-				Output:          o.Output,
-				RekorURL:        o.Rekor.URL,
-				Attachment:      o.Attachment,
-				Annotations:     annotations,
-				HashAlgorithm:   hashAlgorithm,
-				SignatureRef:    o.SignatureRef,
-				LocalImage:      o.LocalImage,
+				RegistryOptions:    o.Registry,
+				CheckClaims:        o.CheckClaims,
+				KeyRef:             o.Key,
+				CertRef:            o.CertVerify.Cert,
+				CertEmail:          o.CertVerify.CertEmail,
+				CertOidcIssuer:     o.CertVerify.CertOidcIssuer,
+				Sk:                 o.SecurityKey.Use,
+				Slot:               o.SecurityKey.Slot,
+				PIVAttestation:     o.PIVAttestation,
+				Output:             o.Output,
+				RekorURL:           o.Rekor.URL,
+				Attachment:         o.Attachment,
+				Annotations:        annotations,
+				AnnotationMatchers: annotationMatchers,
+				HashAlgorithm:      hashAlgorithm,
+				SignatureRef:       o.SignatureRef,
+				LocalImage:         o.LocalImage,
+				MediaType:          o.MediaType,
+				Recursive:          o.Recursive,
+				Platform:           o.Platform,
+				StrictIdentity:     o.StrictIdentity,
+				IdentityAliases:    o.IdentityAliases,
+				MaxAge:             o.MaxAge,
+				LegacySHA256:       o.LegacySHA256,
+				RevocationList:     o.RevocationList,
 			}
 
 			return v.Exec(cmd.Context(), args)
@@ -153,7 +179,10 @@ against the transparency log.`,
   cosign verify-attestation --key gitlab://[OWNER]/[PROJECT_NAME] <IMAGE>
 
   # verify image with public key stored in GitLab with project id
-  cosign verify-attestation --key gitlab://[PROJECT_ID] <IMAGE>`,
+  cosign verify-attestation --key gitlab://[PROJECT_ID] <IMAGE>
+
+  # verify image with public key stored in a GitHub repository variable, or committed as cosign.pub
+  cosign verify-attestation --key github://[OWNER]/[REPO] <IMAGE>`,
 
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -171,6 +200,8 @@ against the transparency log.`,
 				PredicateType:   o.Predicate.Type,
 				Policies:        o.Policies,
 				LocalImage:      o.LocalImage,
+				RevocationList:  o.RevocationList,
+				LegacySHA256:    o.LegacySHA256,
 			}
 			return v.Exec(cmd.Context(), args)
 		},
@@ -229,16 +260,20 @@ The blob may be specified as a path to a file or - for stdin.`,
 
   # Verify a signature against a certificate
   cosign verify-blob --cert <cert> --signature $sig <blob>
+
+  # Verify a blob with only the bundle written by 'cosign sign-blob --bundle', against locally supplied roots
+  SIGSTORE_ROOT_FILE=<fulcio root> SIGSTORE_REKOR_PUBLIC_KEY=<rekor key> cosign verify-blob --bundle <bundle> <blob>
 `,
 
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ko := sign.KeyOpts{
-				KeyRef:     o.Key,
-				Sk:         o.SecurityKey.Use,
-				Slot:       o.SecurityKey.Slot,
-				RekorURL:   o.Rekor.URL,
-				BundlePath: o.BundlePath,
+				KeyRef:       o.Key,
+				Sk:           o.SecurityKey.Use,
+				Slot:         o.SecurityKey.Slot,
+				RekorURL:     o.Rekor.URL,
+				BundlePath:   o.BundlePath,
+				LegacySHA256: o.LegacySHA256,
 			}
 			if err := verify.VerifyBlobCmd(cmd.Context(), ko, o.CertVerify.Cert,
 				o.CertVerify.CertEmail, o.CertVerify.CertOidcIssuer, o.Signature, args[0]); err != nil {
@@ -251,3 +286,50 @@ The blob may be specified as a path to a file or - for stdin.`,
 	o.AddFlags(cmd)
 	return cmd
 }
+
+func VerifyBlobAttestation() *cobra.Command {
+	o := &options.VerifyBlobAttestationOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify-blob-attestation",
+		Short: "Verify an attestation on the supplied blob",
+		Long: `Verify an in-toto attestation, as created by 'cosign attest-blob', on the supplied blob.
+The attestation subject must match the sha256 digest of the blob.
+The attestation may be specified as a path to a file or a remote URL.`,
+		Example: `  cosign verify-blob-attestation (--key <key path>|<key url>|<kms uri>)|(--cert <cert>) --signature <attestation> [--type <type>] [--policy <policy>] <blob>
+
+  # verify a blob attestation with a public key
+  cosign verify-blob-attestation --key cosign.pub --signature <ATT> --type slsaprovenance <BLOB>
+
+  # verify a blob attestation and validate the statement against a CUE policy
+  cosign verify-blob-attestation --key cosign.pub --signature <ATT> --policy policy.cue <BLOB>
+
+  # verify a keyless blob attestation against its certificate and the transparency log
+  cosign verify-blob-attestation --cert <CERT> --signature <ATT> <BLOB>`,
+
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := verify.VerifyBlobAttestationCommand{
+				CheckClaims:    o.CheckClaims,
+				CertRef:        o.CertVerify.Cert,
+				CertEmail:      o.CertVerify.CertEmail,
+				CertOidcIssuer: o.CertVerify.CertOidcIssuer,
+				KeyRef:         o.Key,
+				Sk:             o.SecurityKey.Use,
+				Slot:           o.SecurityKey.Slot,
+				RekorURL:       o.Rekor.URL,
+				SignaturePath:  o.Signature,
+				PredicateType:  o.Predicate.Type,
+				Policies:       o.Policies,
+				LegacySHA256:   o.LegacySHA256,
+			}
+			if err := v.Exec(cmd.Context(), args[0]); err != nil {
+				return errors.Wrapf(err, "verifying blob attestation %s", args[0])
+			}
+			return nil
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
@@ -18,15 +18,18 @@ package verify
 import (
 	"context"
 	"crypto"
-	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/pkg/errors"
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/fulcio"
@@ -38,6 +41,7 @@ import (
 	"github.com/sigstore/cosign/pkg/cosign/pivkey"
 	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
 	"github.com/sigstore/cosign/pkg/oci"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
 	sigs "github.com/sigstore/cosign/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
@@ -48,20 +52,30 @@ import (
 // nolint
 type VerifyCommand struct {
 	options.RegistryOptions
-	CheckClaims    bool
-	KeyRef         string
-	CertRef        string
-	CertEmail      string
-	CertOidcIssuer string
-	Sk             bool
-	Slot           string
-	Output         string
-	RekorURL       string
-	Attachment     string
-	Annotations    sigs.AnnotationsMap
-	SignatureRef   string
-	HashAlgorithm  crypto.Hash
-	LocalImage     bool
+	CheckClaims        bool
+	KeyRef             string
+	CertRef            string
+	CertEmail          string
+	CertOidcIssuer     string
+	Sk                 bool
+	Slot               string
+	PIVAttestation     options.PIVAttestationOptions
+	RevocationList     options.RevocationListOptions
+	Output             string
+	RekorURL           string
+	Attachment         string
+	Annotations        sigs.AnnotationsMap
+	AnnotationMatchers []cosign.AnnotationMatcher
+	SignatureRef       string
+	HashAlgorithm      crypto.Hash
+	LocalImage         bool
+	MediaType          string
+	Recursive          bool
+	Platform           string
+	StrictIdentity     bool
+	IdentityAliases    []string
+	MaxAge             time.Duration
+	LegacySHA256       bool
 }
 
 // Exec runs the verification command
@@ -77,33 +91,113 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 		return flag.ErrHelp
 	}
 
-	// always default to sha256 if the algorithm hasn't been explicitly set
-	if c.HashAlgorithm == 0 {
-		c.HashAlgorithm = crypto.SHA256
+	if c.LocalImage && (c.Recursive || c.Platform != "") {
+		return errors.New("--recursive and --platform are not supported with --local-image")
+	}
+	var platform *v1.Platform
+	if c.Platform != "" {
+		if platform, err = parsePlatform(c.Platform); err != nil {
+			return err
+		}
+	}
+
+	co, closeKeys, err := c.CheckOpts(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeKeys()
+	ociremoteOpts := co.RegistryClientOpts
+
+	for _, img := range images {
+		if c.LocalImage {
+			if c.StrictIdentity {
+				// There is no repository to match, only aliases.
+				if co.IdentityRepositories, err = c.identityRepositories(nil); err != nil {
+					return err
+				}
+			}
+			verified, bundleVerified, err := cosign.VerifyLocalImageSignatures(ctx, img, co)
+			if err != nil {
+				return err
+			}
+			PrintVerificationHeader(img, co, bundleVerified)
+			PrintVerification(img, verified, c.Output)
+		} else {
+			ref, err := name.ParseReference(img)
+			if err != nil {
+				return errors.Wrap(err, "parsing reference")
+			}
+			ref, err = sign.GetAttachedImageRef(ref, c.Attachment, ociremoteOpts...)
+			if err != nil {
+				return errors.Wrapf(err, "resolving attachment type %s for image %s", c.Attachment, img)
+			}
+			if c.StrictIdentity {
+				repo := ref.Context()
+				if co.IdentityRepositories, err = c.identityRepositories(&repo); err != nil {
+					return err
+				}
+			}
+
+			if c.Recursive || platform != nil {
+				if err := c.verifyEntities(ctx, ref, platform, co); err != nil {
+					return err
+				}
+				continue
+			}
+
+			verified, bundleVerified, err := cosign.VerifyImageSignatures(ctx, ref, co)
+			if err != nil {
+				return err
+			}
+
+			PrintVerificationHeader(ref.Name(), co, bundleVerified)
+			PrintVerification(ref.Name(), verified, c.Output)
+		}
 	}
 
+	return nil
+}
+
+// CheckOpts returns the options to verify signatures as configured by c, and a
+// function releasing the keys they use, to be called once done verifying.
+func (c *VerifyCommand) CheckOpts(ctx context.Context) (*cosign.CheckOpts, func(), error) {
 	if !options.OneOf(c.KeyRef, c.CertRef, c.Sk) && !options.EnableExperimental() {
-		return &options.KeyParseError{}
+		return nil, nil, &options.KeyParseError{}
 	}
 	ociremoteOpts, err := c.ClientOpts(ctx)
 	if err != nil {
-		return errors.Wrap(err, "constructing client options")
+		return nil, nil, errors.Wrap(err, "constructing client options")
 	}
 	co := &cosign.CheckOpts{
 		Annotations:        c.Annotations.Annotations,
+		AnnotationMatchers: c.AnnotationMatchers,
 		RegistryClientOpts: ociremoteOpts,
 		CertEmail:          c.CertEmail,
 		CertOidcIssuer:     c.CertOidcIssuer,
 		SignatureRef:       c.SignatureRef,
+		LegacySHA256:       c.LegacySHA256,
 	}
 	if c.CheckClaims {
 		co.ClaimVerifier = cosign.SimpleClaimVerifier
 	}
+	if co.PIVAttestation, err = pivAttestationOpts(c.PIVAttestation); err != nil {
+		return nil, nil, err
+	}
+	co.MaxAge = c.MaxAge
+	if co.RevocationList, err = revocationList(ctx, c.RevocationList, ociremoteOpts); err != nil {
+		return nil, nil, err
+	}
+	if at, ok := cosign.LookupArtifactType(c.MediaType); ok {
+		co.ConfigMediaType = at.ConfigMediaType
+		co.LayerMediaType = at.LayerMediaType
+	} else if c.MediaType != "" {
+		co.LayerMediaType = types.MediaType(c.MediaType)
+	}
 	if options.EnableExperimental() {
 		if c.RekorURL != "" {
 			rekorClient, err := rekor.NewClient(c.RekorURL)
 			if err != nil {
-				return errors.Wrap(err, "creating Rekor client")
+				return nil, nil, errors.Wrap(err, "creating Rekor client")
 			}
 			co.RekorClient = rekorClient
 		}
@@ -114,67 +208,60 @@ func (c *VerifyCommand) Exec(ctx context.Context, images []string) (err error) {
 
 	// Keys are optional!
 	var pubKey signature.Verifier
+	closeKeys := func() {}
 	switch {
 	case keyRef != "":
 		pubKey, err = sigs.PublicKeyFromKeyRefWithHashAlgo(ctx, keyRef, c.HashAlgorithm)
 		if err != nil {
-			return errors.Wrap(err, "loading public key")
+			return nil, nil, errors.Wrap(err, "loading public key")
 		}
 		pkcs11Key, ok := pubKey.(*pkcs11key.Key)
 		if ok {
-			defer pkcs11Key.Close()
+			closeKeys = pkcs11Key.Close
 		}
 	case c.Sk:
 		sk, err := pivkey.GetKeyWithSlot(c.Slot)
 		if err != nil {
-			return errors.Wrap(err, "opening piv token")
+			return nil, nil, errors.Wrap(err, "opening piv token")
 		}
-		defer sk.Close()
 		pubKey, err = sk.Verifier()
 		if err != nil {
-			return errors.Wrap(err, "initializing piv token verifier")
+			sk.Close()
+			return nil, nil, errors.Wrap(err, "initializing piv token verifier")
 		}
+		closeKeys = sk.Close
 	case certRef != "":
 		cert, err := loadCertFromFileOrURL(c.CertRef)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
-		pubKey, err = signature.LoadECDSAVerifier(cert.PublicKey.(*ecdsa.PublicKey), crypto.SHA256)
+		pubKey, err = cosign.LoadVerifier(cert.PublicKey)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 	co.SigVerifier = pubKey
+	return co, closeKeys, nil
+}
 
-	for _, img := range images {
-		if c.LocalImage {
-			verified, bundleVerified, err := cosign.VerifyLocalImageSignatures(ctx, img, co)
-			if err != nil {
-				return err
-			}
-			PrintVerificationHeader(img, co, bundleVerified)
-			PrintVerification(img, verified, c.Output)
-		} else {
-			ref, err := name.ParseReference(img)
-			if err != nil {
-				return errors.Wrap(err, "parsing reference")
-			}
-			ref, err = sign.GetAttachedImageRef(ref, c.Attachment, ociremoteOpts...)
-			if err != nil {
-				return errors.Wrapf(err, "resolving attachment type %s for image %s", c.Attachment, img)
-			}
-
-			verified, bundleVerified, err := cosign.VerifyImageSignatures(ctx, ref, co)
-			if err != nil {
-				return err
-			}
-
-			PrintVerificationHeader(ref.Name(), co, bundleVerified)
-			PrintVerification(ref.Name(), verified, c.Output)
+// identityRepositories returns the repositories accepted as docker-reference
+// of signatures of images in repo.
+func (c *VerifyCommand) identityRepositories(repo *name.Repository) ([]name.Repository, error) {
+	var repos []name.Repository
+	if repo != nil {
+		repos = append(repos, *repo)
+	}
+	for _, alias := range c.IdentityAliases {
+		r, err := name.NewRepository(alias)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing identity alias %s", alias)
 		}
+		repos = append(repos, r)
 	}
-
-	return nil
+	if len(repos) == 0 {
+		return nil, errors.New("--strict-identity with --local-image requires --identity-alias")
+	}
+	return repos, nil
 }
 
 func PrintVerificationHeader(imgRef string, co *cosign.CheckOpts, bundleVerified bool) {
@@ -186,6 +273,15 @@ func PrintVerificationHeader(imgRef string, co *cosign.CheckOpts, bundleVerified
 		}
 		fmt.Fprintln(os.Stderr, "  - The cosign claims were validated")
 	}
+	if len(co.AnnotationMatchers) > 0 {
+		fmt.Fprintln(os.Stderr, "  - The annotations matched the specified patterns")
+	}
+	if len(co.IdentityRepositories) > 0 {
+		fmt.Fprintln(os.Stderr, "  - The docker-reference claims named the verified repository")
+	}
+	if co.MaxAge != 0 {
+		fmt.Fprintf(os.Stderr, "  - The timestamp claims were no older than %s\n", co.MaxAge)
+	}
 	if bundleVerified {
 		fmt.Fprintln(os.Stderr, "  - Existence of the claims in the transparency log was verified offline")
 	} else if co.RekorClient != nil {
@@ -195,6 +291,14 @@ func PrintVerificationHeader(imgRef string, co *cosign.CheckOpts, bundleVerified
 	if co.SigVerifier != nil {
 		fmt.Fprintln(os.Stderr, "  - The signatures were verified against the specified public key")
 	}
+	if at, ok := cosign.ArtifactTypeForConfig(co.ConfigMediaType); ok {
+		fmt.Fprintf(os.Stderr, "  - The signed artifact is a %s\n", at.Description)
+	} else if co.LayerMediaType != "" {
+		fmt.Fprintf(os.Stderr, "  - The signed artifact has a layer of media type %s\n", co.LayerMediaType)
+	}
+	if co.RevocationList != nil {
+		fmt.Fprintln(os.Stderr, "  - The signatures were not revoked by the revocation list")
+	}
 	fmt.Fprintln(os.Stderr, "  - Any certificates were verified against the Fulcio roots.")
 }
 
@@ -287,3 +391,63 @@ func loadCertFromPEM(pems []byte) (*x509.Certificate, error) {
 	}
 	return certs[0], nil
 }
+
+// revocationList fetches and verifies the revocation list of o, if any.
+func revocationList(ctx context.Context, o options.RevocationListOptions, ociremoteOpts []ociremote.Option) (*cosign.RevocationList, error) {
+	if o.Source == "" {
+		if o.Key != "" {
+			return nil, errors.New("--revocation-list-key requires --revocation-list")
+		}
+		return nil, nil
+	}
+	if o.Key == "" {
+		return nil, errors.New("--revocation-list requires --revocation-list-key")
+	}
+	var minIssued time.Time
+	if o.MinIssued != "" {
+		var err error
+		if minIssued, err = time.Parse(time.RFC3339, o.MinIssued); err != nil {
+			return nil, errors.Wrap(err, "parsing --revocation-list-min-issued")
+		}
+	}
+	verifier, err := sigs.PublicKeyFromKeyRef(ctx, o.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading revocation list public key")
+	}
+	signed, err := cosign.FetchRevocationList(ctx, o.Source, ociremoteOpts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching revocation list %s", o.Source)
+	}
+	return cosign.VerifyRevocationList(ctx, verifier, signed, minIssued)
+}
+
+// pivAttestationOpts returns the requirements on the PIV attestation of the
+// signing key o sets, if any.
+func pivAttestationOpts(o options.PIVAttestationOptions) (*cosign.PIVAttestationOpts, error) {
+	if o.Root == "" {
+		if len(o.PINPolicies) > 0 || len(o.TouchPolicies) > 0 {
+			return nil, errors.New("--piv-pin-policy and --piv-touch-policy require --piv-attestation-root")
+		}
+		return nil, nil
+	}
+	pemBytes, err := os.ReadFile(filepath.Clean(o.Root))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading PIV attestation root")
+	}
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(pemBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading PIV attestation root")
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate found in the PIV attestation root")
+	}
+	roots := x509.NewCertPool()
+	for _, c := range certs {
+		roots.AddCert(c)
+	}
+	return &cosign.PIVAttestationOpts{
+		Roots:         roots,
+		PINPolicies:   o.PINPolicies,
+		TouchPolicies: o.TouchPolicies,
+	}, nil
+}
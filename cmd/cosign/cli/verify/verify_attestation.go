@@ -17,8 +17,6 @@ package verify
 
 import (
 	"context"
-	"crypto"
-	"crypto/ecdsa"
 	"encoding/base64"
 	"encoding/json"
 	"flag"
@@ -32,7 +30,6 @@ import (
 	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
 	"github.com/sigstore/cosign/pkg/cosign/rego"
 	"github.com/sigstore/cosign/pkg/oci"
-	"github.com/sigstore/sigstore/pkg/signature"
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/fulcio"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
@@ -59,6 +56,8 @@ type VerifyAttestationCommand struct {
 	PredicateType  string
 	Policies       []string
 	LocalImage     bool
+	RevocationList options.RevocationListOptions
+	LegacySHA256   bool
 }
 
 // Exec runs the verification command
@@ -79,10 +78,14 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		RegistryClientOpts: ociremoteOpts,
 		CertEmail:          c.CertEmail,
 		CertOidcIssuer:     c.CertOidcIssuer,
+		LegacySHA256:       c.LegacySHA256,
 	}
 	if c.CheckClaims {
 		co.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
 	}
+	if co.RevocationList, err = revocationList(ctx, c.RevocationList, ociremoteOpts); err != nil {
+		return err
+	}
 	if options.EnableExperimental() {
 		if c.RekorURL != "" {
 			rekorClient, err := rekor.NewClient(c.RekorURL)
@@ -121,7 +124,7 @@ func (c *VerifyAttestationCommand) Exec(ctx context.Context, images []string) (e
 		if err != nil {
 			return errors.Wrap(err, "loading certificate from reference")
 		}
-		co.SigVerifier, err = signature.LoadECDSAVerifier(cert.PublicKey.(*ecdsa.PublicKey), crypto.SHA256)
+		co.SigVerifier, err = cosign.LoadVerifier(cert.PublicKey)
 		if err != nil {
 			return errors.Wrap(err, "creating certificate verifier")
 		}
@@ -19,13 +19,13 @@ import (
 	"bytes"
 	"context"
 	"crypto"
-	"crypto/ecdsa"
-	_ "crypto/sha256" // for `crypto.SHA256`
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/runtime"
@@ -67,11 +67,16 @@ func VerifyBlobCmd(ctx context.Context, ko sign.KeyOpts, certRef, certEmail, cer
 		return err
 	}
 
-	blobBytes, err := payloadBytes(blobRef)
-	if err != nil {
-		return err
+	var b *cosign.LocalSignedPayload
+	if ko.BundlePath != "" {
+		b, err = cosign.FetchLocalSignedPayloadFromPath(ko.BundlePath)
+		if err != nil {
+			return err
+		}
 	}
 
+	var digest []byte
+
 	// Keys are optional!
 	switch {
 	case ko.KeyRef != "":
@@ -98,15 +103,11 @@ func VerifyBlobCmd(ctx context.Context, ko sign.KeyOpts, certRef, certEmail, cer
 		if err != nil {
 			return err
 		}
-		verifier, err = signature.LoadECDSAVerifier(cert.PublicKey.(*ecdsa.PublicKey), crypto.SHA256)
+		verifier, err = cosign.LoadVerifier(cert.PublicKey)
 		if err != nil {
 			return err
 		}
 	case ko.BundlePath != "":
-		b, err := cosign.FetchLocalSignedPayloadFromPath(ko.BundlePath)
-		if err != nil {
-			return err
-		}
 		if b.Cert == "" {
 			return fmt.Errorf("bundle does not contain cert for verification, please provide public key")
 		}
@@ -118,9 +119,21 @@ func VerifyBlobCmd(ctx context.Context, ko sign.KeyOpts, certRef, certEmail, cer
 		cert, err = loadCertFromPEM(certBytes)
 		if err != nil {
 			// check if cert is actually a public key
-			verifier, err = sigs.LoadPublicKeyRaw(certBytes, crypto.SHA256)
+			verifier, err = sigs.LoadPublicKeyRaw(certBytes, 0)
 		} else {
-			verifier, err = signature.LoadECDSAVerifier(cert.PublicKey.(*ecdsa.PublicKey), crypto.SHA256)
+			// A certificate in the bundle is only as good as its chain to the Fulcio roots.
+			co := &cosign.CheckOpts{
+				RootCerts:      fulcio.GetRoots(),
+				CertEmail:      certEmail,
+				CertOidcIssuer: certOidcIssuer,
+			}
+			if b.Chain != "" {
+				co.IntermediateCerts, err = loadCertPoolFromPEM(b.Chain)
+				if err != nil {
+					return errors.Wrap(err, "loading certificate chain from bundle")
+				}
+			}
+			verifier, err = cosign.ValidateAndUnpackCert(cert, co)
 		}
 		if err != nil {
 			return err
@@ -131,7 +144,13 @@ func VerifyBlobCmd(ctx context.Context, ko sign.KeyOpts, certRef, certEmail, cer
 			return err
 		}
 
-		uuids, err := cosign.FindTLogEntriesByPayload(ctx, rClient, blobBytes)
+		// Keyless signatures are made with Fulcio's ECDSA P-256 certificates, so the
+		// entry is looked up, and the blob later verified, by its sha256 digest.
+		digest, err = payloadDigest(blobRef, crypto.SHA256)
+		if err != nil {
+			return err
+		}
+		uuids, err := cosign.FindTLogEntriesByDigest(ctx, rClient, digest, crypto.SHA256)
 		if err != nil {
 			return err
 		}
@@ -162,13 +181,37 @@ func VerifyBlobCmd(ctx context.Context, ko sign.KeyOpts, certRef, certEmail, cer
 		}
 	}
 
-	// verify the signature
-	if err := verifier.VerifySignature(bytes.NewReader([]byte(sig)), bytes.NewReader(blobBytes)); err != nil {
+	// Keys that sign over a digest only need the blob streamed through the hash matching
+	// the key, so it never has to fit in memory. ED25519 keys sign the message itself, so
+	// the blob is read in full, and its sha256 digest is what a bundle records.
+	var message []byte
+	digests := map[crypto.Hash][]byte{crypto.SHA256: digest}
+	if digest == nil {
+		digests, message, err = blobDigests(ctx, verifier, blobRef)
+		if err != nil {
+			return err
+		}
+	}
+
+	// verify the signature, with the key of a key ring that made it, as its type
+	// decides which digest is signed and which key the tlog entry has.
+	var hashAlgorithm crypto.Hash
+	verifier, err = cosign.SigningVerifier(verifier, func(v signature.Verifier) error {
+		var err error
+		digest, hashAlgorithm, err = verifyBlobSignature(ctx, v, sig, message, digests, ko.LegacySHA256)
+		return err
+	})
+	if err != nil {
 		return err
 	}
+	if b != nil {
+		if err := verifyPayloadDigest(b.PayloadDigest, digest, hashAlgorithm); err != nil {
+			return err
+		}
+	}
 
 	// verify the rekor entry
-	if err := verifyRekorEntry(ctx, ko, verifier, cert, b64sig, blobBytes); err != nil {
+	if err := verifyRekorEntry(ctx, ko, b, verifier, cert, b64sig, digest, hashAlgorithm); err != nil {
 		return err
 	}
 
@@ -176,6 +219,90 @@ func VerifyBlobCmd(ctx context.Context, ko sign.KeyOpts, certRef, certEmail, cer
 	return nil
 }
 
+// blobDigests returns the digests of the blob at blobRef for each of the hash
+// algorithms the keys of verifier sign with, and sha256, which bundles record for
+// keys signing the message itself, and legacy signatures of other keys are over,
+// see cosign.LegacySHA256Verifier. The blob is only read once, and returned in
+// full if a key signs the message itself.
+func blobDigests(ctx context.Context, verifier signature.Verifier, blobRef string) (map[crypto.Hash][]byte, []byte, error) {
+	verifiers := []signature.Verifier{verifier}
+	if ring, ok := verifier.(cosign.KeyRing); ok {
+		verifiers = ring.Verifiers()
+	}
+	hs := []crypto.Hash{crypto.SHA256}
+	needMessage := false
+	for _, v := range verifiers {
+		pub, err := v.PublicKey(signatureoptions.WithContext(ctx))
+		if err != nil {
+			return nil, nil, err
+		}
+		switch h := cosign.HashAlgorithmForKey(pub); {
+		case h == 0:
+			needMessage = true
+		case !containsHash(hs, h):
+			hs = append(hs, h)
+		}
+	}
+
+	var message []byte
+	var ds [][]byte
+	var err error
+	if needMessage {
+		if message, err = loadBlob(blobRef); err == nil {
+			ds, err = blob.Digests(bytes.NewReader(message), hs...)
+		}
+	} else {
+		ds, err = payloadDigests(blobRef, hs...)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	digests := map[crypto.Hash][]byte{}
+	for i, h := range hs {
+		digests[h] = ds[i]
+	}
+	return digests, message, nil
+}
+
+func containsHash(hs []crypto.Hash, h crypto.Hash) bool {
+	for _, x := range hs {
+		if x == h {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyBlobSignature verifies sig with verifier, over message for keys signing
+// the message itself, or else over the digest in digests matching the key, or the
+// sha256 one with legacySHA256. It returns the digest the signature covers, the
+// sha256 digest of the message for the former, and the hash algorithm of that
+// digest, or zero for the former.
+func verifyBlobSignature(ctx context.Context, verifier signature.Verifier, sig string, message []byte, digests map[crypto.Hash][]byte, legacySHA256 bool) ([]byte, crypto.Hash, error) {
+	pub, err := verifier.PublicKey(signatureoptions.WithContext(ctx))
+	if err != nil {
+		return nil, 0, err
+	}
+	hashAlgorithm := cosign.HashAlgorithmForKey(pub)
+	if hashAlgorithm == 0 && message != nil {
+		return digests[crypto.SHA256], 0, verifier.VerifySignature(bytes.NewReader([]byte(sig)), bytes.NewReader(message))
+	}
+	digest, ok := digests[hashAlgorithm]
+	if !ok {
+		return nil, 0, fmt.Errorf("unexpected key type %T for a keyless signature", pub)
+	}
+	err = verifier.VerifySignature(bytes.NewReader([]byte(sig)), nil,
+		signatureoptions.WithDigest(digest), signatureoptions.WithCryptoSignerOpts(hashAlgorithm))
+	if err != nil && legacySHA256 && hashAlgorithm != crypto.SHA256 {
+		// Cosign signed SHA-256 digests with all key types before, see cosign.LegacySHA256Verifier.
+		if verifier.VerifySignature(bytes.NewReader([]byte(sig)), nil,
+			signatureoptions.WithDigest(digests[crypto.SHA256]), signatureoptions.WithCryptoSignerOpts(crypto.SHA256)) == nil {
+			return digests[crypto.SHA256], crypto.SHA256, nil
+		}
+	}
+	return digest, hashAlgorithm, err
+}
+
 // signatures returns the raw signature and the base64 encoded signature
 func signatures(sigRef string, bundlePath string) (string, string, error) {
 	var targetSig []byte
@@ -212,27 +339,55 @@ func signatures(sigRef string, bundlePath string) (string, string, error) {
 	return sig, b64sig, nil
 }
 
-func payloadBytes(blobRef string) ([]byte, error) {
-	var blobBytes []byte
-	var err error
-	if blobRef == "-" {
-		blobBytes, err = io.ReadAll(os.Stdin)
-	} else {
-		blobBytes, err = blob.LoadFileOrURL(blobRef)
+// payloadDigest streams the blob from a file, URL or, for "-", stdin and returns its digest.
+func payloadDigest(blobRef string, hashAlgorithm crypto.Hash) ([]byte, error) {
+	r, err := openBlob(blobRef)
+	if err != nil {
+		return nil, err
 	}
+	defer r.Close()
+	return blob.Digest(r, hashAlgorithm)
+}
+
+// payloadDigests is like payloadDigest, returning the digest for each of hs.
+func payloadDigests(blobRef string, hs ...crypto.Hash) ([][]byte, error) {
+	r, err := openBlob(blobRef)
 	if err != nil {
 		return nil, err
 	}
-	return blobBytes, nil
+	defer r.Close()
+	return blob.Digests(r, hs...)
+}
+
+// loadBlob reads the whole blob from a file, URL or, for "-", stdin.
+func loadBlob(blobRef string) ([]byte, error) {
+	r, err := openBlob(blobRef)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func openBlob(blobRef string) (io.ReadCloser, error) {
+	if blobRef == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return blob.OpenFileOrURL(blobRef)
 }
 
-func verifyRekorEntry(ctx context.Context, ko sign.KeyOpts, pubKey signature.Verifier, cert *x509.Certificate, b64sig string, blobBytes []byte) error {
+func verifyRekorEntry(ctx context.Context, ko sign.KeyOpts, b *cosign.LocalSignedPayload, pubKey signature.Verifier, cert *x509.Certificate, b64sig string, digest []byte, hashAlgorithm crypto.Hash) error {
 	// If we have a bundle with a rekor entry, let's first try to verify offline
-	if ko.BundlePath != "" {
-		if err := verifyRekorBundle(ctx, ko.BundlePath, cert); err == nil {
+	if b != nil && b.Bundle != nil {
+		err := verifyRekorBundle(ctx, b, cert, b64sig, digest, hashAlgorithm)
+		if err == nil {
 			fmt.Fprintf(os.Stderr, "tlog entry verified offline\n")
 			return nil
 		}
+		// Only fall back to looking the entry up online in experimental mode.
+		if !options.EnableExperimental() {
+			return errors.Wrap(err, "verifying bundled tlog entry")
+		}
 	}
 	if !options.EnableExperimental() {
 		return nil
@@ -255,7 +410,7 @@ func verifyRekorEntry(ctx context.Context, ko sign.KeyOpts, pubKey signature.Ver
 			return err
 		}
 	}
-	uuid, index, err := cosign.FindTlogEntry(ctx, rekorClient, b64sig, blobBytes, pubBytes)
+	uuid, index, err := cosign.FindTlogEntryByDigest(ctx, rekorClient, b64sig, digest, hashAlgorithm, pubBytes)
 	if err != nil {
 		return err
 	}
@@ -272,32 +427,53 @@ func verifyRekorEntry(ctx context.Context, ko sign.KeyOpts, pubKey signature.Ver
 	return cosign.CheckExpiry(cert, time.Unix(*e.IntegratedTime, 0))
 }
 
-func verifyRekorBundle(ctx context.Context, bundlePath string, cert *x509.Certificate) error {
-	b, err := cosign.FetchLocalSignedPayloadFromPath(bundlePath)
-	if err != nil {
-		return err
-	}
+// verifyRekorBundle checks the SET of the bundled rekor entry, that the entry is for
+// this blob and, if it was signed with a certificate, that the entry was made while
+// the certificate was valid.
+func verifyRekorBundle(ctx context.Context, b *cosign.LocalSignedPayload, cert *x509.Certificate, b64sig string, digest []byte, hashAlgorithm crypto.Hash) error {
 	if b.Bundle == nil {
 		return fmt.Errorf("rekor entry is not available")
 	}
-	pub, err := cosign.GetRekorPub(ctx)
+	return cosign.VerifyBundleDigest(ctx, b.Bundle, cert, b64sig, digest, hashAlgorithm)
+}
+
+// verifyPayloadDigest checks the blob against the digest recorded in a bundle, if any.
+// The digest is computed with hashAlgorithm, or sha256 for keys that sign the message itself.
+func verifyPayloadDigest(bundleDigest string, digest []byte, hashAlgorithm crypto.Hash) error {
+	if bundleDigest == "" {
+		return nil
+	}
+	parts := strings.SplitN(bundleDigest, ":", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("parsing bundle payload digest: %q is not of the form <algorithm>:<hex>", bundleDigest)
+	}
+	want, err := cosign.HashedRekordAlgorithm(hashAlgorithm)
 	if err != nil {
-		return errors.Wrap(err, "retrieving rekor public key")
+		want = "sha256"
 	}
+	if parts[0] != want {
+		return fmt.Errorf("bundle payload digest algorithm %s does not match the %s expected for the key", parts[0], want)
+	}
+	if parts[1] != hex.EncodeToString(digest) {
+		return errors.New("blob does not match the digest in the bundle")
+	}
+	return nil
+}
 
-	rekorPubKey, err := cosign.PemToECDSAKey(pub)
+func loadCertPoolFromPEM(b64chain string) (*x509.CertPool, error) {
+	chain, err := base64.StdEncoding.DecodeString(b64chain)
 	if err != nil {
-		return errors.Wrap(err, "pem to ecdsa")
+		return nil, err
 	}
-
-	if err := cosign.VerifySET(b.Bundle.Payload, b.Bundle.SignedEntryTimestamp, rekorPubKey); err != nil {
-		return err
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(chain)
+	if err != nil {
+		return nil, err
 	}
-	if cert == nil {
-		return nil
+	pool := x509.NewCertPool()
+	for _, c := range certs {
+		pool.AddCert(c)
 	}
-	it := time.Unix(b.Bundle.Payload.IntegratedTime, 0)
-	return cosign.CheckExpiry(cert, it)
+	return pool, nil
 }
 
 func extractCerts(e *models.LogEntryAnon) ([]*x509.Certificate, error) {
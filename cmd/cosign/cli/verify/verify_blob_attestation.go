@@ -0,0 +1,218 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/pkg/errors"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/fulcio"
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/rekor"
+	"github.com/sigstore/cosign/pkg/blob"
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/cue"
+	"github.com/sigstore/cosign/pkg/cosign/pivkey"
+	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/pkg/cosign/rego"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	sigs "github.com/sigstore/cosign/pkg/signature"
+)
+
+// VerifyBlobAttestationCommand verifies an in-toto attestation, as created by
+// `cosign attest-blob`, against the supplied blob.
+// nolint
+type VerifyBlobAttestationCommand struct {
+	CheckClaims    bool
+	CertRef        string
+	CertEmail      string
+	CertOidcIssuer string
+	KeyRef         string
+	Sk             bool
+	Slot           string
+	RekorURL       string
+	SignaturePath  string
+	PredicateType  string
+	Policies       []string
+	LegacySHA256   bool
+}
+
+// Exec runs the verification command
+func (c *VerifyBlobAttestationCommand) Exec(ctx context.Context, artifactPath string) (err error) {
+	if !options.OneOf(c.KeyRef, c.Sk, c.CertRef) && !options.EnableExperimental() {
+		return &options.KeyParseError{}
+	}
+	if c.SignaturePath == "" {
+		return errors.New("--signature is required")
+	}
+	if c.CertRef != "" && c.RekorURL == "" {
+		return errors.New("--rekor-url is required to check the certificate was valid when the attestation was logged")
+	}
+
+	predicateURI, err := options.ParsePredicateType(c.PredicateType)
+	if err != nil {
+		return err
+	}
+
+	var cuePolicies, regoPolicies []string
+	for _, policy := range c.Policies {
+		switch filepath.Ext(policy) {
+		case ".rego":
+			regoPolicies = append(regoPolicies, policy)
+		case ".cue":
+			cuePolicies = append(cuePolicies, policy)
+		default:
+			return errors.New("invalid policy format, expected .cue or .rego")
+		}
+	}
+
+	envelope, err := blob.LoadFileOrURL(c.SignaturePath)
+	if err != nil {
+		return errors.Wrap(err, "loading attestation")
+	}
+
+	digest, err := payloadDigest(artifactPath, crypto.SHA256)
+	if err != nil {
+		return errors.Wrap(err, "hashing blob")
+	}
+
+	co := &cosign.CheckOpts{
+		CertEmail:      c.CertEmail,
+		CertOidcIssuer: c.CertOidcIssuer,
+		LegacySHA256:   c.LegacySHA256,
+	}
+	if c.CheckClaims {
+		co.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
+	}
+	// A certificate is only as good as the transparency log entry showing it was
+	// valid when the attestation was made, so keyless attestations are always looked up.
+	if (options.EnableExperimental() || c.CertRef != "") && c.RekorURL != "" {
+		rekorClient, err := rekor.NewClient(c.RekorURL)
+		if err != nil {
+			return errors.Wrap(err, "creating Rekor client")
+		}
+		co.RekorClient = rekorClient
+	}
+
+	var attOpts []static.Option
+	switch {
+	case c.KeyRef != "":
+		co.SigVerifier, err = sigs.PublicKeyFromKeyRef(ctx, c.KeyRef)
+		if err != nil {
+			return errors.Wrap(err, "loading public key")
+		}
+		pkcs11Key, ok := co.SigVerifier.(*pkcs11key.Key)
+		if ok {
+			defer pkcs11Key.Close()
+		}
+	case c.Sk:
+		sk, err := pivkey.GetKeyWithSlot(c.Slot)
+		if err != nil {
+			return errors.Wrap(err, "opening piv token")
+		}
+		defer sk.Close()
+		co.SigVerifier, err = sk.Verifier()
+		if err != nil {
+			return errors.Wrap(err, "initializing piv token verifier")
+		}
+	case c.CertRef != "":
+		// The envelope doesn't carry the certificate, so attach it and have
+		// it validated against the Fulcio roots like any other keyless attestation.
+		cert, err := loadCertFromFileOrURL(c.CertRef)
+		if err != nil {
+			return errors.Wrap(err, "loading certificate from reference")
+		}
+		certPEM, err := cryptoutils.MarshalCertificateToPEM(cert)
+		if err != nil {
+			return err
+		}
+		co.RootCerts = fulcio.GetRoots()
+		attOpts = append(attOpts, static.WithCertChain(certPEM, nil))
+	default:
+		return errors.New("a certificate is required to verify a keyless blob attestation")
+	}
+
+	att, err := static.NewAttestation(envelope, attOpts...)
+	if err != nil {
+		return err
+	}
+
+	if _, err := cosign.VerifyBlobAttestation(ctx, att, v1.Hash{
+		Algorithm: "sha256",
+		Hex:       hex.EncodeToString(digest),
+	}, co); err != nil {
+		return err
+	}
+
+	statement, err := decodeStatement(envelope)
+	if err != nil {
+		return err
+	}
+	var header in_toto.StatementHeader
+	if err := json.Unmarshal(statement, &header); err != nil {
+		return errors.Wrap(err, "unmarshal in-toto statement")
+	}
+	if header.PredicateType != predicateURI {
+		return fmt.Errorf("attestation predicate type %q does not match %q", header.PredicateType, predicateURI)
+	}
+
+	var validationErrors []error
+	if len(cuePolicies) > 0 {
+		fmt.Fprintf(os.Stderr, "will be validating against CUE policies: %v\n", cuePolicies)
+		if err := cue.ValidateJSON(statement, cuePolicies); err != nil {
+			validationErrors = append(validationErrors, err)
+		}
+	}
+	if len(regoPolicies) > 0 {
+		fmt.Fprintf(os.Stderr, "will be validating against Rego policies: %v\n", regoPolicies)
+		validationErrors = append(validationErrors, rego.ValidateJSON(statement, regoPolicies)...)
+	}
+	if len(validationErrors) > 0 {
+		fmt.Fprintf(os.Stderr, "There are %d number of errors occurred during the validation:\n", len(validationErrors))
+		for _, v := range validationErrors {
+			_, _ = fmt.Fprintf(os.Stderr, "- %v\n", v)
+		}
+		return fmt.Errorf("%d validation errors occurred", len(validationErrors))
+	}
+
+	fmt.Fprintln(os.Stderr, "Verified OK")
+	return nil
+}
+
+// decodeStatement returns the in-toto statement carried by a DSSE envelope.
+func decodeStatement(envelope []byte) ([]byte, error) {
+	var env dsse.Envelope
+	if err := json.Unmarshal(envelope, &env); err != nil {
+		return nil, errors.Wrap(err, "unmarshal envelope")
+	}
+	statement, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding envelope payload")
+	}
+	return statement, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/cosign/internal/pkg/cosign/payload"
+	"github.com/sigstore/cosign/pkg/cosign/attestation"
+	"github.com/sigstore/cosign/pkg/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func TestVerifyBlobAttestation(t *testing.T) {
+	ctx := context.Background()
+	td := t.TempDir()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM, err := cryptoutils.MarshalPublicKeyToPEM(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPath := writeFile(t, td, "cosign.pub", pubPEM)
+
+	blobPath := writeFile(t, td, "release.tar.gz", []byte("some release tarball"))
+	otherBlobPath := writeFile(t, td, "other.tar.gz", []byte("another release tarball"))
+	digest, err := payloadDigest(blobPath, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := attestation.GenerateStatement(attestation.GenerateOpts{
+		Predicate: strings.NewReader(`{"builder": "ci"}`),
+		Type:      "custom",
+		Digest:    hex.EncodeToString(digest),
+		Repo:      "release.tar.gz",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stBytes, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, _, err := payload.NewDSSEAttestor(types.IntotoPayloadType, sv).DSSEAttest(ctx, bytes.NewReader(stBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := att.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	attPath := writeFile(t, td, "release.att", envelope)
+
+	passPolicy := writeFile(t, td, "pass.cue", []byte(`predicateType: "cosign.sigstore.dev/attestation/v1"`))
+	failPolicy := writeFile(t, td, "fail.cue", []byte(`predicateType: "https://slsa.dev/provenance/v0.2"`))
+
+	tests := []struct {
+		description   string
+		blobPath      string
+		predicateType string
+		policies      []string
+		shouldErr     bool
+	}{{
+		description:   "matching blob",
+		blobPath:      blobPath,
+		predicateType: "custom",
+	}, {
+		description:   "matching blob and policy",
+		blobPath:      blobPath,
+		predicateType: "custom",
+		policies:      []string{passPolicy},
+	}, {
+		description:   "different blob",
+		blobPath:      otherBlobPath,
+		predicateType: "custom",
+		shouldErr:     true,
+	}, {
+		description:   "different predicate type",
+		blobPath:      blobPath,
+		predicateType: "slsaprovenance",
+		shouldErr:     true,
+	}, {
+		description:   "failing policy",
+		blobPath:      blobPath,
+		predicateType: "custom",
+		policies:      []string{failPolicy},
+		shouldErr:     true,
+	}}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			cmd := VerifyBlobAttestationCommand{
+				CheckClaims:   true,
+				KeyRef:        pubPath,
+				SignaturePath: attPath,
+				PredicateType: test.predicateType,
+				Policies:      test.policies,
+			}
+			err := cmd.Exec(ctx, test.blobPath)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf("Exec() = %v, wanted err: %t", err, test.shouldErr)
+			}
+		})
+	}
+
+	// Without the transparency log, nothing shows the certificate was valid when the attestation was made.
+	cmd := VerifyBlobAttestationCommand{
+		CheckClaims:   true,
+		CertRef:       pubPath,
+		SignaturePath: attPath,
+		PredicateType: "custom",
+	}
+	if err := cmd.Exec(ctx, blobPath); err == nil || !strings.Contains(err.Error(), "--rekor-url") {
+		t.Errorf("Exec() with --cert and no Rekor URL = %v, wanted a --rekor-url error", err)
+	}
+}
+
+func writeFile(t *testing.T, dir, name string, contents []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, contents, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
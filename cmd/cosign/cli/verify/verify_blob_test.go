@@ -15,12 +15,37 @@
 package verify
 
 import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
 	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/bundle"
+	sigs "github.com/sigstore/cosign/pkg/signature"
+	"github.com/sigstore/cosign/test"
 )
 
 func TestSignaturesRef(t *testing.T) {
@@ -93,3 +118,329 @@ func TestSignaturesBundle(t *testing.T) {
 		t.Fatalf("unexpected encoded signature, expected: %s got: %s", b64sig, gotb64Sig)
 	}
 }
+
+func TestVerifyBlobCmdWithBundle(t *testing.T) {
+	ctx := context.Background()
+	td := t.TempDir()
+
+	rootCert, rootKey, _ := test.GenerateRootCa()
+	subCert, subKey, _ := test.GenerateSubordinateCa(rootCert, rootKey)
+	leafCert, leafKey, _ := test.GenerateLeafCert("subject@mail.com", "oidc-issuer", subCert, subKey)
+	rootPEM, _ := cryptoutils.MarshalCertificateToPEM(rootCert)
+	subPEM, _ := cryptoutils.MarshalCertificateToPEM(subCert)
+	leafPEM, _ := cryptoutils.MarshalCertificateToPEM(leafCert)
+
+	rekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rekorPEM, err := cryptoutils.MarshalPublicKeyToPEM(&rekorKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Supply the trusted roots locally, so nothing is fetched from TUF.
+	t.Setenv("SIGSTORE_ROOT_FILE", writeFile(t, td, "root.pem", rootPEM))
+	t.Setenv("SIGSTORE_REKOR_PUBLIC_KEY", writeFile(t, td, "rekor.pub", rekorPEM))
+
+	blob := []byte("some release tarball")
+	blobPath := writeFile(t, td, "blob", blob)
+	otherBlobPath := writeFile(t, td, "other", []byte("another release tarball"))
+
+	sv, err := signature.LoadECDSASignerVerifier(leafKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sv.SignMessage(bytes.NewReader(blob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64sig := base64.StdEncoding.EncodeToString(sig)
+	digest := sha256.Sum256(blob)
+
+	body := fmt.Sprintf(`{"apiVersion":"0.0.1","kind":"hashedrekord","spec":{"data":{"hash":{"algorithm":"sha256","value":%q}},"signature":{"content":%q,"publicKey":{"content":%q}}}}`,
+		hex.EncodeToString(digest[:]), b64sig, base64.StdEncoding.EncodeToString(leafPEM))
+	payload := bundle.RekorPayload{
+		Body:           base64.StdEncoding.EncodeToString([]byte(body)),
+		IntegratedTime: time.Now().Unix(),
+		LogIndex:       1,
+		LogID:          "log-id",
+	}
+	contents, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonicalized, err := jsoncanonicalizer.Transform(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256(canonicalized)
+	set, err := ecdsa.SignASN1(rand.Reader, rekorKey, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lsp := cosign.LocalSignedPayload{
+		Base64Signature: b64sig,
+		Cert:            base64.StdEncoding.EncodeToString(leafPEM),
+		Chain:           base64.StdEncoding.EncodeToString(append(subPEM, rootPEM...)),
+		PayloadDigest:   "sha256:" + hex.EncodeToString(digest[:]),
+		Bundle: &bundle.RekorBundle{
+			SignedEntryTimestamp: set,
+			Payload:              payload,
+		},
+	}
+	writeBundle := func(name string, lsp cosign.LocalSignedPayload) string {
+		contents, err := json.Marshal(lsp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return writeFile(t, td, name, contents)
+	}
+	bundlePath := writeBundle("good.bundle", lsp)
+
+	noChain := lsp
+	noChain.Chain = ""
+	noChainPath := writeBundle("nochain.bundle", noChain)
+
+	badSET := lsp
+	badSET.Bundle = &bundle.RekorBundle{
+		SignedEntryTimestamp: []byte("not a signature"),
+		Payload:              payload,
+	}
+	badSETPath := writeBundle("badset.bundle", badSET)
+
+	tests := []struct {
+		description string
+		bundlePath  string
+		blobPath    string
+		certEmail   string
+		shouldErr   bool
+	}{{
+		description: "self-contained bundle",
+		bundlePath:  bundlePath,
+		blobPath:    blobPath,
+	}, {
+		description: "matching email",
+		bundlePath:  bundlePath,
+		blobPath:    blobPath,
+		certEmail:   "subject@mail.com",
+	}, {
+		description: "other email",
+		bundlePath:  bundlePath,
+		blobPath:    blobPath,
+		certEmail:   "other@mail.com",
+		shouldErr:   true,
+	}, {
+		description: "other blob",
+		bundlePath:  bundlePath,
+		blobPath:    otherBlobPath,
+		shouldErr:   true,
+	}, {
+		description: "missing intermediate",
+		bundlePath:  noChainPath,
+		blobPath:    blobPath,
+		shouldErr:   true,
+	}, {
+		description: "bad SET",
+		bundlePath:  badSETPath,
+		blobPath:    blobPath,
+		shouldErr:   true,
+	}}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			ko := sign.KeyOpts{BundlePath: test.bundlePath}
+			err := VerifyBlobCmd(ctx, ko, "", test.certEmail, "", "", test.blobPath)
+			if (err != nil) != test.shouldErr {
+				t.Fatalf("VerifyBlobCmd() = %v, wanted err: %t", err, test.shouldErr)
+			}
+		})
+	}
+}
+
+func TestSignBlobVerifyBlob(t *testing.T) {
+	ctx := context.Background()
+	passFunc := func(_ bool) ([]byte, error) {
+		return []byte("hunter2"), nil
+	}
+
+	tests := []struct {
+		keyAlgorithm  string
+		payloadDigest string
+	}{
+		{keyAlgorithm: cosign.ECDSAP256, payloadDigest: "sha256:"},
+		{keyAlgorithm: cosign.ECDSAP384, payloadDigest: "sha384:"},
+		{keyAlgorithm: cosign.ECDSAP521, payloadDigest: "sha512:"},
+		{keyAlgorithm: cosign.RSA3072, payloadDigest: "sha256:"},
+		{keyAlgorithm: cosign.ED25519, payloadDigest: "sha256:"},
+	}
+	for _, test := range tests {
+		t.Run(test.keyAlgorithm, func(t *testing.T) {
+			td := t.TempDir()
+			keys, err := cosign.GenerateKeyPairWithAlgorithm(passFunc, test.keyAlgorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			privKeyPath := writeFile(t, td, "cosign.key", keys.PrivateBytes)
+			pubKeyPath := writeFile(t, td, "cosign.pub", keys.PublicBytes)
+
+			// Large enough to span many reads of the stream.
+			blobPath := writeFile(t, td, "blob", bytes.Repeat([]byte("0123456789abcdef"), 1<<16))
+			otherBlobPath := writeFile(t, td, "other", []byte("another blob"))
+			sigPath := filepath.Join(td, "blob.sig")
+			bundlePath := filepath.Join(td, "blob.bundle")
+
+			ko := sign.KeyOpts{KeyRef: privKeyPath, PassFunc: passFunc}
+			if _, err := sign.SignBlobCmd(ctx, ko, options.RegistryOptions{}, blobPath, true, sigPath, "", 0); err != nil {
+				t.Fatal(err)
+			}
+			ko.BundlePath = bundlePath
+			if _, err := sign.SignBlobCmd(ctx, ko, options.RegistryOptions{}, blobPath, true, "", "", 0); err != nil {
+				t.Fatal(err)
+			}
+			b, err := cosign.FetchLocalSignedPayloadFromPath(bundlePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(b.PayloadDigest, test.payloadDigest) {
+				t.Errorf("bundle payload digest = %s, wanted %s prefix", b.PayloadDigest, test.payloadDigest)
+			}
+
+			verifyKO := sign.KeyOpts{KeyRef: pubKeyPath}
+			if err := VerifyBlobCmd(ctx, verifyKO, "", "", "", sigPath, blobPath); err != nil {
+				t.Errorf("VerifyBlobCmd() = %v", err)
+			}
+			if err := VerifyBlobCmd(ctx, verifyKO, "", "", "", sigPath, otherBlobPath); err == nil {
+				t.Error("VerifyBlobCmd() on another blob expected error")
+			}
+
+			verifyKO.BundlePath = bundlePath
+			if err := VerifyBlobCmd(ctx, verifyKO, "", "", "", "", blobPath); err != nil {
+				t.Errorf("VerifyBlobCmd() with bundle = %v", err)
+			}
+			if err := VerifyBlobCmd(ctx, verifyKO, "", "", "", "", otherBlobPath); err == nil {
+				t.Error("VerifyBlobCmd() with bundle on another blob expected error")
+			}
+		})
+	}
+}
+
+func TestVerifyBlobLegacySHA256(t *testing.T) {
+	ctx := context.Background()
+	td := t.TempDir()
+	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := []byte("a blob")
+	// Cosign signed SHA-256 digests with all key types before.
+	digest := sha256.Sum256(blob)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKeyPath := writeFile(t, td, "cosign.pub", pub)
+	blobPath := writeFile(t, td, "blob", blob)
+	sigPath := writeFile(t, td, "blob.sig", []byte(base64.StdEncoding.EncodeToString(sig)))
+
+	if err := VerifyBlobCmd(ctx, sign.KeyOpts{KeyRef: pubKeyPath}, "", "", "", sigPath, blobPath); err == nil {
+		t.Error("VerifyBlobCmd() of a SHA-256 signature from a P-384 key succeeded")
+	}
+	if err := VerifyBlobCmd(ctx, sign.KeyOpts{KeyRef: pubKeyPath, LegacySHA256: true}, "", "", "", sigPath, blobPath); err != nil {
+		t.Errorf("VerifyBlobCmd() with LegacySHA256 = %v", err)
+	}
+}
+
+func init() {
+	sigs.RegisterKeyRefScheme("testring://", testRingScheme{})
+}
+
+// testRingScheme loads the key rings of the PEM files named by testring:// key references.
+type testRingScheme struct{}
+
+func (testRingScheme) SignerVerifier(context.Context, string, cosign.PassFunc) (signature.SignerVerifier, error) {
+	return nil, errors.New("no private keys in key rings")
+}
+
+func (testRingScheme) Verifier(_ context.Context, keyRef string, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
+	raw, err := os.ReadFile(strings.TrimPrefix(keyRef, "testring://"))
+	if err != nil {
+		return nil, err
+	}
+	var ring testRing
+	for {
+		var block *pem.Block
+		if block, raw = pem.Decode(raw); block == nil {
+			return ring, nil
+		}
+		v, err := sigs.LoadPublicKeyRaw(pem.EncodeToMemory(block), hashAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		ring = append(ring, v)
+	}
+}
+
+// testRing accepts the signatures of any of its keys, with the public key of the first.
+type testRing []signature.Verifier
+
+func (r testRing) Verifiers() []signature.Verifier {
+	return r
+}
+
+func (r testRing) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return r[0].PublicKey(opts...)
+}
+
+func (r testRing) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	return errors.New("key rings are checked key by key")
+}
+
+func TestVerifyBlobKeyRing(t *testing.T) {
+	ctx := context.Background()
+	passFunc := func(_ bool) ([]byte, error) {
+		return []byte("hunter2"), nil
+	}
+	td := t.TempDir()
+	blobPath := writeFile(t, td, "blob", []byte("a blob"))
+
+	// A P-256 key rotated to a P-384 one, and an ED25519 key signing the blob itself.
+	algorithms := []string{cosign.ECDSAP384, cosign.ECDSAP256, cosign.ED25519}
+	var pubs [][]byte
+	var privKeyPaths []string
+	for _, algorithm := range algorithms {
+		keys, err := cosign.GenerateKeyPairWithAlgorithm(passFunc, algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, keys.PublicBytes)
+		privKeyPaths = append(privKeyPaths, writeFile(t, td, algorithm+".key", keys.PrivateBytes))
+	}
+	ringPath := "testring://" + writeFile(t, td, "ring.pub", bytes.Join(pubs, nil))
+
+	for i, algorithm := range algorithms {
+		sigPath := filepath.Join(td, algorithm+".sig")
+		bundlePath := filepath.Join(td, algorithm+".bundle")
+		ko := sign.KeyOpts{KeyRef: privKeyPaths[i], PassFunc: passFunc}
+		if _, err := sign.SignBlobCmd(ctx, ko, options.RegistryOptions{}, blobPath, true, sigPath, "", 0); err != nil {
+			t.Fatal(err)
+		}
+		ko.BundlePath = bundlePath
+		if _, err := sign.SignBlobCmd(ctx, ko, options.RegistryOptions{}, blobPath, true, "", "", 0); err != nil {
+			t.Fatal(err)
+		}
+
+		verifyKO := sign.KeyOpts{KeyRef: ringPath}
+		if err := VerifyBlobCmd(ctx, verifyKO, "", "", "", sigPath, blobPath); err != nil {
+			t.Errorf("VerifyBlobCmd(%s) = %v", algorithm, err)
+		}
+		verifyKO.BundlePath = bundlePath
+		if err := VerifyBlobCmd(ctx, verifyKO, "", "", "", "", blobPath); err != nil {
+			t.Errorf("VerifyBlobCmd(%s) with bundle = %v", algorithm, err)
+		}
+	}
+}
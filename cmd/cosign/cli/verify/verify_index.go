@@ -0,0 +1,165 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/oci"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/oci/walk"
+)
+
+// entity is a manifest whose signatures are to be verified.
+type entity struct {
+	ref name.Digest
+	se  oci.SignedEntity
+}
+
+// verifyEntities verifies the signatures of ref and, with c.Recursive, of each
+// manifest of the index it points to. With platform, only the images of that
+// platform are verified, as well as the index itself with c.Recursive. The
+// media types required by co only apply to the images, not to the index.
+func (c *VerifyCommand) verifyEntities(ctx context.Context, ref name.Reference, platform *v1.Platform, co *cosign.CheckOpts) error {
+	se, err := ociremote.SignedEntity(ref, co.RegistryClientOpts...)
+	if err != nil {
+		return err
+	}
+	entities, err := entitiesToVerify(ctx, ref.Context(), se, c.Recursive, platform)
+	if err != nil {
+		return err
+	}
+	for _, e := range entities {
+		verified, bundleVerified, err := cosign.VerifyEntitySignatures(ctx, e.se, entityCheckOpts(e.se, co))
+		if err != nil {
+			return errors.Wrapf(err, "verifying %s", e.ref)
+		}
+		PrintVerificationHeader(e.ref.Name(), co, bundleVerified)
+		PrintVerification(e.ref.Name(), verified, c.Output)
+	}
+	return nil
+}
+
+// entityCheckOpts returns the options to verify se with: co, without the
+// media types for an index, which can only be required of artifacts.
+func entityCheckOpts(se oci.SignedEntity, co *cosign.CheckOpts) *cosign.CheckOpts {
+	if _, ok := se.(oci.SignedImageIndex); !ok || (co.ConfigMediaType == "" && co.LayerMediaType == "") {
+		return co
+	}
+	ico := *co
+	ico.ConfigMediaType, ico.LayerMediaType = "", ""
+	return &ico
+}
+
+// entitiesToVerify returns the manifests of se, in repo, to verify.
+func entitiesToVerify(ctx context.Context, repo name.Repository, se oci.SignedEntity, recursive bool, platform *v1.Platform) ([]entity, error) {
+	if platform != nil {
+		return platformEntities(repo, se, recursive, platform)
+	}
+
+	var entities []entity
+	if err := walk.SignedEntity(ctx, se, func(ctx context.Context, se oci.SignedEntity) error {
+		h, err := se.(interface{ Digest() (v1.Hash, error) }).Digest()
+		if err != nil {
+			return errors.Wrap(err, "computing digest")
+		}
+		entities = append(entities, entity{ref: repo.Digest(h.String()), se: se})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func platformEntities(repo name.Repository, se oci.SignedEntity, recursive bool, platform *v1.Platform) ([]entity, error) {
+	switch e := se.(type) {
+	case oci.SignedImage:
+		// A single image is verified if it is of the expected platform. Its
+		// config doesn't tell the variant, so only the OS and architecture
+		// are compared.
+		cf, err := e.ConfigFile()
+		if err != nil {
+			return nil, errors.Wrap(err, "reading image config")
+		}
+		if cf.OS != platform.OS || cf.Architecture != platform.Architecture {
+			return nil, fmt.Errorf("image is for platform %s/%s, not %s", cf.OS, cf.Architecture, options.PlatformString(platform))
+		}
+		h, err := e.Digest()
+		if err != nil {
+			return nil, err
+		}
+		return []entity{{ref: repo.Digest(h.String()), se: e}}, nil
+
+	case oci.SignedImageIndex:
+		var entities []entity
+		if recursive {
+			h, err := e.Digest()
+			if err != nil {
+				return nil, err
+			}
+			entities = append(entities, entity{ref: repo.Digest(h.String()), se: e})
+		}
+		im, err := e.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		for _, desc := range im.Manifests {
+			if desc.Platform == nil || !platformMatches(*desc.Platform, platform) {
+				continue
+			}
+			si, err := e.SignedImage(desc.Digest)
+			if err != nil {
+				return nil, errors.Wrapf(err, "accessing image %s", desc.Digest)
+			}
+			entities = append(entities, entity{ref: repo.Digest(desc.Digest.String()), se: si})
+		}
+		if len(entities) == 0 || (recursive && len(entities) == 1) {
+			return nil, fmt.Errorf("no image for platform %s in the index", options.PlatformString(platform))
+		}
+		return entities, nil
+
+	default:
+		return nil, fmt.Errorf("unexpected signed entity %T", se)
+	}
+}
+
+// parsePlatform parses a platform given as os/arch[/variant].
+func parsePlatform(s string) (*v1.Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid platform %q, expected os/arch[/variant]", s)
+	}
+	p := &v1.Platform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// platformMatches returns whether p is the wanted platform, ignoring the
+// variant when none is wanted.
+func platformMatches(p v1.Platform, want *v1.Platform) bool {
+	return p.OS == want.OS && p.Architecture == want.Architecture &&
+		(want.Variant == "" || p.Variant == want.Variant)
+}
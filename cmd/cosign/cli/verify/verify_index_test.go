@@ -0,0 +1,173 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+
+	"github.com/sigstore/cosign/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/oci/signed"
+)
+
+func TestEntitiesToVerify(t *testing.T) {
+	ctx := context.Background()
+	repo, err := name.NewRepository("example.com/multiarch")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	platforms := []v1.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm", Variant: "v6"},
+		{OS: "linux", Architecture: "arm", Variant: "v7"},
+	}
+	var adds []mutate.IndexAddendum
+	digests := map[string]string{}
+	for i := range platforms {
+		img, err := random.Image(10, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		digests[options.PlatformString(&platforms[i])] = repo.Digest(h.String()).String()
+		adds = append(adds, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &platforms[i]},
+		})
+	}
+	ii := mutate.AppendManifests(empty.Index, adds...)
+	h, err := ii.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := repo.Digest(h.String()).String()
+	sii := signed.ImageIndex(ii)
+
+	tests := []struct {
+		name      string
+		recursive bool
+		platform  string
+		want      []string
+		wantErr   bool
+	}{{
+		name:      "recursive",
+		recursive: true,
+		want:      []string{index, digests["linux/amd64"], digests["linux/arm/v6"], digests["linux/arm/v7"]},
+	}, {
+		name:     "platform",
+		platform: "linux/amd64",
+		want:     []string{digests["linux/amd64"]},
+	}, {
+		name:      "recursive platform",
+		recursive: true,
+		platform:  "linux/arm/v7",
+		want:      []string{index, digests["linux/arm/v7"]},
+	}, {
+		name:     "platform without variant",
+		platform: "linux/arm",
+		want:     []string{digests["linux/arm/v6"], digests["linux/arm/v7"]},
+	}, {
+		name:     "missing platform",
+		platform: "windows/amd64",
+		wantErr:  true,
+	}, {
+		name:      "recursive missing platform",
+		recursive: true,
+		platform:  "linux/s390x",
+		wantErr:   true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var platform *v1.Platform
+			if test.platform != "" {
+				if platform, err = parsePlatform(test.platform); err != nil {
+					t.Fatal(err)
+				}
+			}
+			entities, err := entitiesToVerify(ctx, repo, sii, test.recursive, platform)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("entitiesToVerify() = nil, wanted error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("entitiesToVerify() = %v", err)
+			}
+			var got []string
+			for _, e := range entities {
+				got = append(got, e.ref.String())
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("entitiesToVerify() (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	for _, s := range []string{"linux/amd64", "linux/arm/v7"} {
+		p, err := parsePlatform(s)
+		if err != nil {
+			t.Errorf("parsePlatform(%q) = %v", s, err)
+		} else if got := options.PlatformString(p); got != s {
+			t.Errorf("parsePlatform(%q) = %s", s, got)
+		}
+	}
+	for _, s := range []string{"", "linux", "/amd64", "linux/arm/v7/extra"} {
+		if _, err := parsePlatform(s); err == nil {
+			t.Errorf("parsePlatform(%q) = nil, wanted error", s)
+		}
+	}
+}
+
+func TestEntityCheckOpts(t *testing.T) {
+	ii, err := random.Index(10, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	co := &cosign.CheckOpts{LayerMediaType: "application/vnd.wasm.content.layer.v1+wasm", CertEmail: "foo@example.com"}
+
+	if got := entityCheckOpts(signed.Image(img), co); got != co {
+		t.Errorf("entityCheckOpts(image) = %+v, wanted the options unchanged", got)
+	}
+	got := entityCheckOpts(signed.ImageIndex(ii), co)
+	if got.LayerMediaType != "" || got.ConfigMediaType != "" {
+		t.Errorf("entityCheckOpts(index) kept media types %s, %s", got.ConfigMediaType, got.LayerMediaType)
+	}
+	if got.CertEmail != co.CertEmail {
+		t.Errorf("entityCheckOpts(index) CertEmail = %s, wanted %s", got.CertEmail, co.CertEmail)
+	}
+	if co.LayerMediaType == "" {
+		t.Error("entityCheckOpts(index) modified the original options")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
+	providers "github.com/sigstore/cosign/pkg/providers/all"
 	"github.com/sigstore/cosign/pkg/version"
 )
 
@@ -34,6 +35,7 @@ func Version() *cobra.Command {
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			v := version.GetVersionInfo()
+			v.OIDCProviders = providers.Detected(cmd.Context())
 			res := v.String()
 			if outputJSON {
 				j, err := v.JSONString()
@@ -61,6 +61,7 @@ func main() {
 	v := version.GetVersionInfo()
 	vJSON, _ := v.JSONString()
 	log.Printf("%v", vJSON)
+	cwebhook.RegisterMetrics()
 	// This calls flag.Parse()
 	sharedmain.MainWithContext(ctx, "cosigned",
 		certificates.NewController,
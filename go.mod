@@ -31,6 +31,7 @@ require (
 	github.com/stretchr/testify v1.7.0
 	github.com/theupdateframework/go-tuf v0.0.0-20220113233521-eac0a85ce281
 	github.com/xanzy/go-gitlab v0.54.3
+	go.opencensus.io v0.23.0
 	golang.org/x/oauth2 v0.0.0-20211104180415-d3ed0bb246c8
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
 	google.golang.org/api v0.65.0
@@ -39,6 +40,7 @@ require (
 	k8s.io/client-go v0.22.5
 	k8s.io/utils v0.0.0-20211208161948-7d6a63dca704
 	knative.dev/pkg v0.0.0-20220114141842-0a429cba1c73
+	sigs.k8s.io/yaml v1.3.0
 )
 
 require (
@@ -56,7 +56,7 @@ func (pa *payloadAttestor) DSSEAttest(ctx context.Context, payload io.Reader) (o
 
 	envelope := dsse.Envelope{
 		PayloadType: pa.payloadType,
-		Payload:     base64.StdEncoding.EncodeToString(pb),
+		Payload:     base64.StdEncoding.EncodeToString(p),
 		Signatures: []dsse.Signature{{
 			Sig: base64.StdEncoding.EncodeToString(sig),
 		}},
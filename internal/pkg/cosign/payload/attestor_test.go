@@ -75,12 +75,17 @@ func TestDSSEAttestor(t *testing.T) {
 		t.Fatalf("base64.StdEncoding.DecodeString(envelope.Payload) failed: %v", err)
 	}
 
+	if string(gotPayload) != testPayload {
+		t.Errorf("got payload %q, wanted %q", gotPayload, testPayload)
+	}
+
 	gotSig, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
 	if err != nil {
 		t.Fatalf("base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig) failed: %v", err)
 	}
 
-	if err = verifier.VerifySignature(bytes.NewReader(gotSig), bytes.NewReader(gotPayload)); err != nil {
+	// The signature is computed over the DSSE pre-authentication encoding of the payload.
+	if err = verifier.VerifySignature(bytes.NewReader(gotSig), bytes.NewReader(dsse.PAE(testPayloadType, gotPayload))); err != nil {
 		t.Errorf("VerifySignature() returned error: %v", err)
 	}
 }
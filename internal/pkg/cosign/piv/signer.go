@@ -0,0 +1,64 @@
+//
+// Copyright 2022 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package piv
+
+import (
+	"context"
+	"crypto"
+	"io"
+
+	"github.com/sigstore/cosign/internal/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+// signerWrapper adds the PIV attestation of the signing key to the returned `oci.Signature`
+type signerWrapper struct {
+	inner cosign.Signer
+
+	attestation []byte
+}
+
+var _ cosign.Signer = (*signerWrapper)(nil)
+
+// Sign implements `cosign.Signer`
+func (ps *signerWrapper) Sign(ctx context.Context, payload io.Reader) (oci.Signature, crypto.PublicKey, error) {
+	sig, pub, err := ps.inner.Sign(ctx, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	annotations, err := sig.Annotations()
+	if err != nil {
+		return nil, nil, err
+	}
+	annotations[static.PIVAttestationAnnotationKey] = string(ps.attestation)
+	newSig, err := mutate.Signature(sig, mutate.WithAnnotations(annotations))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newSig, pub, nil
+}
+
+// NewSigner returns a `cosign.Signer` which attaches the PEM attestation chain of the PIV slot holding the signing key to the signature
+func NewSigner(inner cosign.Signer, attestation []byte) cosign.Signer {
+	return &signerWrapper{
+		inner:       inner,
+		attestation: attestation,
+	}
+}
@@ -15,6 +15,11 @@
 package blob
 
 import (
+	"crypto"
+	_ "crypto/sha256" // for `crypto.SHA256`
+	_ "crypto/sha512" // for `crypto.SHA512`
+	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"os"
@@ -44,3 +49,52 @@ func LoadFileOrURL(fileRef string) ([]byte, error) {
 	}
 	return raw, nil
 }
+
+// OpenFileOrURL is like LoadFileOrURL, but returns the contents as a stream
+// so that large blobs need not be held in memory. The caller must close it.
+func OpenFileOrURL(fileRef string) (io.ReadCloser, error) {
+	if strings.HasPrefix(fileRef, "http://") || strings.HasPrefix(fileRef, "https://") {
+		// #nosec G107
+		resp, err := http.Get(fileRef)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("fetching %s: %s", fileRef, resp.Status)
+		}
+		return resp.Body, nil
+	}
+	return os.Open(filepath.Clean(fileRef))
+}
+
+// Digest hashes the contents of r with h as they are read, so memory use
+// does not depend on the size of the blob.
+func Digest(r io.Reader, h crypto.Hash) ([]byte, error) {
+	digests, err := Digests(r, h)
+	if err != nil {
+		return nil, err
+	}
+	return digests[0], nil
+}
+
+// Digests is like Digest, but hashes the contents of r with each of hs at once.
+func Digests(r io.Reader, hs ...crypto.Hash) ([][]byte, error) {
+	hashers := make([]hash.Hash, len(hs))
+	writers := make([]io.Writer, len(hs))
+	for i, h := range hs {
+		if !h.Available() {
+			return nil, fmt.Errorf("unsupported hash algorithm: %s", h)
+		}
+		hashers[i] = h.New()
+		writers[i] = hashers[i]
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, err
+	}
+	digests := make([][]byte, len(hs))
+	for i, hasher := range hashers {
+		digests[i] = hasher.Sum(nil)
+	}
+	return digests, nil
+}
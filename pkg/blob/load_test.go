@@ -0,0 +1,131 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blob
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenFileOrURL(t *testing.T) {
+	data := []byte("some release tarball")
+
+	path := filepath.Join(t.TempDir(), "blob")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/blob" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		description string
+		fileRef     string
+		shouldErr   bool
+	}{{
+		description: "file",
+		fileRef:     path,
+	}, {
+		description: "url",
+		fileRef:     server.URL + "/blob",
+	}, {
+		description: "missing file",
+		fileRef:     path + ".missing",
+		shouldErr:   true,
+	}, {
+		description: "missing url",
+		fileRef:     server.URL + "/missing",
+		shouldErr:   true,
+	}}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			r, err := OpenFileOrURL(test.fileRef)
+			if test.shouldErr {
+				if err == nil {
+					r.Close()
+					t.Fatal("OpenFileOrURL() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OpenFileOrURL() = %v", err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("OpenFileOrURL() read %q, wanted %q", got, data)
+			}
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	data := []byte("some release tarball")
+	sum256 := sha256.Sum256(data)
+	sum512 := sha512.Sum512(data)
+
+	tests := []struct {
+		hash crypto.Hash
+		want []byte
+	}{
+		{crypto.SHA256, sum256[:]},
+		{crypto.SHA512, sum512[:]},
+	}
+	for _, test := range tests {
+		t.Run(test.hash.String(), func(t *testing.T) {
+			got, err := Digest(bytes.NewReader(data), test.hash)
+			if err != nil {
+				t.Fatalf("Digest() = %v", err)
+			}
+			if !bytes.Equal(got, test.want) {
+				t.Errorf("Digest() = %x, wanted %x", got, test.want)
+			}
+		})
+	}
+
+	if _, err := Digest(bytes.NewReader(data), crypto.MD4); err == nil {
+		t.Error("Digest() with an unavailable hash expected error")
+	}
+}
+
+func TestDigests(t *testing.T) {
+	data := []byte("some release tarball")
+	sum256 := sha256.Sum256(data)
+	sum512 := sha512.Sum512(data)
+
+	got, err := Digests(bytes.NewReader(data), crypto.SHA512, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Digests() = %v", err)
+	}
+	if len(got) != 2 || !bytes.Equal(got[0], sum512[:]) || !bytes.Equal(got[1], sum256[:]) {
+		t.Errorf("Digests() = %x, wanted [%x %x]", got, sum512, sum256)
+	}
+}
@@ -0,0 +1,76 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AnnotationMatcher requires signature payloads to have the annotation Key,
+// with a value matching Pattern in full.
+type AnnotationMatcher struct {
+	Key     string
+	Pattern *regexp.Regexp
+}
+
+// GlobAnnotationMatcher returns an AnnotationMatcher for a glob pattern,
+// where * matches any sequence of characters, including slashes, and ?
+// matches any single character.
+func GlobAnnotationMatcher(key, glob string) (AnnotationMatcher, error) {
+	var re strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return RegexpAnnotationMatcher(key, re.String())
+}
+
+// RegexpAnnotationMatcher returns an AnnotationMatcher for a regular
+// expression, which must match the whole value.
+func RegexpAnnotationMatcher(key, expr string) (AnnotationMatcher, error) {
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return AnnotationMatcher{}, errors.Wrapf(err, "invalid pattern for annotation %s", key)
+	}
+	return AnnotationMatcher{Key: key, Pattern: re}, nil
+}
+
+// Match checks that annotations, the optional section of a payload, has a
+// value matching m. Values that aren't strings are matched in their %v form.
+func (m AnnotationMatcher) Match(annotations map[string]interface{}) error {
+	v, ok := annotations[m.Key]
+	if !ok {
+		return fmt.Errorf("missing annotation %s", m.Key)
+	}
+	s, ok := v.(string)
+	if !ok {
+		s = fmt.Sprint(v)
+	}
+	if !m.Pattern.MatchString(s) {
+		return fmt.Errorf("annotation %s=%s does not match %s", m.Key, s, m.Pattern)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"testing"
+)
+
+func TestAnnotationMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher func() (AnnotationMatcher, error)
+		value   interface{}
+		want    bool
+	}{
+		{"glob", func() (AnnotationMatcher, error) { return GlobAnnotationMatcher("k", "v1.*") }, "v1.2.3", true},
+		{"glob is anchored", func() (AnnotationMatcher, error) { return GlobAnnotationMatcher("k", "v1.*") }, "xv1.2", false},
+		{"glob dots are literal", func() (AnnotationMatcher, error) { return GlobAnnotationMatcher("k", "v1.?") }, "v102", false},
+		{"glob question mark", func() (AnnotationMatcher, error) { return GlobAnnotationMatcher("k", "v1.?") }, "v1.2", true},
+		{"regexp", func() (AnnotationMatcher, error) { return RegexpAnnotationMatcher("k", "[0-9a-f]{6}|main") }, "abc123", true},
+		{"regexp alternatives are anchored", func() (AnnotationMatcher, error) { return RegexpAnnotationMatcher("k", "[0-9a-f]{6}|main") }, "abc123x", false},
+		{"non string value", func() (AnnotationMatcher, error) { return RegexpAnnotationMatcher("k", "[0-9]+") }, float64(42), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tt.matcher()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = m.Match(map[string]interface{}{"k": tt.value})
+			if got := err == nil; got != tt.want {
+				t.Errorf("Match(%v) = %v, want match %t", tt.value, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := RegexpAnnotationMatcher("k", "("); err == nil {
+		t.Error("RegexpAnnotationMatcher() with an invalid expression succeeded")
+	}
+}
@@ -0,0 +1,149 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	ctypes "github.com/sigstore/cosign/pkg/types"
+)
+
+// ArtifactType is a kind of OCI artifact other than a container image, told
+// apart by the media type of its config.
+type ArtifactType struct {
+	// Name is the short name of the artifact type, e.g. "wasm".
+	Name string
+	// Description is the human readable name of the artifact type.
+	Description     string
+	ConfigMediaType types.MediaType
+	// LayerMediaType is the media type of the layer holding the artifact
+	// content, which may be accompanied by other layers.
+	LayerMediaType types.MediaType
+}
+
+// ArtifactTypes are the artifact types that cosign uploads and recognizes.
+var ArtifactTypes = []ArtifactType{{
+	Name:            "wasm",
+	Description:     "WebAssembly module",
+	ConfigMediaType: ctypes.WasmConfigMediaType,
+	LayerMediaType:  ctypes.WasmLayerMediaType,
+}, {
+	Name:            "helm",
+	Description:     "Helm chart",
+	ConfigMediaType: ctypes.HelmChartConfigMediaType,
+	LayerMediaType:  ctypes.HelmChartContentLayerMediaType,
+}, {
+	Name:            "bundle",
+	Description:     "Open Policy Agent bundle",
+	ConfigMediaType: ctypes.OPABundleConfigMediaType,
+	LayerMediaType:  ctypes.OPABundleLayerMediaType,
+}}
+
+// LookupArtifactType returns the artifact type with the given short name.
+func LookupArtifactType(name string) (ArtifactType, bool) {
+	for _, at := range ArtifactTypes {
+		if at.Name == name {
+			return at, true
+		}
+	}
+	return ArtifactType{}, false
+}
+
+// ArtifactTypeForConfig returns the artifact type whose config has the given
+// media type.
+func ArtifactTypeForConfig(mt types.MediaType) (ArtifactType, bool) {
+	for _, at := range ArtifactTypes {
+		if at.ConfigMediaType == mt {
+			return at, true
+		}
+	}
+	return ArtifactType{}, false
+}
+
+// ArtifactTypeNames returns the short names of ArtifactTypes, for flag help.
+func ArtifactTypeNames() string {
+	names := make([]string, 0, len(ArtifactTypes))
+	for _, at := range ArtifactTypes {
+		names = append(names, at.Name)
+	}
+	return strings.Join(names, "|")
+}
+
+// ArtifactLayer returns the layer of img holding the content of the artifact:
+// its only layer or, for a known artifact type with several layers such as a
+// Helm chart with its provenance file, the layer of the content media type.
+func ArtifactLayer(img v1.Image) (v1.Layer, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Layers) == 1 {
+		return img.LayerByDigest(m.Layers[0].Digest)
+	}
+	at, ok := ArtifactTypeForConfig(m.Config.MediaType)
+	if !ok {
+		return nil, fmt.Errorf("expected exactly one layer in an artifact of config media type %s, got %d", m.Config.MediaType, len(m.Layers))
+	}
+	var found *v1.Descriptor
+	for i, desc := range m.Layers {
+		if desc.MediaType != at.LayerMediaType {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("found several layers of media type %s in %s", at.LayerMediaType, at.Description)
+		}
+		found = &m.Layers[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no layer of media type %s in %s", at.LayerMediaType, at.Description)
+	}
+	return img.LayerByDigest(found.Digest)
+}
+
+// checkMediaTypes checks that se is an artifact with the config and layer
+// media types required by co. As the signed digest covers the manifest, this
+// ties the signature to the media types of the artifact content.
+func checkMediaTypes(se oci.SignedEntity, co *CheckOpts) error {
+	if co.ConfigMediaType == "" && co.LayerMediaType == "" {
+		return nil
+	}
+	img, ok := se.(oci.SignedImage)
+	if !ok {
+		return errors.New("expected an artifact, got an image index")
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+	if co.ConfigMediaType != "" && m.Config.MediaType != co.ConfigMediaType {
+		return fmt.Errorf("artifact config has media type %s, expected %s", m.Config.MediaType, co.ConfigMediaType)
+	}
+	if co.LayerMediaType != "" {
+		for _, desc := range m.Layers {
+			if desc.MediaType == co.LayerMediaType {
+				return nil
+			}
+		}
+		return fmt.Errorf("artifact has no layer of media type %s", co.LayerMediaType)
+	}
+	return nil
+}
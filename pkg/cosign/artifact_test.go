@@ -0,0 +1,146 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	ggcrstatic "github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/sigstore/cosign/pkg/oci/signed"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	ctypes "github.com/sigstore/cosign/pkg/types"
+)
+
+func TestArtifactLayer(t *testing.T) {
+	wasm, err := static.NewFile([]byte("wasm"), static.WithLayerMediaType(ctypes.WasmLayerMediaType), static.WithConfigMediaType(ctypes.WasmConfigMediaType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chart, err := static.NewArtifact([]byte(`{"name":"mychart"}`), ctypes.HelmChartConfigMediaType,
+		ggcrstatic.NewLayer([]byte("provenance"), ctypes.HelmChartProvenanceLayerMediaType),
+		ggcrstatic.NewLayer([]byte("chart"), ctypes.HelmChartContentLayerMediaType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noContent, err := static.NewArtifact([]byte(`{}`), ctypes.HelmChartConfigMediaType,
+		ggcrstatic.NewLayer([]byte("a"), ctypes.HelmChartProvenanceLayerMediaType),
+		ggcrstatic.NewLayer([]byte("b"), ctypes.HelmChartProvenanceLayerMediaType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	multi, err := random.Image(10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		img     v1.Image
+		want    string
+		wantErr bool
+	}{{
+		name: "single layer",
+		img:  wasm,
+		want: "wasm",
+	}, {
+		name: "helm chart with provenance",
+		img:  chart,
+		want: "chart",
+	}, {
+		name:    "helm chart without content",
+		img:     noContent,
+		wantErr: true,
+	}, {
+		name:    "multi layer image",
+		img:     multi,
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l, err := ArtifactLayer(test.img)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("ArtifactLayer() = nil, wanted error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ArtifactLayer() = %v", err)
+			}
+			rc, err := l.Compressed()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rc.Close()
+			got, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("ArtifactLayer() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckMediaTypes(t *testing.T) {
+	wasm, err := static.NewFile([]byte("wasm"), static.WithLayerMediaType(ctypes.WasmLayerMediaType), static.WithConfigMediaType(ctypes.WasmConfigMediaType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ii, err := random.Index(10, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wasmType, _ := LookupArtifactType("wasm")
+	helmType, _ := LookupArtifactType("helm")
+
+	if err := checkMediaTypes(wasm, &CheckOpts{}); err != nil {
+		t.Errorf("checkMediaTypes() = %v, wanted nil without media types", err)
+	}
+	if err := checkMediaTypes(wasm, &CheckOpts{ConfigMediaType: wasmType.ConfigMediaType, LayerMediaType: wasmType.LayerMediaType}); err != nil {
+		t.Errorf("checkMediaTypes(wasm) = %v", err)
+	}
+	if err := checkMediaTypes(wasm, &CheckOpts{LayerMediaType: types.MediaType(ctypes.WasmLayerMediaType)}); err != nil {
+		t.Errorf("checkMediaTypes(wasm layer) = %v", err)
+	}
+	if err := checkMediaTypes(wasm, &CheckOpts{ConfigMediaType: helmType.ConfigMediaType, LayerMediaType: helmType.LayerMediaType}); err == nil {
+		t.Error("checkMediaTypes(helm) = nil, wanted error for a wasm module")
+	}
+	if err := checkMediaTypes(wasm, &CheckOpts{LayerMediaType: ctypes.SPDXMediaType}); err == nil {
+		t.Error("checkMediaTypes(spdx) = nil, wanted error for a wasm module")
+	}
+	if err := checkMediaTypes(signed.ImageIndex(ii), &CheckOpts{LayerMediaType: wasmType.LayerMediaType}); err == nil {
+		t.Error("checkMediaTypes() = nil, wanted error for an index")
+	}
+}
+
+func TestArtifactTypeForConfig(t *testing.T) {
+	for _, at := range ArtifactTypes {
+		got, ok := ArtifactTypeForConfig(at.ConfigMediaType)
+		if !ok || got.Name != at.Name {
+			t.Errorf("ArtifactTypeForConfig(%s) = %v, %v, wanted %s", at.ConfigMediaType, got, ok, at.Name)
+		}
+	}
+	if _, ok := ArtifactTypeForConfig(types.DockerConfigJSON); ok {
+		t.Error("ArtifactTypeForConfig() recognized a container image config")
+	}
+}
@@ -0,0 +1,51 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NoMatchingSignaturesError is returned when none of the signatures of an
+// image verify, with the reason each of them didn't.
+type NoMatchingSignaturesError struct {
+	Errs []error
+}
+
+func (e *NoMatchingSignaturesError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("no matching signatures:\n%s", strings.Join(msgs, "\n "))
+}
+
+// TransparencyLogError is returned when a signature can't be verified
+// against the transparency log because the log or its public keys can't be
+// reached. A bundle or log entry that doesn't verify makes the signature
+// invalid instead.
+type TransparencyLogError struct {
+	Err error
+}
+
+func (e *TransparencyLogError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransparencyLogError) Unwrap() error {
+	return e.Err
+}
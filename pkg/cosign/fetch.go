@@ -39,11 +39,18 @@ type SignedPayload struct {
 	Bundle          *bundle.RekorBundle
 }
 
+// LocalSignedPayload is the bundle written by `cosign sign-blob --bundle`. It carries
+// everything needed to verify the blob, given the trusted roots.
 type LocalSignedPayload struct {
-	Base64Signature string              `json:"base64Signature"`
-	Cert            string              `json:"cert,omitempty"`
-	Bundle          *bundle.RekorBundle `json:"rekorBundle,omitempty"`
-	Timestamp       *tuf.Timestamp      `json:"timestamp,omitempty"`
+	Base64Signature string `json:"base64Signature"`
+	// Cert is the base64 encoded PEM certificate or public key of the signer.
+	Cert string `json:"cert,omitempty"`
+	// Chain is the base64 encoded PEM chain of Cert, if it was issued by Fulcio.
+	Chain string `json:"chain,omitempty"`
+	// PayloadDigest is the digest of the signed blob, e.g. "sha256:<hex>".
+	PayloadDigest string              `json:"payloadDigest,omitempty"`
+	Bundle        *bundle.RekorBundle `json:"rekorBundle,omitempty"`
+	Timestamp     *tuf.Timestamp      `json:"timestamp,omitempty"`
 }
 
 type Signatures struct {
@@ -17,6 +17,7 @@ package git
 
 import (
 	"context"
+	"time"
 
 	"github.com/sigstore/cosign/pkg/cosign"
 	"github.com/sigstore/cosign/pkg/cosign/git/github"
@@ -30,7 +31,12 @@ var providerMap = map[string]Git{
 
 type Git interface {
 	PutSecret(ctx context.Context, ref string, pf cosign.PassFunc) error
+	// RotateSecret is like PutSecret, but keeps accepting the public key of
+	// the key pair it replaces in GetPublicKey until retiredFor elapsed.
+	RotateSecret(ctx context.Context, ref string, pf cosign.PassFunc, retiredFor time.Duration) error
 	GetSecret(ctx context.Context, ref string, key string) (string, error)
+	// GetPublicKey returns the public key PutSecret distributed, as PEM.
+	GetPublicKey(ctx context.Context, ref string) ([]byte, error)
 }
 
 func GetProvider(provider string) Git {
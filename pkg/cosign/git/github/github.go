@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v42/github"
 	"github.com/pkg/errors"
@@ -33,8 +34,20 @@ import (
 
 const (
 	ReferenceScheme = "github"
+
+	publicKeyVariable = "COSIGN_PUBLIC_KEY"
+	publicKeyFile     = "cosign.pub"
 )
 
+// publicKeyRingNames names the public key variables of a repository:
+// COSIGN_PUBLIC_KEY, and the rotated ones COSIGN_PUBLIC_KEY_1,
+// COSIGN_PUBLIC_KEY_1_EXPIRES, etc.
+var publicKeyRingNames = cosign.PublicKeyRingNames{
+	Current:   publicKeyVariable,
+	Separator: "_",
+	Expires:   "EXPIRES",
+}
+
 type Gh struct{}
 
 func New() *Gh {
@@ -42,27 +55,48 @@ func New() *Gh {
 }
 
 func (g *Gh) PutSecret(ctx context.Context, ref string, pf cosign.PassFunc) error {
+	return putKeyPair(ctx, ref, pf, false, 0)
+}
+
+// RotateSecret is like PutSecret, but keeps the public key of the key pair it
+// replaces as the COSIGN_PUBLIC_KEY_1 repository variable, accepted by
+// GetPublicKey until retiredFor elapsed.
+func (g *Gh) RotateSecret(ctx context.Context, ref string, pf cosign.PassFunc, retiredFor time.Duration) error {
+	return putKeyPair(ctx, ref, pf, true, retiredFor)
+}
+
+func putKeyPair(ctx context.Context, ref string, pf cosign.PassFunc, rotate bool, retiredFor time.Duration) error {
 	keys, err := cosign.GenerateKeyPair(pf)
 	if err != nil {
 		return errors.Wrap(err, "generating key pair")
 	}
 
-	var httpClient *http.Client
-	if token, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: token},
-		)
-		httpClient = oauth2.NewClient(ctx, ts)
-	} else {
+	if _, ok := os.LookupEnv("GITHUB_TOKEN"); !ok {
 		return errors.New("could not find \"GITHUB_TOKEN\" env variable")
 	}
-	client := github.NewClient(httpClient)
+	client, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
 
-	split := strings.Split(ref, "/")
-	if len(split) < 2 {
-		return errors.New("could not parse scheme, use github://<owner>/<repo> format")
+	owner, repo, _, err := parseRef(ref)
+	if err != nil {
+		return err
+	}
+
+	ring := &cosign.PublicKeyRing{Current: keys.PublicBytes}
+	var prev *cosign.PublicKeyRing
+	if rotate {
+		if prev, err = readPublicKeyRing(ctx, client, owner, repo); err != nil {
+			return err
+		}
+		if len(prev.Current) == 0 {
+			return errors.Errorf("%s has no %s repository variable, there is no key pair to rotate", ref, publicKeyVariable)
+		}
+		now := time.Now()
+		*ring = *prev
+		ring.Rotate(keys.PublicBytes, now, now.Add(retiredFor))
 	}
-	owner, repo := split[0], split[1]
 
 	key, getRepoPubKeyResp, err := client.Actions.GetRepoPublicKey(ctx, owner, repo)
 	if err != nil {
@@ -128,6 +162,20 @@ func (g *Gh) PutSecret(ctx context.Context, ref string, pf cosign.PassFunc) erro
 
 	fmt.Fprintln(os.Stderr, "Public key written to COSIGN_PUBLIC_KEY environment variable")
 
+	// Secrets can't be read back, so also make the public key available to verifiers.
+	if err := ring.Write(prev, publicKeyRingNames, func(name string, value []byte) error {
+		return errors.Wrapf(putVariable(ctx, client, owner, repo, name, string(value)), "could not create %q repository variable", name)
+	}, func(name string) error {
+		return errors.Wrapf(deleteVariable(ctx, client, owner, repo, name), "could not delete %q repository variable", name)
+	}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Public key written to %s repository variable\n", publicKeyVariable)
+	if len(ring.Retired) > 0 {
+		fmt.Fprintf(os.Stderr, "Previous public key kept as %s_1 repository variable until %s\n", publicKeyVariable, ring.Retired[0].Expires.Format(time.RFC3339))
+	}
+
 	if err := os.WriteFile("cosign.pub", keys.PublicBytes, 0o600); err != nil {
 		return err
 	}
@@ -136,7 +184,150 @@ func (g *Gh) PutSecret(ctx context.Context, ref string, pf cosign.PassFunc) erro
 	return nil
 }
 
-// NOTE: GetSecret is not implemented for GitHub
+// GetSecret returns the secret key of the repository ref refers to.
+// GitHub doesn't let secrets be read through its API, so this only works in
+// workflows exposing the secret as the environment variable of the same name.
 func (g *Gh) GetSecret(ctx context.Context, ref string, key string) (string, error) {
-	return "", nil
+	if _, _, _, err := parseRef(ref); err != nil {
+		return "", err
+	}
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", errors.Errorf("GitHub secrets can't be read through its API: expose the %s secret of %s to the workflow as the %s environment variable", key, ref, key)
+	}
+	return value, nil
+}
+
+// GetPublicKey returns the public key of the repository ref refers to, as
+// <owner>/<repo>[@<git ref>]: the COSIGN_PUBLIC_KEY repository variable or,
+// if there is none, the cosign.pub file of its default branch. The file is
+// always read from the git ref, if there is one.
+func (g *Gh) GetPublicKey(ctx context.Context, ref string) ([]byte, error) {
+	owner, repo, gitRef, err := parseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if gitRef == "" {
+		ring, err := readPublicKeyRing(ctx, client, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		if len(ring.Current) > 0 {
+			// Accept the keys it was rotated from until they expire.
+			return ring.Valid(time.Now()), nil
+		}
+	}
+
+	file, _, _, err := client.Repositories.GetContents(ctx, owner, repo, publicKeyFile, &github.RepositoryContentGetOptions{Ref: gitRef})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not retrieve a %q repository variable nor a %s file in %s", publicKeyVariable, publicKeyFile, ref)
+	}
+	if file == nil {
+		return nil, errors.Errorf("%s of %s is not a file", publicKeyFile, ref)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, errors.Wrapf(err, "decoding %s", publicKeyFile)
+	}
+	return []byte(content), nil
+}
+
+// parseRef splits <owner>/<repo>[@<git ref>].
+func parseRef(ref string) (owner, repo, gitRef string, err error) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		ref, gitRef = ref[:i], ref[i+1:]
+	}
+	split := strings.Split(ref, "/")
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return "", "", "", errors.New("could not parse scheme, use github://<owner>/<repo> format")
+	}
+	return split[0], split[1], gitRef, nil
+}
+
+// newClient returns a client of the GitHub API, or of the GitHub Enterprise
+// server GITHUB_HOST names, authenticated with GITHUB_TOKEN if it is set.
+func newClient(ctx context.Context) (*github.Client, error) {
+	var httpClient *http.Client
+	if token, ok := os.LookupEnv("GITHUB_TOKEN"); ok {
+		ts := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: token},
+		)
+		httpClient = oauth2.NewClient(ctx, ts)
+	}
+	if host, ok := os.LookupEnv("GITHUB_HOST"); ok {
+		client, err := github.NewEnterpriseClient(host, host, httpClient)
+		return client, errors.Wrap(err, "could not create GitHub client")
+	}
+	return github.NewClient(httpClient), nil
+}
+
+// variable is a GitHub Actions repository variable, which go-github lacks.
+type variable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func getVariable(ctx context.Context, client *github.Client, owner, repo, name string) (string, error) {
+	req, err := client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/actions/variables/%s", owner, repo, name), nil)
+	if err != nil {
+		return "", err
+	}
+	var v variable
+	if _, err := client.Do(ctx, req, &v); err != nil {
+		return "", err
+	}
+	return v.Value, nil
+}
+
+// readPublicKeyRing reads the public key ring of a repository, which is
+// empty if it has no COSIGN_PUBLIC_KEY variable, or if the token isn't
+// allowed to read its variables, as is the case for mere readers.
+func readPublicKeyRing(ctx context.Context, client *github.Client, owner, repo string) (*cosign.PublicKeyRing, error) {
+	return cosign.ReadPublicKeyRing(func(name string) ([]byte, error) {
+		value, err := getVariable(ctx, client, owner, repo, name)
+		var ghErr *github.ErrorResponse
+		if errors.As(err, &ghErr) {
+			switch ghErr.Response.StatusCode {
+			case http.StatusNotFound, http.StatusUnauthorized, http.StatusForbidden:
+				return nil, nil
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve %q repository variable", name)
+		}
+		return []byte(value), nil
+	}, publicKeyRingNames)
+}
+
+func putVariable(ctx context.Context, client *github.Client, owner, repo, name, value string) error {
+	req, err := client.NewRequest(http.MethodPost, fmt.Sprintf("repos/%s/%s/actions/variables", owner, repo), &variable{Name: name, Value: value})
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(ctx, req, nil)
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response.StatusCode != http.StatusConflict {
+		return err
+	}
+	// The variable already exists.
+	req, err = client.NewRequest(http.MethodPatch, fmt.Sprintf("repos/%s/%s/actions/variables/%s", owner, repo, name), &variable{Name: name, Value: value})
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(ctx, req, nil)
+	return err
+}
+
+func deleteVariable(ctx context.Context, client *github.Client, owner, repo, name string) error {
+	req, err := client.NewRequest(http.MethodDelete, fmt.Sprintf("repos/%s/%s/actions/variables/%s", owner, repo, name), nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(ctx, req, nil)
+	return err
 }
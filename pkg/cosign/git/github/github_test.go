@@ -0,0 +1,141 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+const (
+	variableKey = "variable key"
+	fileKey     = "file key"
+)
+
+// apiServer serves the COSIGN_PUBLIC_KEY variable of owner/with-variable, and
+// the cosign.pub file of every owner/with-* repository at the git ref "v1"
+// and the default branch. The variables of owner/with-file-unauthorized,
+// owner/with-file-forbidden and owner/with-file-broken fail with 401, 403
+// and 500.
+func apiServer(t *testing.T) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/owner/with-variable/actions/variables/COSIGN_PUBLIC_KEY", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&variable{Name: "COSIGN_PUBLIC_KEY", Value: variableKey})
+	})
+	for repo, status := range map[string]int{
+		"with-file-unauthorized": http.StatusUnauthorized,
+		"with-file-forbidden":    http.StatusForbidden,
+		"with-file-broken":       http.StatusInternalServerError,
+	} {
+		status := status
+		mux.HandleFunc("/api/v3/repos/owner/"+repo+"/actions/variables/COSIGN_PUBLIC_KEY", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message": "Nope"}`, status)
+		})
+	}
+	for _, repo := range []string{"with-variable", "with-file", "with-file-unauthorized", "with-file-forbidden", "with-file-broken"} {
+		mux.HandleFunc("/api/v3/repos/owner/"+repo+"/contents/cosign.pub", func(w http.ResponseWriter, r *http.Request) {
+			if ref := r.URL.Query().Get("ref"); ref != "" && ref != "v1" {
+				http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"type":     "file",
+				"encoding": "base64",
+				"name":     "cosign.pub",
+				"path":     "cosign.pub",
+				"content":  base64.StdEncoding.EncodeToString([]byte(fileKey)),
+			})
+		})
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+	})
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	t.Setenv("GITHUB_HOST", s.URL)
+}
+
+func TestGetPublicKey(t *testing.T) {
+	apiServer(t)
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{{
+		ref:  "owner/with-variable",
+		want: variableKey,
+	}, {
+		ref:  "owner/with-file",
+		want: fileKey,
+	}, {
+		ref:  "owner/with-file-unauthorized",
+		want: fileKey,
+	}, {
+		ref:  "owner/with-file-forbidden",
+		want: fileKey,
+	}, {
+		ref:     "owner/with-file-broken",
+		wantErr: true,
+	}, {
+		ref:  "owner/with-variable@v1",
+		want: fileKey,
+	}, {
+		ref:     "owner/with-file@v2",
+		wantErr: true,
+	}, {
+		ref:     "owner/without-key",
+		wantErr: true,
+	}, {
+		ref:     "owner",
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.ref, func(t *testing.T) {
+			got, err := New().GetPublicKey(context.Background(), test.ref)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("GetPublicKey() = %q, wanted an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPublicKey() = %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("GetPublicKey() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("COSIGN_PRIVATE_KEY", "private key")
+	if got, err := New().GetSecret(ctx, "owner/repo", "COSIGN_PRIVATE_KEY"); err != nil || got != "private key" {
+		t.Errorf("GetSecret() = %q, %v, wanted the environment variable", got, err)
+	}
+
+	os.Unsetenv("COSIGN_PRIVATE_KEY")
+	if got, err := New().GetSecret(ctx, "owner/repo", "COSIGN_PRIVATE_KEY"); err == nil {
+		t.Errorf("GetSecret() = %q, wanted an error for an unexposed secret", got)
+	}
+}
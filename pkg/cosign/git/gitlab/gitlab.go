@@ -18,8 +18,10 @@ package gitlab
 import (
 	"context"
 	"fmt"
-	"io"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/sigstore/cosign/pkg/cosign"
@@ -28,8 +30,20 @@ import (
 
 const (
 	ReferenceScheme = "gitlab"
+
+	publicKeyVariable = "COSIGN_PUBLIC_KEY"
+	publicKeyFile     = "cosign.pub"
 )
 
+// publicKeyRingNames names the public key variables of a project:
+// COSIGN_PUBLIC_KEY, and the rotated ones COSIGN_PUBLIC_KEY_1,
+// COSIGN_PUBLIC_KEY_1_EXPIRES, etc.
+var publicKeyRingNames = cosign.PublicKeyRingNames{
+	Current:   publicKeyVariable,
+	Separator: "_",
+	Expires:   "EXPIRES",
+}
+
 type Gl struct{}
 
 func New() *Gl {
@@ -37,84 +51,66 @@ func New() *Gl {
 }
 
 func (g *Gl) PutSecret(ctx context.Context, ref string, pf cosign.PassFunc) error {
+	return putKeyPair(ref, pf, false, 0)
+}
+
+// RotateSecret is like PutSecret, but keeps the public key of the key pair it
+// replaces as the COSIGN_PUBLIC_KEY_1 variable, accepted by GetPublicKey until
+// retiredFor elapsed.
+func (g *Gl) RotateSecret(ctx context.Context, ref string, pf cosign.PassFunc, retiredFor time.Duration) error {
+	return putKeyPair(ref, pf, true, retiredFor)
+}
+
+func putKeyPair(ref string, pf cosign.PassFunc, rotate bool, retiredFor time.Duration) error {
 	keys, err := cosign.GenerateKeyPair(pf)
 	if err != nil {
 		return errors.Wrap(err, "generating key pair")
 	}
 
-	token, tokenExists := os.LookupEnv("GITLAB_TOKEN")
-
-	if !tokenExists {
-		return errors.New("could not find \"GITLAB_TOKEN\"")
+	client, err := newClient()
+	if err != nil {
+		return err
 	}
 
-	var client *gitlab.Client
-	if url, baseURLExists := os.LookupEnv("GITLAB_HOST"); baseURLExists {
-		client, err = gitlab.NewClient(token, gitlab.WithBaseURL(url))
-		if err != nil {
-			return errors.Wrap(err, "could not create GitLab client")
+	ring := &cosign.PublicKeyRing{Current: keys.PublicBytes}
+	var prev *cosign.PublicKeyRing
+	if rotate {
+		if prev, err = readPublicKeyRing(client, ref); err != nil {
+			return err
 		}
-	} else {
-		client, err = gitlab.NewClient(token)
-		if err != nil {
-			return errors.Wrap(err, "could not create GitLab client")
+		if len(prev.Current) == 0 {
+			return errors.Errorf("%s has no %s variable, there is no key pair to rotate", ref, publicKeyVariable)
 		}
+		now := time.Now()
+		*ring = *prev
+		ring.Rotate(keys.PublicBytes, now, now.Add(retiredFor))
 	}
 
-	_, passwordResp, err := client.ProjectVariables.CreateVariable(ref, &gitlab.CreateProjectVariableOptions{
-		Key:              gitlab.String("COSIGN_PASSWORD"),
-		Value:            gitlab.String(string(keys.Password())),
-		VariableType:     gitlab.VariableType(gitlab.EnvVariableType),
-		Protected:        gitlab.Bool(false),
-		Masked:           gitlab.Bool(false),
-		EnvironmentScope: gitlab.String("*"),
-	})
-	if err != nil {
-		return errors.Wrap(err, "could not create \"COSIGN_PASSWORD\" variable")
-	}
-
-	if passwordResp.StatusCode < 200 && passwordResp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(passwordResp.Body)
-		return errors.Errorf("%s", bodyBytes)
+	if err := setVariable(client, ref, "COSIGN_PASSWORD", string(keys.Password())); err != nil {
+		return err
 	}
 
 	fmt.Fprintln(os.Stderr, "Password written to \"COSIGN_PASSWORD\" variable")
 
-	_, privateKeyResp, err := client.ProjectVariables.CreateVariable(ref, &gitlab.CreateProjectVariableOptions{
-		Key:          gitlab.String("COSIGN_PRIVATE_KEY"),
-		Value:        gitlab.String(string(keys.PrivateBytes)),
-		VariableType: gitlab.VariableType(gitlab.EnvVariableType),
-		Protected:    gitlab.Bool(false),
-		Masked:       gitlab.Bool(false),
-	})
-	if err != nil {
-		return errors.Wrap(err, "could not create \"COSIGN_PRIVATE_KEY\" variable")
-	}
-
-	if privateKeyResp.StatusCode < 200 && privateKeyResp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(privateKeyResp.Body)
-		return errors.Errorf("%s", bodyBytes)
+	if err := setVariable(client, ref, "COSIGN_PRIVATE_KEY", string(keys.PrivateBytes)); err != nil {
+		return err
 	}
 
 	fmt.Fprintln(os.Stderr, "Private key written to \"COSIGN_PRIVATE_KEY\" variable")
 
-	_, publicKeyResp, err := client.ProjectVariables.CreateVariable(ref, &gitlab.CreateProjectVariableOptions{
-		Key:          gitlab.String("COSIGN_PUBLIC_KEY"),
-		Value:        gitlab.String(string(keys.PublicBytes)),
-		VariableType: gitlab.VariableType(gitlab.EnvVariableType),
-		Protected:    gitlab.Bool(false),
-		Masked:       gitlab.Bool(false),
-	})
-	if err != nil {
-		return errors.Wrap(err, "could not create \"COSIGN_PUBLIC_KEY\" variable")
-	}
-
-	if publicKeyResp.StatusCode < 200 && publicKeyResp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(publicKeyResp.Body)
-		return errors.Errorf("%s", bodyBytes)
+	if err := ring.Write(prev, publicKeyRingNames, func(key string, value []byte) error {
+		return setVariable(client, ref, key, string(value))
+	}, func(key string) error {
+		_, err := client.ProjectVariables.RemoveVariable(ref, key)
+		return errors.Wrapf(err, "could not remove %q variable", key)
+	}); err != nil {
+		return err
 	}
 
 	fmt.Fprintln(os.Stderr, "Public key written to \"COSIGN_PUBLIC_KEY\" variable")
+	if len(ring.Retired) > 0 {
+		fmt.Fprintf(os.Stderr, "Previous public key kept as \"%s_1\" variable until %s\n", publicKeyVariable, ring.Retired[0].Expires.Format(time.RFC3339))
+	}
 
 	if err := os.WriteFile("cosign.pub", keys.PublicBytes, 0o600); err != nil {
 		return err
@@ -124,38 +120,102 @@ func (g *Gl) PutSecret(ctx context.Context, ref string, pf cosign.PassFunc) erro
 	return nil
 }
 
-func (g *Gl) GetSecret(ctx context.Context, ref string, key string) (string, error) {
-	token, tokenExists := os.LookupEnv("GITLAB_TOKEN")
-	var varPubKeyValue string
-	if !tokenExists {
-		return varPubKeyValue, errors.New("could not find \"GITLAB_TOKEN\"")
+// setVariable creates the variable key of the project ref refers to, or
+// updates it if it exists.
+func setVariable(client *gitlab.Client, ref, key, value string) error {
+	_, resp, err := client.ProjectVariables.UpdateVariable(ref, key, &gitlab.UpdateProjectVariableOptions{
+		Value:        gitlab.String(value),
+		VariableType: gitlab.VariableType(gitlab.EnvVariableType),
+		Protected:    gitlab.Bool(false),
+		Masked:       gitlab.Bool(false),
+	})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		_, _, err = client.ProjectVariables.CreateVariable(ref, &gitlab.CreateProjectVariableOptions{
+			Key:              gitlab.String(key),
+			Value:            gitlab.String(value),
+			VariableType:     gitlab.VariableType(gitlab.EnvVariableType),
+			Protected:        gitlab.Bool(false),
+			Masked:           gitlab.Bool(false),
+			EnvironmentScope: gitlab.String("*"),
+		})
 	}
+	return errors.Wrapf(err, "could not create %q variable", key)
+}
 
-	var client *gitlab.Client
-	var err error
-	if url, baseURLExists := os.LookupEnv("GITLAB_HOST"); baseURLExists {
-		client, err = gitlab.NewClient(token, gitlab.WithBaseURL(url))
-		if err != nil {
-			return varPubKeyValue, errors.Wrap(err, "could not create GitLab client")
+// readPublicKeyRing reads the public key ring of a project, which is empty if
+// it has no COSIGN_PUBLIC_KEY variable.
+func readPublicKeyRing(client *gitlab.Client, ref string) (*cosign.PublicKeyRing, error) {
+	return cosign.ReadPublicKeyRing(func(key string) ([]byte, error) {
+		variable, resp, err := client.ProjectVariables.GetVariable(ref, key)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "could not retrieve %q variable", key)
 		}
+		return []byte(variable.Value), nil
+	}, publicKeyRingNames)
+}
+
+// GetSecret returns the variable key of the project ref refers to.
+func (g *Gl) GetSecret(ctx context.Context, ref string, key string) (string, error) {
+	client, err := newClient()
+	if err != nil {
+		return "", err
+	}
+
+	variable, _, err := client.ProjectVariables.GetVariable(ref, key)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not retrieve %q variable", key)
+	}
+	return variable.Value, nil
+}
+
+// GetPublicKey returns the public key of the project ref refers to, as
+// <project>[@<git ref>]: the COSIGN_PUBLIC_KEY variable or, if there is none,
+// the cosign.pub file of its default branch. The file is always read from
+// the git ref, if there is one.
+func (g *Gl) GetPublicKey(ctx context.Context, ref string) ([]byte, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	project, gitRef := ref, "HEAD"
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		project, gitRef = ref[:i], ref[i+1:]
 	} else {
-		client, err = gitlab.NewClient(token)
+		ring, err := readPublicKeyRing(client, project)
 		if err != nil {
-			return varPubKeyValue, errors.Wrap(err, "could not create GitLab client")
+			return nil, err
+		}
+		if len(ring.Current) > 0 {
+			// Accept the keys it was rotated from until they expire.
+			return ring.Valid(time.Now()), nil
 		}
 	}
 
-	varPubKey, pubKeyResp, err := client.ProjectVariables.GetVariable(ref, key)
+	pubKey, _, err := client.RepositoryFiles.GetRawFile(project, publicKeyFile, &gitlab.GetRawFileOptions{Ref: gitlab.String(gitRef)})
 	if err != nil {
-		return varPubKeyValue, errors.Wrap(err, "could not retrieve \"COSIGN_PUBLIC_KEY\" variable")
+		return nil, errors.Wrapf(err, "could not retrieve a %q variable nor a %s file in %s", publicKeyVariable, publicKeyFile, ref)
 	}
+	return pubKey, nil
+}
 
-	varPubKeyValue = varPubKey.Value
-
-	if pubKeyResp.StatusCode < 200 && pubKeyResp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(pubKeyResp.Body)
-		return varPubKeyValue, errors.Errorf("%s", bodyBytes)
+// newClient returns a client of GitLab, or of the instance GITLAB_HOST names,
+// authenticated with GITLAB_TOKEN.
+func newClient() (*gitlab.Client, error) {
+	token, tokenExists := os.LookupEnv("GITLAB_TOKEN")
+	if !tokenExists {
+		return nil, errors.New("could not find \"GITLAB_TOKEN\"")
 	}
 
-	return varPubKeyValue, nil
+	var opts []gitlab.ClientOptionFunc
+	if url, baseURLExists := os.LookupEnv("GITLAB_HOST"); baseURLExists {
+		opts = append(opts, gitlab.WithBaseURL(url))
+	}
+	client, err := gitlab.NewClient(token, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create GitLab client")
+	}
+	return client, nil
 }
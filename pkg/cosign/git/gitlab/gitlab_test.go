@@ -0,0 +1,109 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	variableKey = "variable key"
+	fileKey     = "file key"
+)
+
+// apiServer serves the COSIGN_PUBLIC_KEY variable of project 1, and the
+// cosign.pub file of projects 1 and 2 at the git refs "v1" and HEAD.
+func apiServer(t *testing.T) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/1/variables/COSIGN_PUBLIC_KEY", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"key": "COSIGN_PUBLIC_KEY", "value": variableKey})
+	})
+	for _, project := range []int{1, 2} {
+		mux.HandleFunc(fmt.Sprintf("/api/v4/projects/%d/repository/files/cosign.pub/raw", project), func(w http.ResponseWriter, r *http.Request) {
+			if ref := r.URL.Query().Get("ref"); ref != "HEAD" && ref != "v1" {
+				http.Error(w, `{"message": "404 Commit Not Found"}`, http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, fileKey)
+		})
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "404 Not Found"}`, http.StatusNotFound)
+	})
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	t.Setenv("GITLAB_HOST", s.URL)
+	t.Setenv("GITLAB_TOKEN", "token")
+}
+
+func TestGetPublicKey(t *testing.T) {
+	apiServer(t)
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{{
+		ref:  "1",
+		want: variableKey,
+	}, {
+		ref:  "2",
+		want: fileKey,
+	}, {
+		ref:  "1@v1",
+		want: fileKey,
+	}, {
+		ref:     "2@v2",
+		wantErr: true,
+	}, {
+		ref:     "3",
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		t.Run(test.ref, func(t *testing.T) {
+			got, err := New().GetPublicKey(context.Background(), test.ref)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("GetPublicKey() = %q, wanted an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPublicKey() = %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("GetPublicKey() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetSecret(t *testing.T) {
+	apiServer(t)
+	ctx := context.Background()
+
+	if got, err := New().GetSecret(ctx, "1", "COSIGN_PUBLIC_KEY"); err != nil || got != variableKey {
+		t.Errorf("GetSecret() = %q, %v, wanted %q", got, err, variableKey)
+	}
+	if got, err := New().GetSecret(ctx, "1", "COSIGN_PRIVATE_KEY"); err == nil {
+		t.Errorf("GetSecret() = %q, wanted an error for a missing variable", got)
+	}
+}
@@ -16,16 +16,19 @@
 package cosign
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
-	_ "crypto/sha256" // for `crypto.SHA256`
+	"crypto/sha256"
+	_ "crypto/sha512" // for `crypto.SHA384` and `crypto.SHA512`
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -48,6 +51,23 @@ const (
 	BundleKey         = static.BundleAnnotationKey
 )
 
+// Key algorithms supported by GenerateKeyPairWithAlgorithm.
+const (
+	ECDSAP256 = "ecdsa-p256"
+	ECDSAP384 = "ecdsa-p384"
+	ECDSAP521 = "ecdsa-p521"
+	RSA2048   = "rsa-2048"
+	RSA3072   = "rsa-3072"
+	RSA4096   = "rsa-4096"
+	ED25519   = "ed25519"
+
+	// DefaultKeyAlgorithm is the algorithm used by GenerateKeyPair
+	DefaultKeyAlgorithm = ECDSAP256
+)
+
+// KeyAlgorithms lists the key algorithms supported by GenerateKeyPairWithAlgorithm.
+var KeyAlgorithms = []string{ECDSAP256, ECDSAP384, ECDSAP521, RSA2048, RSA3072, RSA4096, ED25519}
+
 type PassFunc func(bool) ([]byte, error)
 
 type Keys struct {
@@ -94,6 +114,110 @@ func GeneratePrivateKey() (*ecdsa.PrivateKey, error) {
 	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 }
 
+// GeneratePrivateKeyWithAlgorithm generates a private key using one of KeyAlgorithms.
+func GeneratePrivateKeyWithAlgorithm(alg string) (crypto.Signer, error) {
+	switch alg {
+	case ECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case ECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case ECDSAP521:
+		return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case RSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case RSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case RSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	case ED25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %q, expected one of %v", alg, KeyAlgorithms)
+	}
+}
+
+// KeyID returns the hex encoded SHA-256 of the DER encoded public key pub, which
+// identifies keys in revocation lists, or the empty string if pub can't be encoded.
+func KeyID(pub crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(der))
+}
+
+// HashAlgorithmForKey returns the hash algorithm cosign uses when signing with, or
+// verifying against, the given public key: SHA-384 for P-384, SHA-512 for P-521 and
+// SHA-256 for P-256 and RSA keys. ED25519 signs the message itself, which is
+// reported as a zero crypto.Hash.
+func HashAlgorithmForKey(pub crypto.PublicKey) crypto.Hash {
+	switch pub := pub.(type) {
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P384():
+			return crypto.SHA384
+		case elliptic.P521():
+			return crypto.SHA512
+		}
+	case ed25519.PublicKey:
+		return crypto.Hash(0)
+	}
+	return crypto.SHA256
+}
+
+// LoadVerifier returns a verifier for pub using the hash algorithm of
+// HashAlgorithmForKey.
+func LoadVerifier(pub crypto.PublicKey) (signature.Verifier, error) {
+	return signature.LoadVerifier(pub, HashAlgorithmForKey(pub))
+}
+
+// LegacySHA256Verifier returns a verifier accepting the signatures of verifier,
+// or the SHA-256 signatures of its key: cosign signed with SHA-256 for all key
+// types before, so P-384 and P-521 keys may have made either. Verifiers of keys
+// signing SHA-256 digests, or messages, are returned as is.
+func LegacySHA256Verifier(verifier signature.Verifier) (signature.Verifier, error) {
+	pub, err := verifier.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if h := HashAlgorithmForKey(pub); h == crypto.SHA256 || h == crypto.Hash(0) {
+		return verifier, nil
+	}
+	legacy, err := signature.LoadVerifier(pub, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	return &legacyHashVerifier{Verifier: verifier, legacy: legacy}, nil
+}
+
+// legacyHashVerifier accepts the signatures of Verifier, or failing that, of legacy.
+type legacyHashVerifier struct {
+	signature.Verifier
+	legacy signature.Verifier
+}
+
+// VerifySignature implements signature.Verifier
+func (v *legacyHashVerifier) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	if message == nil {
+		// The digest passed in the options was computed with a single hash algorithm.
+		return v.Verifier.VerifySignature(sig, message, opts...)
+	}
+	// Each verifier consumes the readers.
+	sigBytes, err := io.ReadAll(sig)
+	if err != nil {
+		return err
+	}
+	msgBytes, err := io.ReadAll(message)
+	if err != nil {
+		return err
+	}
+	if err := v.Verifier.VerifySignature(bytes.NewReader(sigBytes), bytes.NewReader(msgBytes), opts...); err == nil {
+		return nil
+	}
+	return v.legacy.VerifySignature(bytes.NewReader(sigBytes), bytes.NewReader(msgBytes), opts...)
+}
+
 func ImportKeyPair(keyPath string, pf PassFunc) (*KeysBytes, error) {
 	kb, err := os.ReadFile(filepath.Clean(keyPath))
 	if err != nil {
@@ -198,6 +322,16 @@ func GenerateKeyPair(pf PassFunc) (*KeysBytes, error) {
 	return marshalKeyPair(Keys{priv, priv.Public()}, pf)
 }
 
+// GenerateKeyPairWithAlgorithm is like GenerateKeyPair, using one of KeyAlgorithms.
+func GenerateKeyPairWithAlgorithm(pf PassFunc, alg string) (*KeysBytes, error) {
+	priv, err := GeneratePrivateKeyWithAlgorithm(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalKeyPair(Keys{priv, priv.Public()}, pf)
+}
+
 func (k *KeysBytes) Password() []byte {
 	return k.password
 }
@@ -235,9 +369,11 @@ func LoadPrivateKey(key []byte, pass []byte) (signature.SignerVerifier, error) {
 	}
 	switch pk := pk.(type) {
 	case *rsa.PrivateKey:
-		return signature.LoadRSAPKCS1v15SignerVerifier(pk, crypto.SHA256)
+		return signature.LoadRSAPKCS1v15SignerVerifier(pk, HashAlgorithmForKey(pk.Public()))
 	case *ecdsa.PrivateKey:
-		return signature.LoadECDSASignerVerifier(pk, crypto.SHA256)
+		return signature.LoadECDSASignerVerifier(pk, HashAlgorithmForKey(pk.Public()))
+	case ed25519.PrivateKey:
+		return signature.LoadED25519SignerVerifier(pk)
 	default:
 		return nil, errors.Wrap(err, "unsupported key type")
 	}
@@ -16,12 +16,18 @@
 package cosign
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
 	"github.com/stretchr/testify/require"
 )
 
@@ -384,3 +390,108 @@ func TestImportPrivateKey(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateKeyPairWithAlgorithm(t *testing.T) {
+	tests := []struct {
+		keyAlgorithm  string
+		hashAlgorithm crypto.Hash
+	}{
+		{keyAlgorithm: ECDSAP256, hashAlgorithm: crypto.SHA256},
+		{keyAlgorithm: ECDSAP384, hashAlgorithm: crypto.SHA384},
+		{keyAlgorithm: ECDSAP521, hashAlgorithm: crypto.SHA512},
+		{keyAlgorithm: RSA2048, hashAlgorithm: crypto.SHA256},
+		{keyAlgorithm: RSA3072, hashAlgorithm: crypto.SHA256},
+		{keyAlgorithm: ED25519, hashAlgorithm: crypto.Hash(0)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.keyAlgorithm, func(t *testing.T) {
+			keys, err := GenerateKeyPairWithAlgorithm(pass("hello"), tc.keyAlgorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sv, err := LoadPrivateKey(keys.PrivateBytes, []byte("hello"))
+			if err != nil {
+				t.Fatalf("LoadPrivateKey() = %v", err)
+			}
+			pub, err := cryptoutils.UnmarshalPEMToPublicKey(keys.PublicBytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := HashAlgorithmForKey(pub); got != tc.hashAlgorithm {
+				t.Errorf("HashAlgorithmForKey() = %v, wanted %v", got, tc.hashAlgorithm)
+			}
+
+			message := []byte("hello world")
+			sig, err := sv.SignMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifier, err := signature.LoadVerifier(pub, HashAlgorithmForKey(pub))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("VerifySignature() = %v", err)
+			}
+			if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("tampered"))); err == nil {
+				t.Error("VerifySignature() on another message expected error")
+			}
+		})
+	}
+
+	if _, err := GenerateKeyPairWithAlgorithm(pass("hello"), "ecdsa-p224"); err == nil {
+		t.Error("GenerateKeyPairWithAlgorithm() with an unsupported algorithm expected error")
+	}
+}
+
+func TestLegacySHA256Verifier(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, err := LoadVerifier(priv.Public())
+	if err != nil {
+		t.Fatalf("LoadVerifier() = %v", err)
+	}
+	verifier, err := LegacySHA256Verifier(strict)
+	if err != nil {
+		t.Fatalf("LegacySHA256Verifier() = %v", err)
+	}
+
+	// Signatures made before cosign picked the hash algorithm by key are over SHA-256 digests.
+	for _, hashAlgorithm := range []crypto.Hash{crypto.SHA384, crypto.SHA256} {
+		t.Run(hashAlgorithm.String(), func(t *testing.T) {
+			sv, err := signature.LoadECDSASignerVerifier(priv, hashAlgorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			message := []byte("hello world")
+			sig, err := sv.SignMessage(bytes.NewReader(message))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message)); err != nil {
+				t.Errorf("VerifySignature() = %v", err)
+			}
+			if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("tampered"))); err == nil {
+				t.Error("VerifySignature() on another message expected error")
+			}
+			err = strict.VerifySignature(bytes.NewReader(sig), bytes.NewReader(message))
+			if wantErr := hashAlgorithm != crypto.SHA384; (err != nil) != wantErr {
+				t.Errorf("LoadVerifier() VerifySignature() = %v, wanted error: %t", err, wantErr)
+			}
+		})
+	}
+
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := LoadVerifier(p256.Public())
+	if err != nil {
+		t.Fatalf("LoadVerifier() = %v", err)
+	}
+	if got, err := LegacySHA256Verifier(v); err != nil || got != v {
+		t.Errorf("LegacySHA256Verifier(P-256) = %v, %v, wanted the verifier as is", got, err)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"k8s.io/utils/pointer"
 
@@ -32,8 +33,18 @@ import (
 
 const (
 	KeyReference = "k8s://"
+
+	publicKeyName = "cosign.pub"
 )
 
+// publicKeyRingNames names the public keys of a secret: cosign.pub, and the
+// rotated ones cosign.pub.1, cosign.pub.1.expires, etc.
+var publicKeyRingNames = cosign.PublicKeyRingNames{
+	Current:   publicKeyName,
+	Separator: ".",
+	Expires:   "expires",
+}
+
 func GetKeyPairSecret(ctx context.Context, k8sRef string) (*v1.Secret, error) {
 	namespace, name, err := parseRef(k8sRef)
 	if err != nil {
@@ -54,6 +65,37 @@ func GetKeyPairSecret(ctx context.Context, k8sRef string) (*v1.Secret, error) {
 }
 
 func KeyPairSecret(ctx context.Context, k8sRef string, pf cosign.PassFunc) error {
+	return keyPairSecret(ctx, k8sRef, pf, nil)
+}
+
+// RotateKeyPairSecret is like KeyPairSecret, but keeps the public key of the
+// key pair it replaces as cosign.pub.1, accepted until retiredFor elapsed,
+// see PublicKeys.
+func RotateKeyPairSecret(ctx context.Context, k8sRef string, pf cosign.PassFunc, retiredFor time.Duration) error {
+	return keyPairSecret(ctx, k8sRef, pf, func(data map[string][]byte, pub []byte) error {
+		ring, err := readPublicKeyRing(data)
+		if err != nil {
+			return err
+		}
+		if len(ring.Current) == 0 {
+			return errors.New("there is no key pair to rotate")
+		}
+		prev := *ring
+		now := time.Now()
+		ring.Rotate(pub, now, now.Add(retiredFor))
+		return ring.Write(&prev, publicKeyRingNames, func(key string, value []byte) error {
+			data[key] = value
+			return nil
+		}, func(key string) error {
+			delete(data, key)
+			return nil
+		})
+	})
+}
+
+// keyPairSecret generates a key pair in the secret k8sRef refers to, calling
+// rotate, if set, to update the existing data of the secret with the new public key.
+func keyPairSecret(ctx context.Context, k8sRef string, pf cosign.PassFunc, rotate func(data map[string][]byte, pub []byte) error) error {
 	namespace, name, err := parseRef(k8sRef)
 	if err != nil {
 		return err
@@ -75,16 +117,31 @@ func KeyPairSecret(ctx context.Context, k8sRef string, pf cosign.PassFunc) error
 	}
 	var s *v1.Secret
 	if s, err = client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
-		if k8serrors.IsNotFound(err) {
-			s, err = client.CoreV1().Secrets(namespace).Create(ctx, secret(keys, namespace, name, nil, immutable), metav1.CreateOptions{})
-			if err != nil {
-				return errors.Wrapf(err, "creating secret %s in ns %s", name, namespace)
-			}
-		} else {
+		if !k8serrors.IsNotFound(err) {
 			return errors.Wrap(err, "checking if secret exists")
 		}
+		if rotate != nil {
+			return errors.Errorf("secret %s in ns %s does not exist, there is no key pair to rotate", name, namespace)
+		}
+		s, err = client.CoreV1().Secrets(namespace).Create(ctx, secret(keys, namespace, name, nil, immutable), metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "creating secret %s in ns %s", name, namespace)
+		}
 	} else { // Update the existing secret
-		s, err = client.CoreV1().Secrets(namespace).Update(ctx, secret(keys, namespace, name, s.Data, immutable), metav1.UpdateOptions{})
+		if s.Immutable != nil && *s.Immutable {
+			// Replacing it would leave no key pair at all if creating the new one failed.
+			return errors.Errorf("secret %s in ns %s is immutable and can't be updated, delete it first to generate a new key pair", name, namespace)
+		}
+		data := s.Data
+		if data == nil {
+			data = map[string][]byte{}
+		}
+		if rotate != nil {
+			if err := rotate(data, keys.PublicBytes); err != nil {
+				return errors.Wrapf(err, "rotating secret %s in ns %s", name, namespace)
+			}
+		}
+		s, err = client.CoreV1().Secrets(namespace).Update(ctx, secret(keys, namespace, name, data, immutable), metav1.UpdateOptions{})
 		if err != nil {
 			return errors.Wrapf(err, "updating secret %s in ns %s", name, namespace)
 		}
@@ -98,6 +155,23 @@ func KeyPairSecret(ctx context.Context, k8sRef string, pf cosign.PassFunc) error
 	return nil
 }
 
+// PublicKeys returns the PEM encoded public keys of the data of a secret that
+// are accepted at now: cosign.pub, and the keys it was rotated from that
+// didn't expire yet.
+func PublicKeys(data map[string][]byte, now time.Time) ([]byte, error) {
+	ring, err := readPublicKeyRing(data)
+	if err != nil {
+		return nil, err
+	}
+	return ring.Valid(now), nil
+}
+
+func readPublicKeyRing(data map[string][]byte) (*cosign.PublicKeyRing, error) {
+	return cosign.ReadPublicKeyRing(func(key string) ([]byte, error) {
+		return data[key], nil
+	}, publicKeyRingNames)
+}
+
 // creates a secret with the following data:
 // * cosign.key
 // * cosign.pub
@@ -107,7 +181,7 @@ func secret(keys *cosign.KeysBytes, namespace, name string, data map[string][]by
 		data = map[string][]byte{}
 	}
 	data["cosign.key"] = keys.PrivateBytes
-	data["cosign.pub"] = keys.PublicBytes
+	data[publicKeyName] = keys.PublicBytes
 	data["cosign.password"] = keys.Password()
 
 	obj := metav1.ObjectMeta{
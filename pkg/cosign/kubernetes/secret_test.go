@@ -17,6 +17,7 @@ package kubernetes
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"k8s.io/utils/pointer"
 
@@ -123,3 +124,22 @@ func TestParseRef(t *testing.T) {
 		})
 	}
 }
+
+func TestPublicKeys(t *testing.T) {
+	now := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	data := map[string][]byte{
+		"cosign.key":           []byte("private"),
+		"cosign.pub":           []byte("current"),
+		"cosign.pub.1":         []byte("rotated"),
+		"cosign.pub.1.expires": []byte("2021-12-02T00:00:00Z"),
+		"cosign.pub.2":         []byte("expired"),
+		"cosign.pub.2.expires": []byte("2021-11-30T00:00:00Z"),
+	}
+	got, err := PublicKeys(data, now)
+	if err != nil {
+		t.Fatalf("PublicKeys() = %v", err)
+	}
+	if want := "current\nrotated"; string(got) != want {
+		t.Errorf("PublicKeys() = %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+)
+
+const (
+	admissionsName          = "admissions"
+	verificationLatencyName = "verification_latencies"
+	registryErrorsName      = "registry_errors"
+	rekorErrorsName         = "rekor_errors"
+	keyLoadFailuresName     = "key_load_failures"
+)
+
+// Admission decisions.
+const (
+	decisionAllowed = "allowed"
+	decisionDenied  = "denied"
+)
+
+// Reasons of admission decisions, reasonVerified for the allowed ones.
+const (
+	reasonVerified         = "verified"
+	reasonInvalidReference = "invalid_reference"
+	reasonNotDigest        = "not_digest"
+	reasonKeychain         = "keychain"
+	reasonKeyLoad          = "key_load"
+	reasonRegistryError    = "registry_error"
+	reasonRekorError       = "rekor_error"
+	reasonNoValidSignature = "no_valid_signature"
+)
+
+var (
+	admissionsM = stats.Int64(
+		admissionsName,
+		"The number of pod specs admitted or denied, by namespace, decision and reason",
+		stats.UnitDimensionless)
+	verificationLatencyM = stats.Float64(
+		verificationLatencyName,
+		"The time it takes to verify the signatures of an image in milliseconds",
+		stats.UnitMilliseconds)
+	registryErrorsM = stats.Int64(
+		registryErrorsName,
+		"The number of admissions denied because images or signatures couldn't be fetched from registries",
+		stats.UnitDimensionless)
+	rekorErrorsM = stats.Int64(
+		rekorErrorsName,
+		"The number of admissions denied because the transparency log or its public keys couldn't be reached",
+		stats.UnitDimensionless)
+	keyLoadFailuresM = stats.Int64(
+		keyLoadFailuresName,
+		"The number of failures loading the public keys from the secret",
+		stats.UnitDimensionless)
+
+	namespaceKey = tag.MustNewKey("namespace")
+	decisionKey  = tag.MustNewKey("decision")
+	reasonKey    = tag.MustNewKey("reason")
+)
+
+// RegisterMetrics registers the views of the webhook metrics, for the
+// exporter sharedmain sets up to export them.
+func RegisterMetrics() {
+	if err := view.Register(
+		&view.View{
+			Description: admissionsM.Description(),
+			Measure:     admissionsM,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{namespaceKey, decisionKey, reasonKey},
+		},
+		&view.View{
+			Description: verificationLatencyM.Description(),
+			Measure:     verificationLatencyM,
+			Aggregation: view.Distribution(metrics.Buckets125(1, 100000)...), // [1 2 5 10 20 50 100 200 500 1000 2000 5000 10000 20000 50000 100000]ms
+			TagKeys:     []tag.Key{decisionKey},
+		},
+		&view.View{
+			Description: registryErrorsM.Description(),
+			Measure:     registryErrorsM,
+			Aggregation: view.Count(),
+		},
+		&view.View{
+			Description: rekorErrorsM.Description(),
+			Measure:     rekorErrorsM,
+			Aggregation: view.Count(),
+		},
+		&view.View{
+			Description: keyLoadFailuresM.Description(),
+			Measure:     keyLoadFailuresM,
+			Aggregation: view.Count(),
+		},
+	); err != nil {
+		panic(err)
+	}
+}
+
+func recordAdmission(ctx context.Context, namespace, decision, reason string) {
+	ctx, err := tag.New(ctx,
+		tag.Insert(namespaceKey, namespace),
+		tag.Insert(decisionKey, decision),
+		tag.Insert(reasonKey, reason))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, admissionsM.M(1))
+}
+
+func recordVerification(ctx context.Context, decision string, d time.Duration) {
+	ctx, err := tag.New(ctx, tag.Insert(decisionKey, decision))
+	if err != nil {
+		return
+	}
+	metrics.Record(ctx, verificationLatencyM.M(float64(d.Milliseconds())))
+}
+
+func recordKeyLoadFailure(ctx context.Context) {
+	metrics.Record(ctx, keyLoadFailuresM.M(1))
+}
+
+// recordVerificationErrors records, once per admission, that images were
+// denied because of registry or transparency log errors, given the reasons
+// of the denied images.
+func recordVerificationErrors(ctx context.Context, reasons map[string]bool) {
+	if reasons[reasonRegistryError] {
+		metrics.Record(ctx, registryErrorsM.M(1))
+	}
+	if reasons[reasonRekorError] {
+		metrics.Record(ctx, rekorErrorsM.M(1))
+	}
+}
+
+// verificationErrorReason returns the reason to deny an image whose
+// verification failed with err.
+func verificationErrorReason(err error) string {
+	var terr *transport.Error
+	if errors.As(err, &terr) {
+		return reasonRegistryError
+	}
+	// No signature verified: blame the transparency log only if some
+	// signature couldn't be checked because it was unreachable.
+	var nms *cosign.NoMatchingSignaturesError
+	if errors.As(err, &nms) {
+		for _, err := range nms.Errs {
+			var tlerr *cosign.TransparencyLogError
+			if errors.As(err, &tlerr) {
+				return reasonRekorError
+			}
+		}
+	}
+	return reasonNoValidSignature
+}
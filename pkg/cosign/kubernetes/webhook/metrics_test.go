@@ -0,0 +1,101 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	pkgerrors "github.com/pkg/errors"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricstest"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+)
+
+// opencensus metrics carry global state that need to be reset between unit tests
+func resetMetrics() {
+	metricstest.Unregister(admissionsName, verificationLatencyName, registryErrorsName, rekorErrorsName, keyLoadFailuresName)
+	RegisterMetrics()
+}
+
+func TestVerificationErrorReason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{{
+		name: "registry error",
+		err:  pkgerrors.Wrap(&transport.Error{StatusCode: http.StatusInternalServerError}, "fetching signatures"),
+		want: reasonRegistryError,
+	}, {
+		name: "transparency log error",
+		err: &cosign.NoMatchingSignaturesError{Errs: []error{
+			errors.New("invalid signature"),
+			pkgerrors.Wrap(&cosign.TransparencyLogError{Err: errors.New("searching log query")}, "unable to verify bundle"),
+		}},
+		want: reasonRekorError,
+	}, {
+		name: "bundle mismatch",
+		err: &cosign.NoMatchingSignaturesError{Errs: []error{
+			pkgerrors.Wrap(errors.New("certificate expired before signatures were entered in log"), "unable to verify bundle"),
+		}},
+		want: reasonNoValidSignature,
+	}, {
+		name: "invalid signatures",
+		err:  &cosign.NoMatchingSignaturesError{Errs: []error{errors.New("invalid signature")}},
+		want: reasonNoValidSignature,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := verificationErrorReason(test.err); got != test.want {
+				t.Errorf("verificationErrorReason() = %s, wanted %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecordVerificationErrors(t *testing.T) {
+	metrics.InitForTesting()
+	resetMetrics()
+	ctx := context.Background()
+
+	// Images denied for the same reason are counted once per admission.
+	recordVerificationErrors(ctx, map[string]bool{reasonRekorError: true, reasonNoValidSignature: true})
+	metricstest.CheckCountData(t, rekorErrorsName, map[string]string{}, 1)
+	metricstest.CheckStatsNotReported(t, registryErrorsName)
+}
+
+func TestRecordAdmission(t *testing.T) {
+	metrics.InitForTesting()
+	resetMetrics()
+	ctx := context.Background()
+
+	recordAdmission(ctx, "default", decisionDenied, reasonNotDigest)
+	recordAdmission(ctx, "default", decisionDenied, reasonNotDigest)
+	recordVerification(ctx, decisionAllowed, 20*time.Millisecond)
+
+	metricstest.CheckCountData(t, admissionsName, map[string]string{
+		"namespace": "default",
+		"decision":  decisionDenied,
+		"reason":    reasonNotDigest,
+	}, 2)
+	metricstest.CheckDistributionData(t, verificationLatencyName, map[string]string{"decision": decisionAllowed}, 1, 20, 20)
+}
@@ -17,12 +17,12 @@ package webhook
 
 import (
 	"context"
-	"crypto"
 	"crypto/ecdsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"knative.dev/pkg/apis"
@@ -30,27 +30,33 @@ import (
 
 	"github.com/sigstore/cosign/cmd/cosign/cli/fulcio/fulcioroots"
 	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/kubernetes"
 	"github.com/sigstore/cosign/pkg/oci"
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	sigs "github.com/sigstore/cosign/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/signature"
 )
 
-func valid(ctx context.Context, ref name.Reference, keys []*ecdsa.PublicKey, opts ...ociremote.Option) error {
+// valid returns the authority that signed ref: the ID of the public key
+// among keys that verified one of its signatures, or if there are no keys,
+// the subject of the certificate of a signature verified against the Fulcio
+// roots.
+func valid(ctx context.Context, ref name.Reference, keys []*ecdsa.PublicKey, opts ...ociremote.Option) (string, error) {
 	if len(keys) == 0 {
 		// If there are no keys, then verify against the fulcio root.
 		sps, err := validSignatures(ctx, ref, nil /* verifier */, opts...)
 		if err != nil {
-			return err
+			return "", err
 		}
 		if len(sps) > 0 {
-			return nil
+			return certAuthority(sps[0]), nil
 		}
-		return errors.New("no valid signatures were found")
+		return "", errors.New("no valid signatures were found")
 	}
 	// We return nil if ANY key matches
 	var lastErr error
 	for _, k := range keys {
-		verifier, err := signature.LoadECDSAVerifier(k, crypto.SHA256)
+		verifier, err := cosign.LoadVerifier(k)
 		if err != nil {
 			logging.FromContext(ctx).Errorf("error creating verifier: %v", err)
 			lastErr = err
@@ -64,24 +70,33 @@ func valid(ctx context.Context, ref name.Reference, keys []*ecdsa.PublicKey, opt
 			continue
 		}
 		if len(sps) > 0 {
-			return nil
+			return cosign.KeyID(k), nil
 		}
 	}
 	logging.FromContext(ctx).Debug("No valid signatures were found.")
-	return lastErr
+	return "", lastErr
+}
+
+// certAuthority returns the subject of the certificate of sig.
+func certAuthority(sig oci.Signature) string {
+	cert, err := sig.Cert()
+	if err != nil || cert == nil {
+		return ""
+	}
+	return sigs.CertSubject(cert)
 }
 
 // For testing
 var cosignVerifySignatures = cosign.VerifyImageSignatures
 
 func validSignatures(ctx context.Context, ref name.Reference, verifier signature.Verifier, opts ...ociremote.Option) ([]oci.Signature, error) {
-	sigs, _, err := cosignVerifySignatures(ctx, ref, &cosign.CheckOpts{
+	sps, _, err := cosignVerifySignatures(ctx, ref, &cosign.CheckOpts{
 		RegistryClientOpts: opts,
 		RootCerts:          fulcioroots.Get(),
 		SigVerifier:        verifier,
 		ClaimVerifier:      cosign.SimpleClaimVerifier,
 	})
-	return sigs, err
+	return sps, err
 }
 
 func getKeys(ctx context.Context, cfg map[string][]byte) ([]*ecdsa.PublicKey, *apis.FieldError) {
@@ -90,7 +105,12 @@ func getKeys(ctx context.Context, cfg map[string][]byte) ([]*ecdsa.PublicKey, *a
 
 	logging.FromContext(ctx).Debugf("Got public key: %v", cfg["cosign.pub"])
 
-	pems := parsePems(cfg["cosign.pub"])
+	// Accept the keys cosign.pub was rotated from until they expire.
+	pub, err := kubernetes.PublicKeys(cfg, time.Now())
+	if err != nil {
+		return nil, apis.ErrGeneric(fmt.Sprintf("malformed rotated keys: %v", err), apis.CurrentField)
+	}
+	pems := parsePems(pub)
 	for _, p := range pems {
 		// TODO: (@dlorenc) check header
 		key, err := x509.ParsePKIXPublicKey(p.Bytes)
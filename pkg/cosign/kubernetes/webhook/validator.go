@@ -18,6 +18,7 @@ package webhook
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn/k8schain"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -104,19 +105,44 @@ func (v *Validator) ValidateCronJob(ctx context.Context, c *duckv1.CronJob) *api
 }
 
 func (v *Validator) validatePodSpec(ctx context.Context, ps *corev1.PodSpec, opt k8schain.Options) (errs *apis.FieldError) {
+	// The reason for the admission decision: the reason the first denied
+	// image was denied, if any.
+	reason := reasonVerified
+	// The reasons all denied images were denied for.
+	reasons := map[string]bool{}
+	deny := func(r string) {
+		if reason == reasonVerified {
+			reason = r
+		}
+		reasons[r] = true
+	}
+	defer func() {
+		decision := decisionAllowed
+		if errs != nil {
+			decision = decisionDenied
+		}
+		recordAdmission(ctx, opt.Namespace, decision, reason)
+		recordVerificationErrors(ctx, reasons)
+	}()
+
 	kc, err := k8schain.New(ctx, v.client, opt)
 	if err != nil {
 		logging.FromContext(ctx).Warnf("Unable to build k8schain: %v", err)
+		deny(reasonKeychain)
 		return apis.ErrGeneric(err.Error(), apis.CurrentField)
 	}
 
 	s, err := v.lister.Secrets(system.Namespace()).Get(v.secretName)
 	if err != nil {
+		recordKeyLoadFailure(ctx)
+		deny(reasonKeyLoad)
 		return apis.ErrGeneric(err.Error(), apis.CurrentField)
 	}
 
 	keys, kerr := getKeys(ctx, s.Data)
 	if kerr != nil {
+		recordKeyLoadFailure(ctx)
+		deny(reasonKeyLoad)
 		return kerr
 	}
 
@@ -124,13 +150,18 @@ func (v *Validator) validatePodSpec(ctx context.Context, ps *corev1.PodSpec, opt
 		for i, c := range cs {
 			ref, err := name.ParseReference(c.Image)
 			if err != nil {
+				deny(reasonInvalidReference)
+				v.logDecision(ctx, opt.Namespace, c.Image, "", decisionDenied, reasonInvalidReference, "", err)
 				errs = errs.Also(apis.ErrGeneric(err.Error(), "image").ViaFieldIndex(field, i))
 				continue
 			}
 
 			// Require digests, otherwise the validation is meaningless
 			// since the tag can move.
-			if _, ok := ref.(name.Digest); !ok {
+			digest, ok := ref.(name.Digest)
+			if !ok {
+				deny(reasonNotDigest)
+				v.logDecision(ctx, opt.Namespace, c.Image, "", decisionDenied, reasonNotDigest, "", nil)
 				errs = errs.Also(apis.ErrInvalidValue(
 					fmt.Sprintf("%s must be an image digest", c.Image),
 					"image",
@@ -138,12 +169,20 @@ func (v *Validator) validatePodSpec(ctx context.Context, ps *corev1.PodSpec, opt
 				continue
 			}
 
-			if err := valid(ctx, ref, keys, ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(kc))); err != nil {
+			start := time.Now()
+			authority, err := valid(ctx, ref, keys, ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(kc)))
+			if err != nil {
+				recordVerification(ctx, decisionDenied, time.Since(start))
+				r := verificationErrorReason(err)
+				deny(r)
+				v.logDecision(ctx, opt.Namespace, c.Image, digest.DigestStr(), decisionDenied, r, "", err)
 				errorField := apis.ErrGeneric(err.Error(), "image").ViaFieldIndex(field, i)
 				errorField.Details = c.Image
 				errs = errs.Also(errorField)
 				continue
 			}
+			recordVerification(ctx, decisionAllowed, time.Since(start))
+			v.logDecision(ctx, opt.Namespace, c.Image, digest.DigestStr(), decisionAllowed, reasonVerified, authority, nil)
 		}
 	}
 
@@ -153,6 +192,25 @@ func (v *Validator) validatePodSpec(ctx context.Context, ps *corev1.PodSpec, opt
 	return errs
 }
 
+// logDecision logs the admission decision for an image, for auditing: the
+// authority that signed it if it was allowed, the reason it was denied
+// otherwise, and the policy, i.e. the secret holding the public keys.
+func (v *Validator) logDecision(ctx context.Context, namespace, image, digest, decision, reason, authority string, err error) {
+	kvs := []interface{}{
+		"namespace", namespace,
+		"image", image,
+		"digest", digest,
+		"decision", decision,
+		"reason", reason,
+		"authority", authority,
+		"policy", v.secretName,
+	}
+	if err != nil {
+		kvs = append(kvs, "error", err.Error())
+	}
+	logging.FromContext(ctx).Infow("Admission decision", kvs...)
+}
+
 // ResolvePodSpecable implements duckv1.PodSpecValidator
 func (v *Validator) ResolvePodSpecable(ctx context.Context, wp *duckv1.WithPod) {
 	if wp.DeletionTimestamp != nil {
@@ -0,0 +1,193 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+// The extensions of the PIV slot attestation certificates issued by YubiKeys,
+// see https://developers.yubico.com/PIV/Introduction/PIV_attestation.html
+var (
+	pivExtFirmwareVersion = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 41482, 3, 3}
+	pivExtSerialNumber    = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 41482, 3, 7}
+	pivExtKeyPolicy       = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 41482, 3, 8}
+	pivExtFormFactor      = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 41482, 3, 9}
+)
+
+var (
+	pivPINPolicies   = map[byte]string{0x01: "never", 0x02: "once", 0x03: "always"}
+	pivTouchPolicies = map[byte]string{0x01: "never", 0x02: "always", 0x03: "cached"}
+)
+
+// PIVAttestationOpts are the requirements on the PIV attestation of the key
+// a signature was made with.
+type PIVAttestationOpts struct {
+	// Roots are the manufacturer CA certificates the device attestation
+	// certificate must chain to.
+	Roots *x509.CertPool
+	// PINPolicies, if set, are the accepted PIN policies of the key: never, once or always.
+	PINPolicies []string
+	// TouchPolicies, if set, are the accepted touch policies of the key: never, always or cached.
+	TouchPolicies []string
+}
+
+// PIVAttestation is what the attestation of a PIV slot proves about the key it holds.
+type PIVAttestation struct {
+	// Serial is the serial number of the device.
+	Serial uint32
+	// Version is the firmware version of the device.
+	Version string
+	// FormFactor is the form factor code of the device.
+	FormFactor byte
+	// PINPolicy is the PIN policy of the key: never, once or always.
+	PINPolicy string
+	// TouchPolicy is the touch policy of the key: never, always or cached.
+	TouchPolicy string
+}
+
+// PIVAttestationChain returns the PEM encoded slot attestation certificate
+// followed by the device attestation certificate, as attached to signatures.
+func PIVAttestationChain(slotCert, deviceCert *x509.Certificate) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, c := range []*x509.Certificate{slotCert, deviceCert} {
+		pem, err := cryptoutils.MarshalCertificateToPEM(c)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(pem)
+	}
+	return buf.Bytes(), nil
+}
+
+// VerifyPIVAttestation verifies that the key pub, which made sig, was
+// generated on a PIV device chaining to opts.Roots, with an accepted PIN and
+// touch policy.
+func VerifyPIVAttestation(sig oci.Signature, pub crypto.PublicKey, opts *PIVAttestationOpts) (*PIVAttestation, error) {
+	annotations, err := sig.Annotations()
+	if err != nil {
+		return nil, err
+	}
+	chain, ok := annotations[static.PIVAttestationAnnotationKey]
+	if !ok {
+		return nil, errors.New("signature has no PIV attestation")
+	}
+	certs, err := cryptoutils.LoadCertificatesFromPEM(strings.NewReader(chain))
+	if err != nil {
+		return nil, errors.Wrap(err, "loading PIV attestation")
+	}
+	if len(certs) != 2 {
+		return nil, fmt.Errorf("expected a slot and a device attestation certificate, got %d certificates", len(certs))
+	}
+	slotCert, deviceCert := certs[0], certs[1]
+
+	// Some YubiKey 4 device attestation certificates lack basic constraints
+	// while being used as intermediates.
+	if !deviceCert.BasicConstraintsValid {
+		deviceCert.BasicConstraintsValid = true
+		deviceCert.IsCA = true
+	}
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(deviceCert)
+	if _, err := slotCert.Verify(x509.VerifyOptions{
+		Roots:         opts.Roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, errors.Wrap(err, "verifying PIV attestation")
+	}
+
+	attested, err := x509.MarshalPKIXPublicKey(slotCert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	signing, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(attested, signing) {
+		return nil, errors.New("PIV attestation is for another key than the signing key")
+	}
+
+	att, err := parsePIVAttestation(slotCert)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.PINPolicies) > 0 && !containsString(opts.PINPolicies, att.PINPolicy) {
+		return nil, fmt.Errorf("PIN policy %q of the signing key is not one of %v", att.PINPolicy, opts.PINPolicies)
+	}
+	if len(opts.TouchPolicies) > 0 && !containsString(opts.TouchPolicies, att.TouchPolicy) {
+		return nil, fmt.Errorf("touch policy %q of the signing key is not one of %v", att.TouchPolicy, opts.TouchPolicies)
+	}
+	return att, nil
+}
+
+func parsePIVAttestation(slotCert *x509.Certificate) (*PIVAttestation, error) {
+	att := &PIVAttestation{}
+	for _, ext := range slotCert.Extensions {
+		switch {
+		case ext.Id.Equal(pivExtFirmwareVersion):
+			if len(ext.Value) != 3 {
+				return nil, fmt.Errorf("invalid PIV firmware version %x", ext.Value)
+			}
+			att.Version = fmt.Sprintf("%d.%d.%d", ext.Value[0], ext.Value[1], ext.Value[2])
+		case ext.Id.Equal(pivExtSerialNumber):
+			var serial int64
+			if _, err := asn1.Unmarshal(ext.Value, &serial); err != nil || serial < 0 {
+				return nil, fmt.Errorf("invalid PIV serial number %x", ext.Value)
+			}
+			att.Serial = uint32(serial)
+		case ext.Id.Equal(pivExtKeyPolicy):
+			if len(ext.Value) != 2 {
+				return nil, fmt.Errorf("invalid PIV key policy %x", ext.Value)
+			}
+			att.PINPolicy = pivPINPolicies[ext.Value[0]]
+			att.TouchPolicy = pivTouchPolicies[ext.Value[1]]
+			if att.PINPolicy == "" || att.TouchPolicy == "" {
+				return nil, fmt.Errorf("unknown PIV key policy %x", ext.Value)
+			}
+		case ext.Id.Equal(pivExtFormFactor):
+			if len(ext.Value) != 1 {
+				return nil, fmt.Errorf("invalid PIV form factor %x", ext.Value)
+			}
+			att.FormFactor = ext.Value[0]
+		}
+	}
+	if att.PINPolicy == "" {
+		return nil, errors.New("PIV attestation has no key policy")
+	}
+	return att, nil
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/sigstore/cosign/test"
+)
+
+// The Yubico PIV root CA, and the attestations of a YubiKey and of the key in its
+// 9c slot, as printed by `cosign piv-tool attestation`.
+const (
+	yubicoPIVRootCA = `-----BEGIN CERTIFICATE-----
+MIIDFzCCAf+gAwIBAgIDBAZHMA0GCSqGSIb3DQEBCwUAMCsxKTAnBgNVBAMMIFl1
+YmljbyBQSVYgUm9vdCBDQSBTZXJpYWwgMjYzNzUxMCAXDTE2MDMxNDAwMDAwMFoY
+DzIwNTIwNDE3MDAwMDAwWjArMSkwJwYDVQQDDCBZdWJpY28gUElWIFJvb3QgQ0Eg
+U2VyaWFsIDI2Mzc1MTCCASIwDQYJKoZIhvcNAQEBBQADggEPADCCAQoCggEBAMN2
+cMTNR6YCdcTFRxuPy31PabRn5m6pJ+nSE0HRWpoaM8fc8wHC+Tmb98jmNvhWNE2E
+ilU85uYKfEFP9d6Q2GmytqBnxZsAa3KqZiCCx2LwQ4iYEOb1llgotVr/whEpdVOq
+joU0P5e1j1y7OfwOvky/+AXIN/9Xp0VFlYRk2tQ9GcdYKDmqU+db9iKwpAzid4oH
+BVLIhmD3pvkWaRA2H3DA9t7H/HNq5v3OiO1jyLZeKqZoMbPObrxqDg+9fOdShzgf
+wCqgT3XVmTeiwvBSTctyi9mHQfYd2DwkaqxRnLbNVyK9zl+DzjSGp9IhVPiVtGet
+X02dxhQnGS7K6BO0Qe8CAwEAAaNCMEAwHQYDVR0OBBYEFMpfyvLEojGc6SJf8ez0
+1d8Cv4O/MA8GA1UdEwQIMAYBAf8CAQEwDgYDVR0PAQH/BAQDAgEGMA0GCSqGSIb3
+DQEBCwUAA4IBAQBc7Ih8Bc1fkC+FyN1fhjWioBCMr3vjneh7MLbA6kSoyWF70N3s
+XhbXvT4eRh0hvxqvMZNjPU/VlRn6gLVtoEikDLrYFXN6Hh6Wmyy1GTnspnOvMvz2
+lLKuym9KYdYLDgnj3BeAvzIhVzzYSeU77/Cupofj093OuAswW0jYvXsGTyix6B3d
+bW5yWvyS9zNXaqGaUmP3U9/b6DlHdDogMLu3VLpBB9bm5bjaKWWJYgWltCVgUbFq
+Fqyi4+JE014cSgR57Jcu3dZiehB6UtAPgad9L5cNvua/IWRmm+ANy3O2LH++Pyl8
+SREzU8onbBsjMg9QDiSf5oJLKvd/Ren+zGY7
+-----END CERTIFICATE-----`
+	yubiKeyDeviceAttestation = `-----BEGIN CERTIFICATE-----
+MIIC+jCCAeKgAwIBAgIJAJDjrwcvIYiiMA0GCSqGSIb3DQEBCwUAMCsxKTAnBgNV
+BAMMIFl1YmljbyBQSVYgUm9vdCBDQSBTZXJpYWwgMjYzNzUxMCAXDTE2MDMxNDAw
+MDAwMFoYDzIwNTIwNDE3MDAwMDAwWjAhMR8wHQYDVQQDDBZZdWJpY28gUElWIEF0
+dGVzdGF0aW9uMIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEAyS4ANsMp
+RQA9cigP1oUG8yQ8tQkel2IergXvY9WSYy/muj30exFWXvO323i9RaQtoT7hOS5d
+SsH1hNvSTD56fIaKpg+8jHsQLM6mF2Jo0Kb4rBduYNi+waFbGcwgrmRX1d9NcYb6
+UDJt0o0RW6aGPY6wqUvMlIj0EwNIN7Ct1wSjIdL1qFmyVwUkQkPDd/0jDv7giE0P
+M36qISQ6U8t2jNg5aWDEjf7wwWTIiMjbv0FaaiL5Vqmc7WboofKZN5nQyWGAtAtz
+jTXzSkBfNPDO1eAUgbCbmu5efD8WeAtiPQyz8zQDU5UyihmDUEF1Dgr9/QMtQ5bd
+Z+FkBTtBYFp4aQIDAQABoykwJzARBgorBgEEAYLECgMDBAMFAgYwEgYDVR0TAQH/
+BAgwBgEB/wIBADANBgkqhkiG9w0BAQsFAAOCAQEAQutaY0Wf/o2MPyRmsMM1QQuX
+JI1ncaiDczWpFGj8YFUqlwLsEgYMzzGMrgPHIyE+CCgbYfyJu2mGU7goEHFq2/Ky
+i8mjJtk/nVMF/m+dD7zbLvXPU0f9BKdpm1LUjC/YscvkFuI+sFrZvk8e1DAM49D5
+Dm3MsEw9KjGhhTSv8iMoz9QMN7O1ozfsLTkj5eJQFEzkeUtgPxoJVnJqd4JkqnhF
+ZoN7tG+9N6wouG5pCzOJDgraGwow11UdcheQze2SVktYcRdWVgr86YBiYdfAzkLz
+FN4tXEiGuQyX6gWKBdd91niHF27RIWNGuz6X9KzMwgJ374n2ld8BiLg9PU30xA==
+-----END CERTIFICATE-----`
+	yubiKeySlotAttestation = `-----BEGIN CERTIFICATE-----
+MIICVTCCAT2gAwIBAgIQARF+TvIOm46Oc+FF3+YHITANBgkqhkiG9w0BAQsFADAh
+MR8wHQYDVQQDDBZZdWJpY28gUElWIEF0dGVzdGF0aW9uMCAXDTE2MDMxNDAwMDAw
+MFoYDzIwNTIwNDE3MDAwMDAwWjAlMSMwIQYDVQQDDBpZdWJpS2V5IFBJViBBdHRl
+c3RhdGlvbiA5YzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABBG2R72YMY5KlpWP
+OuQj5ZgKoyyusj4S/uzKMSNyy5jv4DoR/EOnpVXAGSYAFGWPhl5TdLtf02rSPrHc
+NgxEE4ejTjBMMBEGCisGAQQBgsQKAwMEAwUCBjAUBgorBgEEAYLECgMHBAYCBADH
+kP4wEAYKKwYBBAGCxAoDCAQCAwIwDwYKKwYBBAGCxAoDCQQBAzANBgkqhkiG9w0B
+AQsFAAOCAQEAeT5EXMm1PfVImtFinOPUsVY4tq2mPaZQ67//OiPisuSaF90YJIRJ
+PyndeKHDpscFwN1h8XhACb6e6XAyswB//qMdt+2VEeJCFatcuUHki4Vb8plRkZNU
+IDTbnZ3TnqY9eH4POmbHS9MmsDJPBFqCAvbX4hgHOiYmpim2tf4U562LMzpYU44c
+rb9ZMlAhjlOHgft02Gduv2DK1THfUacMYR1L0p9WgCaRKAlAWsvyl3Xmfjf3NRJT
+gzHKg/sREq1fns6kff5rj0kqZhuuhSYfOrhS3pRbMOEcKksymBwYbQpEgJYJndwO
+uCPMJZqsNyWMmfksjulR9XAQvBCImkXncw==
+-----END CERTIFICATE-----`
+)
+
+// pivSlotCert issues a slot attestation certificate for pub from the device
+// certificate, as a YubiKey does.
+func pivSlotCert(t *testing.T, pub crypto.PublicKey, device *x509.Certificate, devicePriv crypto.Signer, policy []byte) *x509.Certificate {
+	t.Helper()
+	serial, err := asn1.Marshal(int64(12345678))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "YubiKey PIV Attestation 9c"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{
+			{Id: pivExtFirmwareVersion, Value: []byte{5, 4, 3}},
+			{Id: pivExtSerialNumber, Value: serial},
+			{Id: pivExtKeyPolicy, Value: policy},
+			{Id: pivExtFormFactor, Value: []byte{0x03}},
+		},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, device, pub, devicePriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestVerifyPIVAttestation(t *testing.T) {
+	rootCert, rootPriv, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deviceCert, devicePriv, err := test.GenerateSubordinateCa(rootCert, rootPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCert)
+	otherRoot, _, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRoots := x509.NewCertPool()
+	otherRoots.AddCert(otherRoot)
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"critical":{}}`)
+	sigBytes, err := sv.SignMessage(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b64sig := base64.StdEncoding.EncodeToString(sigBytes)
+
+	signed := func(pub crypto.PublicKey, policy []byte) oci.Signature {
+		t.Helper()
+		chain, err := PIVAttestationChain(pivSlotCert(t, pub, deviceCert, devicePriv, policy), deviceCert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := static.NewSignature(payload, b64sig, static.WithAnnotations(map[string]string{
+			static.PIVAttestationAnnotationKey: string(chain),
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+	unattested, err := static.NewSignature(payload, b64sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		sig     oci.Signature
+		opts    PIVAttestationOpts
+		wantErr bool
+	}{{
+		name: "attested",
+		sig:  signed(&priv.PublicKey, []byte{0x02, 0x02}),
+		opts: PIVAttestationOpts{Roots: roots},
+	}, {
+		name: "accepted policies",
+		sig:  signed(&priv.PublicKey, []byte{0x03, 0x03}),
+		opts: PIVAttestationOpts{Roots: roots, PINPolicies: []string{"once", "always"}, TouchPolicies: []string{"always", "cached"}},
+	}, {
+		name:    "rejected PIN policy",
+		sig:     signed(&priv.PublicKey, []byte{0x01, 0x02}),
+		opts:    PIVAttestationOpts{Roots: roots, PINPolicies: []string{"once", "always"}},
+		wantErr: true,
+	}, {
+		name:    "rejected touch policy",
+		sig:     signed(&priv.PublicKey, []byte{0x02, 0x01}),
+		opts:    PIVAttestationOpts{Roots: roots, TouchPolicies: []string{"always"}},
+		wantErr: true,
+	}, {
+		name:    "untrusted manufacturer",
+		sig:     signed(&priv.PublicKey, []byte{0x02, 0x02}),
+		opts:    PIVAttestationOpts{Roots: otherRoots},
+		wantErr: true,
+	}, {
+		name:    "attestation of another key",
+		sig:     signed(&otherPriv.PublicKey, []byte{0x02, 0x02}),
+		opts:    PIVAttestationOpts{Roots: roots},
+		wantErr: true,
+	}, {
+		name:    "no attestation",
+		sig:     unattested,
+		opts:    PIVAttestationOpts{Roots: roots},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyImageSignature(context.Background(), tt.sig, v1.Hash{}, &CheckOpts{
+				SigVerifier:    sv,
+				PIVAttestation: &tt.opts,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyImageSignature() = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+
+	att, err := VerifyPIVAttestation(signed(&priv.PublicKey, []byte{0x03, 0x02}), &priv.PublicKey, &PIVAttestationOpts{Roots: roots})
+	if err != nil {
+		t.Fatalf("VerifyPIVAttestation() = %v", err)
+	}
+	want := PIVAttestation{Serial: 12345678, Version: "5.4.3", FormFactor: 0x03, PINPolicy: "always", TouchPolicy: "always"}
+	if *att != want {
+		t.Errorf("VerifyPIVAttestation() = %+v, wanted %+v", *att, want)
+	}
+}
+
+func TestVerifyYubiKeyAttestation(t *testing.T) {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(yubicoPIVRootCA)) {
+		t.Fatal("invalid Yubico PIV root CA")
+	}
+	slotCerts, err := cryptoutils.UnmarshalCertificatesFromPEM([]byte(yubiKeySlotAttestation))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := static.NewSignature(nil, "", static.WithAnnotations(map[string]string{
+		static.PIVAttestationAnnotationKey: yubiKeySlotAttestation + "\n" + yubiKeyDeviceAttestation,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	att, err := VerifyPIVAttestation(sig, slotCerts[0].PublicKey, &PIVAttestationOpts{Roots: roots})
+	if err != nil {
+		t.Fatalf("VerifyPIVAttestation() = %v", err)
+	}
+	want := PIVAttestation{Serial: 13078782, Version: "5.2.6", FormFactor: 0x03, PINPolicy: "always", TouchPolicy: "always"}
+	if *att != want {
+		t.Errorf("VerifyPIVAttestation() = %+v, wanted %+v", *att, want)
+	}
+}
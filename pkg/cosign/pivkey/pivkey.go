@@ -33,6 +33,7 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/term"
 
+	"github.com/sigstore/cosign/pkg/cosign"
 	"github.com/sigstore/sigstore/pkg/signature"
 )
 
@@ -164,29 +165,30 @@ func (k *Key) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, er
 	return k.Pub, nil
 }
 
-func (k *Key) VerifySignature(signature, message io.Reader, opts ...signature.VerifyOption) error {
-	sig, err := io.ReadAll(signature)
+func (k *Key) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	sigBytes, err := io.ReadAll(sig)
 	if err != nil {
 		return errors.Wrap(err, "read signature")
 	}
-	msg, err := io.ReadAll(message)
-	if err != nil {
-		return errors.Wrap(err, "read message")
-	}
-	digest := sha256.Sum256(msg)
-
 	att, err := k.Attest()
 	if err != nil {
 		return errors.Wrap(err, "get attestation")
 	}
+	// This streams the message, unless the caller already supplied its digest.
+	hashAlgorithm := cosign.HashAlgorithmForKey(att.PublicKey)
+	digest, _, err := signature.ComputeDigestForVerifying(message, hashAlgorithm, []crypto.Hash{hashAlgorithm}, opts...)
+	if err != nil {
+		return errors.Wrap(err, "read message")
+	}
+
 	switch kt := att.PublicKey.(type) {
 	case *ecdsa.PublicKey:
-		if ecdsa.VerifyASN1(kt, digest[:], sig) {
+		if ecdsa.VerifyASN1(kt, digest, sigBytes) {
 			return nil
 		}
 		return errors.New("invalid ecdsa signature")
 	case *rsa.PublicKey:
-		return rsa.VerifyPKCS1v15(kt, crypto.SHA256, digest[:], sig)
+		return rsa.VerifyPKCS1v15(kt, hashAlgorithm, digest, sigBytes)
 	}
 
 	return fmt.Errorf("unsupported key type: %T", att.PublicKey)
@@ -269,11 +271,13 @@ func (k *Key) Sign(ctx context.Context, rawPayload []byte) ([]byte, []byte, erro
 
 func (k *Key) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
 	signer := k.Priv.(crypto.Signer)
-	h := sha256.New()
-	if _, err := io.Copy(h, message); err != nil {
+	// This streams the message, unless the caller already supplied its digest.
+	hashAlgorithm := cosign.HashAlgorithmForKey(signer.Public())
+	digest, _, err := signature.ComputeDigestForSigning(message, hashAlgorithm, []crypto.Hash{hashAlgorithm}, opts...)
+	if err != nil {
 		return nil, err
 	}
-	sig, err := signer.Sign(rand.Reader, h.Sum(nil), crypto.SHA256)
+	sig, err := signer.Sign(rand.Reader, digest, hashAlgorithm)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +37,14 @@ func GetKeyWithURIConfig(config *Pkcs11UriConfig, askForPinIfNeeded bool) (*Key,
 	return nil, errors.New("unimplemented")
 }
 
+func GenerateKeyWithURIConfig(config *Pkcs11UriConfig, algorithm string, askForPinIfNeeded bool) (*Key, error) {
+	return nil, errors.New("unimplemented")
+}
+
+func (k *Key) ImportCertificate(cert *x509.Certificate, replace bool) error {
+	return errors.New("unimplemented")
+}
+
 func (k *Key) Certificate() (*x509.Certificate, error) {
 	return nil, errors.New("unimplemented")
 }
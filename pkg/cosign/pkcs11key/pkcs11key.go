@@ -18,9 +18,11 @@
 package pkcs11key
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
@@ -33,6 +35,7 @@ import (
 	"github.com/ThalesIgnite/crypto11"
 	"github.com/miekg/pkcs11"
 	"github.com/pkg/errors"
+	"github.com/sigstore/cosign/pkg/cosign"
 	"github.com/sigstore/sigstore/pkg/signature"
 	"golang.org/x/term"
 )
@@ -50,16 +53,48 @@ type Key struct {
 }
 
 func GetKeyWithURIConfig(config *Pkcs11UriConfig, askForPinIfNeeded bool) (*Key, error) {
-	conf := &crypto11.Config{
-		Path: config.ModulePath,
-		Pin:  config.Pin,
-	}
-
 	// At least one of object and id must be specified.
 	if len(config.KeyLabel) == 0 && len(config.KeyID) == 0 {
 		return nil, errors.New("one of keyLabel and keyID must be set")
 	}
 
+	ctx, err := configure(config, askForPinIfNeeded)
+	if err != nil {
+		return nil, err
+	}
+
+	// If both keyID and keyLabel are set, keyID has priority.
+	var signer crypto11.Signer
+	if len(config.KeyID) != 0 {
+		signer, err = ctx.FindKeyPair(config.KeyID, nil)
+	} else if len(config.KeyLabel) != 0 {
+		signer, err = ctx.FindKeyPair(nil, config.KeyLabel)
+	}
+	if err != nil {
+		ctx.Close()
+		return nil, err
+	}
+
+	// Key's corresponding cert might not exist,
+	// therefore, we do not fail if it is the case.
+	var cert *x509.Certificate
+	if len(config.KeyID) != 0 {
+		cert, _ = ctx.FindCertificate(config.KeyID, nil, nil)
+	} else if len(config.KeyLabel) != 0 {
+		cert, _ = ctx.FindCertificate(nil, config.KeyLabel, nil)
+	}
+
+	return &Key{ctx: ctx, signer: signer, cert: cert}, nil
+}
+
+// configure opens the token config refers to, asking for its PIN if needed
+// and askForPinIfNeeded is set.
+func configure(config *Pkcs11UriConfig, askForPinIfNeeded bool) (*crypto11.Context, error) {
+	conf := &crypto11.Config{
+		Path: config.ModulePath,
+		Pin:  config.Pin,
+	}
+
 	// At least one of token and slot-id must be specified.
 	if config.TokenLabel == "" && config.SlotID == nil {
 		return nil, errors.New("one of token and slot id must be set")
@@ -154,32 +189,127 @@ func GetKeyWithURIConfig(config *Pkcs11UriConfig, askForPinIfNeeded bool) (*Key,
 		conf.TokenLabel = config.TokenLabel
 	}
 
-	ctx, err := crypto11.Configure(conf)
+	return crypto11.Configure(conf)
+}
+
+// GenerateKeyWithURIConfig generates a key pair of one of KeyAlgorithms in the
+// token config refers to, with the label and ID of config. A random ID is
+// generated and set in config if it has none.
+func GenerateKeyWithURIConfig(config *Pkcs11UriConfig, algorithm string, askForPinIfNeeded bool) (*Key, error) {
+	// At least one of object and id must be specified.
+	if len(config.KeyLabel) == 0 && len(config.KeyID) == 0 {
+		return nil, errors.New("one of keyLabel and keyID must be set")
+	}
+
+	ctx, err := configure(config, askForPinIfNeeded)
 	if err != nil {
 		return nil, err
 	}
 
-	// If both keyID and keyLabel are set, keyID has priority.
+	// Do not shadow an existing key, the URIs referring to it would become ambiguous.
+	for _, attrs := range [][2][]byte{{config.KeyID, nil}, {nil, config.KeyLabel}} {
+		if len(attrs[0]) == 0 && len(attrs[1]) == 0 {
+			continue
+		}
+		existing, err := ctx.FindKeyPair(attrs[0], attrs[1])
+		if err != nil {
+			ctx.Close()
+			return nil, err
+		}
+		if existing != nil {
+			ctx.Close()
+			return nil, errors.New("a key with this label or ID already exists in the PKCS11 token")
+		}
+	}
+
+	if len(config.KeyID) == 0 {
+		config.KeyID = make([]byte, 16)
+		if _, err := rand.Read(config.KeyID); err != nil {
+			ctx.Close()
+			return nil, err
+		}
+	}
+	label := config.KeyLabel
+	if label == nil {
+		label = []byte{}
+	}
+
 	var signer crypto11.Signer
-	if len(config.KeyID) != 0 {
-		signer, err = ctx.FindKeyPair(config.KeyID, nil)
-	} else if len(config.KeyLabel) != 0 {
-		signer, err = ctx.FindKeyPair(nil, config.KeyLabel)
+	switch algorithm {
+	case cosign.ECDSAP256:
+		signer, err = ctx.GenerateECDSAKeyPairWithLabel(config.KeyID, label, elliptic.P256())
+	case cosign.ECDSAP384:
+		signer, err = ctx.GenerateECDSAKeyPairWithLabel(config.KeyID, label, elliptic.P384())
+	case cosign.RSA2048:
+		signer, err = ctx.GenerateRSAKeyPairWithLabel(config.KeyID, label, 2048)
+	case cosign.RSA3072:
+		signer, err = ctx.GenerateRSAKeyPairWithLabel(config.KeyID, label, 3072)
+	case cosign.RSA4096:
+		signer, err = ctx.GenerateRSAKeyPairWithLabel(config.KeyID, label, 4096)
+	default:
+		err = fmt.Errorf("unsupported key algorithm %q, expected one of %v", algorithm, KeyAlgorithms)
 	}
 	if err != nil {
-		return nil, err
+		ctx.Close()
+		return nil, errors.Wrap(err, "generate key pair")
 	}
 
-	// Key's corresponding cert might not exist,
-	// therefore, we do not fail if it is the case.
-	var cert *x509.Certificate
-	if len(config.KeyID) != 0 {
-		cert, _ = ctx.FindCertificate(config.KeyID, nil, nil)
-	} else if len(config.KeyLabel) != 0 {
-		cert, _ = ctx.FindCertificate(nil, config.KeyLabel, nil)
+	return &Key{ctx: ctx, signer: signer}, nil
+}
+
+// ImportCertificate stores cert in the token, with the ID and label of the
+// key, which must be the key of cert. An existing certificate of the key is
+// only replaced if replace is set.
+func (k *Key) ImportCertificate(cert *x509.Certificate, replace bool) error {
+	if k.ctx == nil {
+		return ContextNotInitialized
+	}
+	if k.signer == nil {
+		return SignerNotSet
 	}
 
-	return &Key{ctx: ctx, signer: signer, cert: cert}, nil
+	certPub, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "marshal certificate public key")
+	}
+	keyPub, err := x509.MarshalPKIXPublicKey(k.signer.Public())
+	if err != nil {
+		return errors.Wrap(err, "marshal key public key")
+	}
+	if !bytes.Equal(certPub, keyPub) {
+		return errors.New("the certificate is not for this key")
+	}
+
+	attrs, err := k.ctx.GetAttributes(k.signer, []crypto11.AttributeType{crypto11.CkaId, crypto11.CkaLabel})
+	if err != nil {
+		return errors.Wrap(err, "get key attributes")
+	}
+	id, label := []byte{}, []byte{}
+	if a := attrs[crypto11.CkaId]; a != nil && a.Value != nil {
+		id = a.Value
+	}
+	if a := attrs[crypto11.CkaLabel]; a != nil && a.Value != nil {
+		label = a.Value
+	}
+
+	existing, err := k.ctx.FindCertificate(id, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "find certificate")
+	}
+	if existing != nil {
+		if !replace {
+			return errors.New("the key already has a certificate")
+		}
+		if err := k.ctx.DeleteCertificate(id, nil, nil); err != nil {
+			return errors.Wrap(err, "delete certificate")
+		}
+	}
+
+	if err := k.ctx.ImportCertificateWithLabel(id, label, cert); err != nil {
+		return errors.Wrap(err, "import certificate")
+	}
+	k.cert = cert
+	return nil
 }
 
 func (k *Key) Certificate() (*x509.Certificate, error) {
@@ -190,25 +320,26 @@ func (k *Key) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, er
 	return k.signer.Public(), nil
 }
 
-func (k *Key) VerifySignature(signature, message io.Reader, opts ...signature.VerifyOption) error {
-	sig, err := io.ReadAll(signature)
+func (k *Key) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	sigBytes, err := io.ReadAll(sig)
 	if err != nil {
 		return errors.Wrap(err, "read signature")
 	}
-	msg, err := io.ReadAll(message)
+	// This streams the message, unless the caller already supplied its digest.
+	hashAlgorithm := cosign.HashAlgorithmForKey(k.signer.Public())
+	digest, _, err := signature.ComputeDigestForVerifying(message, hashAlgorithm, []crypto.Hash{hashAlgorithm}, opts...)
 	if err != nil {
 		return errors.Wrap(err, "read message")
 	}
-	digest := sha256.Sum256(msg)
 
 	switch kt := k.signer.Public().(type) {
 	case *ecdsa.PublicKey:
-		if ecdsa.VerifyASN1(kt, digest[:], sig) {
+		if ecdsa.VerifyASN1(kt, digest, sigBytes) {
 			return nil
 		}
 		return errors.New("invalid ecdsa signature")
 	case *rsa.PublicKey:
-		return rsa.VerifyPKCS1v15(kt, crypto.SHA256, digest[:], sig)
+		return rsa.VerifyPKCS1v15(kt, hashAlgorithm, digest, sigBytes)
 	}
 
 	return fmt.Errorf("unsupported key type: %T", k.PublicKey)
@@ -234,11 +365,13 @@ func (k *Key) Sign(ctx context.Context, rawPayload []byte) ([]byte, []byte, erro
 }
 
 func (k *Key) SignMessage(message io.Reader, opts ...signature.SignOption) ([]byte, error) {
-	h := sha256.New()
-	if _, err := io.Copy(h, message); err != nil {
+	// This streams the message, unless the caller already supplied its digest.
+	hashAlgorithm := cosign.HashAlgorithmForKey(k.signer.Public())
+	digest, _, err := signature.ComputeDigestForSigning(message, hashAlgorithm, []crypto.Hash{hashAlgorithm}, opts...)
+	if err != nil {
 		return nil, err
 	}
-	sig, err := k.signer.Sign(rand.Reader, h.Sum(nil), crypto.SHA256)
+	sig, err := k.signer.Sign(rand.Reader, digest, hashAlgorithm)
 	if err != nil {
 		return nil, err
 	}
@@ -22,6 +22,8 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/pkg/cosign"
 )
 
 const (
@@ -79,6 +81,9 @@ func EncodeURIComponent(uriString string, isForPath bool, usePercentEncoding boo
 	return stringBuilder.String(), nil
 }
 
+// KeyAlgorithms lists the key algorithms supported by GenerateKeyWithURIConfig.
+var KeyAlgorithms = []string{cosign.ECDSAP256, cosign.ECDSAP384, cosign.RSA2048, cosign.RSA3072, cosign.RSA4096}
+
 type Pkcs11UriConfig struct {
 	uriPathAttributes  url.Values
 	uriQueryAttributes url.Values
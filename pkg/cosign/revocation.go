@@ -0,0 +1,331 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/pkg/cosign/tuf"
+	"github.com/sigstore/cosign/pkg/oci"
+	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
+	"github.com/sigstore/cosign/pkg/types"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+// RevocationList lists signatures that are no longer trusted, even though
+// they verify, e.g. because the key that made them leaked.
+//
+// A signed list stays valid until it expires, so verifiers refusing lists
+// issued before the one they last saw can't be served an older one that
+// revokes less.
+type RevocationList struct {
+	// Issued is when the list was made.
+	Issued time.Time `json:"issued"`
+	// Expires is when the list must be replaced by a newer one.
+	Expires time.Time `json:"expires"`
+	// Revocations are the revoked signatures.
+	Revocations []Revocation `json:"revocations"`
+}
+
+// Revocation revokes the signatures matching all its non-empty fields: made
+// with the key KeyID, or the certificate with the serial number CertSerial
+// or the subject Identity, or the signature SignatureDigest, and made after
+// SignedAfter and before SignedBefore.
+//
+// The time a signature was made is the integrated time of its verified
+// transparency log bundle or, failing that, the start of the validity of its
+// certificate. When it is unknown, e.g. for a signature made with a key and
+// not uploaded to the transparency log, revocations with SignedAfter or
+// SignedBefore revoke it regardless.
+type Revocation struct {
+	// KeyID is the hex encoded SHA-256 of the DER encoded public key.
+	KeyID string `json:"keyId,omitempty"`
+	// CertSerial is the hex encoded serial number of the certificate.
+	CertSerial string `json:"certSerial,omitempty"`
+	// Identity is an email address or URI subject of the certificate.
+	Identity string `json:"identity,omitempty"`
+	// SignatureDigest is the digest of the signature layer, as in sha256:<hex>.
+	SignatureDigest string `json:"signatureDigest,omitempty"`
+
+	SignedAfter  *time.Time `json:"signedAfter,omitempty"`
+	SignedBefore *time.Time `json:"signedBefore,omitempty"`
+
+	// Reason is reported when rejecting a revoked signature.
+	Reason string `json:"reason,omitempty"`
+}
+
+// SignedRevocationList is how revocation lists are distributed: a JSON
+// encoded RevocationList and its signature.
+type SignedRevocationList struct {
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// DefaultRevocationListExpiry is how long a revocation list is valid after
+// it is issued, unless it says otherwise.
+const DefaultRevocationListExpiry = 30 * 24 * time.Hour
+
+// RevocationListScheme* prefix the revocation list sources of
+// FetchRevocationList that aren't files.
+const (
+	RevocationListSchemeOCI = "oci://"
+	RevocationListSchemeTUF = "tuf://"
+)
+
+// Validate checks that the list has a validity period, and that every
+// revocation identifies what it revokes, so that a revocation with mistyped
+// fields doesn't revoke everything.
+func (rl *RevocationList) Validate() error {
+	if rl.Issued.IsZero() || rl.Expires.IsZero() {
+		return errors.New("revocation list must have issued and expires times")
+	}
+	if !rl.Expires.After(rl.Issued) {
+		return fmt.Errorf("revocation list expires at %s, before it is issued at %s", rl.Expires.Format(time.RFC3339), rl.Issued.Format(time.RFC3339))
+	}
+	for i, r := range rl.Revocations {
+		if r.KeyID == "" && r.CertSerial == "" && r.Identity == "" && r.SignatureDigest == "" {
+			return fmt.Errorf("revocation %d has none of keyId, certSerial, identity and signatureDigest", i)
+		}
+		if r.CertSerial != "" {
+			if _, ok := parseCertSerial(r.CertSerial); !ok {
+				return fmt.Errorf("revocation %d has an invalid certSerial %q", i, r.CertSerial)
+			}
+		}
+	}
+	return nil
+}
+
+// SignRevocationList validates rl and signs it with signer.
+func SignRevocationList(ctx context.Context, signer signature.Signer, rl *RevocationList) ([]byte, error) {
+	if err := rl.Validate(); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(rl)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(payload), options.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "signing revocation list")
+	}
+	return json.MarshalIndent(&SignedRevocationList{Payload: payload, Signature: sig}, "", "  ")
+}
+
+// VerifyRevocationList verifies the signature of a SignedRevocationList
+// with verifier, and returns the revocation list if it didn't expire and,
+// unless minIssued is zero, wasn't issued before minIssued.
+func VerifyRevocationList(ctx context.Context, verifier signature.Verifier, signed []byte, minIssued time.Time) (*RevocationList, error) {
+	srl := &SignedRevocationList{}
+	if err := json.Unmarshal(signed, srl); err != nil {
+		return nil, errors.Wrap(err, "parsing signed revocation list")
+	}
+	if err := verifier.VerifySignature(bytes.NewReader(srl.Signature), bytes.NewReader(srl.Payload), options.WithContext(ctx)); err != nil {
+		return nil, errors.Wrap(err, "verifying revocation list signature")
+	}
+	rl := &RevocationList{}
+	if err := json.Unmarshal(srl.Payload, rl); err != nil {
+		return nil, errors.Wrap(err, "parsing revocation list")
+	}
+	if err := rl.Validate(); err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(rl.Expires) {
+		return nil, fmt.Errorf("revocation list expired at %s", rl.Expires.Format(time.RFC3339))
+	}
+	if rl.Issued.Before(minIssued) {
+		return nil, fmt.Errorf("revocation list issued at %s, before the minimum of %s", rl.Issued.Format(time.RFC3339), minIssued.Format(time.RFC3339))
+	}
+	return rl, nil
+}
+
+// FetchRevocationList reads the SignedRevocationList at source:
+// oci://<image> for the layer of media type types.RevocationListMediaType
+// of an image, tuf://<target> for a target of the TUF repository, or else
+// the path of a file.
+func FetchRevocationList(ctx context.Context, source string, opts ...ociremote.Option) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(source, RevocationListSchemeOCI):
+		ref, err := name.ParseReference(strings.TrimPrefix(source, RevocationListSchemeOCI))
+		if err != nil {
+			return nil, err
+		}
+		return fetchRevocationListImage(ref, opts...)
+	case strings.HasPrefix(source, RevocationListSchemeTUF):
+		t, err := tuf.NewFromEnv(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer t.Close()
+		return t.GetTarget(strings.TrimPrefix(source, RevocationListSchemeTUF))
+	default:
+		return os.ReadFile(filepath.Clean(source))
+	}
+}
+
+func fetchRevocationListImage(ref name.Reference, opts ...ociremote.Option) ([]byte, error) {
+	img, err := ociremote.SignedImage(ref, opts...)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range layers {
+		mt, err := l.MediaType()
+		if err != nil {
+			return nil, err
+		}
+		if mt != types.RevocationListMediaType {
+			continue
+		}
+		rc, err := l.Uncompressed()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		var b bytes.Buffer
+		if _, err := b.ReadFrom(rc); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("no layer of media type %s in %s", types.RevocationListMediaType, ref)
+}
+
+// Revoked returns the revocation matching sig, made with pub at signedAt, a
+// zero signedAt meaning unknown, or nil if sig isn't revoked.
+func (rl *RevocationList) Revoked(sig oci.Signature, pub crypto.PublicKey, signedAt time.Time) (*Revocation, error) {
+	cert, err := sig.Cert()
+	if err != nil {
+		return nil, err
+	}
+	digest, err := sig.Digest()
+	if err != nil {
+		return nil, err
+	}
+	keyID := KeyID(pub)
+
+	for i := range rl.Revocations {
+		r := &rl.Revocations[i]
+		if r.KeyID != "" && !strings.EqualFold(r.KeyID, keyID) {
+			continue
+		}
+		if r.CertSerial != "" && !certSerialMatches(r.CertSerial, cert) {
+			continue
+		}
+		if r.Identity != "" && !certIdentityMatches(r.Identity, cert) {
+			continue
+		}
+		if r.SignatureDigest != "" && r.SignatureDigest != digest.String() {
+			continue
+		}
+		if !signedAt.IsZero() {
+			if r.SignedAfter != nil && signedAt.Before(*r.SignedAfter) {
+				continue
+			}
+			if r.SignedBefore != nil && !signedAt.Before(*r.SignedBefore) {
+				continue
+			}
+		}
+		return r, nil
+	}
+	return nil, nil
+}
+
+// checkRevocation rejects sig, verified with verifier, if co.RevocationList
+// revokes it. bundleVerified tells whether the integrated time of its bundle
+// can be trusted.
+func checkRevocation(sig oci.Signature, verifier signature.Verifier, bundleVerified bool, co *CheckOpts) error {
+	if co.RevocationList == nil {
+		return nil
+	}
+	pub, err := verifier.PublicKey(co.PKOpts...)
+	if err != nil {
+		return err
+	}
+	var signedAt time.Time
+	if bundleVerified {
+		b, err := sig.Bundle()
+		if err != nil {
+			return err
+		}
+		signedAt = time.Unix(b.Payload.IntegratedTime, 0)
+	} else {
+		cert, err := sig.Cert()
+		if err != nil {
+			return err
+		}
+		if cert != nil {
+			signedAt = cert.NotBefore
+		}
+	}
+	r, err := co.RevocationList.Revoked(sig, pub, signedAt)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return nil
+	}
+	if r.Reason != "" {
+		return fmt.Errorf("signature revoked: %s", r.Reason)
+	}
+	return errors.New("signature revoked")
+}
+
+func parseCertSerial(s string) (*big.Int, bool) {
+	s = strings.TrimPrefix(strings.ReplaceAll(strings.ToLower(s), ":", ""), "0x")
+	return new(big.Int).SetString(s, 16)
+}
+
+func certSerialMatches(serial string, cert *x509.Certificate) bool {
+	if cert == nil {
+		return false
+	}
+	n, ok := parseCertSerial(serial)
+	return ok && n.Cmp(cert.SerialNumber) == 0
+}
+
+func certIdentityMatches(identity string, cert *x509.Certificate) bool {
+	if cert == nil {
+		return false
+	}
+	for _, email := range cert.EmailAddresses {
+		if email == identity {
+			return true
+		}
+	}
+	for _, uri := range cert.URIs {
+		if uri.String() == identity {
+			return true
+		}
+	}
+	return false
+}
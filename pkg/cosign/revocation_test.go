@@ -0,0 +1,180 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sigstore/cosign/pkg/oci/static"
+	"github.com/sigstore/cosign/test"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func TestSignVerifyRevocationList(t *testing.T) {
+	ctx := context.Background()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	rl := &RevocationList{
+		Issued:      issued,
+		Expires:     issued.Add(24 * time.Hour),
+		Revocations: []Revocation{{KeyID: "abcd", Reason: "leaked"}},
+	}
+	signed, err := SignRevocationList(ctx, sv, rl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "revocations.json")
+	if err := os.WriteFile(path, signed, 0600); err != nil {
+		t.Fatal(err)
+	}
+	fetched, err := FetchRevocationList(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := VerifyRevocationList(ctx, sv, fetched, issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Revocations) != 1 || got.Revocations[0].Reason != "leaked" {
+		t.Errorf("VerifyRevocationList() = %+v, want %+v", got, rl)
+	}
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherVerifier, err := signature.LoadECDSAVerifier(&other.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyRevocationList(ctx, otherVerifier, signed, time.Time{}); err == nil {
+		t.Error("VerifyRevocationList() with another key succeeded")
+	}
+
+	// An older list can't replace one issued later.
+	if _, err := VerifyRevocationList(ctx, sv, signed, issued.Add(time.Second)); err == nil {
+		t.Error("VerifyRevocationList() of a list issued before the minimum succeeded")
+	}
+
+	expired, err := SignRevocationList(ctx, sv, &RevocationList{
+		Issued:      issued.Add(-48 * time.Hour),
+		Expires:     issued.Add(-24 * time.Hour),
+		Revocations: rl.Revocations,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyRevocationList(ctx, sv, expired, time.Time{}); err == nil {
+		t.Error("VerifyRevocationList() of an expired list succeeded")
+	}
+
+	if _, err := SignRevocationList(ctx, sv, &RevocationList{Issued: issued, Revocations: rl.Revocations}); err == nil {
+		t.Error("SignRevocationList() of a list that doesn't expire succeeded")
+	}
+	if _, err := SignRevocationList(ctx, sv, &RevocationList{
+		Issued:      issued,
+		Expires:     issued.Add(time.Hour),
+		Revocations: []Revocation{{Reason: "everything"}},
+	}); err == nil {
+		t.Error("SignRevocationList() of a revocation matching everything succeeded")
+	}
+}
+
+func TestRevoked(t *testing.T) {
+	rootCert, rootKey, err := test.GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCert, _, err := test.GenerateLeafCert("signer@example.com", "oidc-issuer", rootCert, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, err := cryptoutils.MarshalCertificateToPEM(leafCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := static.NewSignature([]byte("payload"), "c2lnbmF0dXJl", static.WithCertChain(certPEM, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := sig.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(leafCert.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := fmt.Sprintf("%x", sha256.Sum256(der))
+
+	signedAt := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+	before := signedAt.Add(-time.Hour)
+	after := signedAt.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		revocation Revocation
+		signedAt   time.Time
+		want       bool
+	}{
+		{"key", Revocation{KeyID: strings.ToUpper(keyID)}, signedAt, true},
+		{"other key", Revocation{KeyID: "abcd"}, signedAt, false},
+		{"serial", Revocation{CertSerial: "01"}, signedAt, true},
+		{"other serial", Revocation{CertSerial: "02"}, signedAt, false},
+		{"identity", Revocation{Identity: "signer@example.com"}, signedAt, true},
+		{"other identity", Revocation{Identity: "other@example.com"}, signedAt, false},
+		{"signature", Revocation{SignatureDigest: digest.String()}, signedAt, true},
+		{"identity and other key", Revocation{Identity: "signer@example.com", KeyID: "abcd"}, signedAt, false},
+		{"signed after", Revocation{KeyID: keyID, SignedAfter: &before}, signedAt, true},
+		{"not signed after", Revocation{KeyID: keyID, SignedAfter: &after}, signedAt, false},
+		{"signed before", Revocation{KeyID: keyID, SignedBefore: &after}, signedAt, true},
+		{"not signed before", Revocation{KeyID: keyID, SignedBefore: &before}, signedAt, false},
+		{"unknown signing time", Revocation{KeyID: keyID, SignedBefore: &before}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := &RevocationList{Revocations: []Revocation{tt.revocation}}
+			r, err := rl.Revoked(sig, leafCert.PublicKey, tt.signedAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r != nil; got != tt.want {
+				t.Errorf("Revoked() = %v, want revoked %v", r, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultRotationExpiry is how long the public key of a rotated key pair is
+// still accepted by default, so signatures made just before the rotation don't
+// get rejected right away.
+const DefaultRotationExpiry = 7 * 24 * time.Hour
+
+// PublicKeyRing is the public key of a key pair, and the public keys of the
+// key pairs it was rotated from, which are accepted until they expire.
+//
+// Rings are stored in key/value stores, like Kubernetes secrets or git forge
+// variables, see PublicKeyRingNames.
+type PublicKeyRing struct {
+	Current []byte
+	Retired []RetiredPublicKey
+}
+
+// RetiredPublicKey is the PEM encoded public key of a rotated key pair,
+// accepted until Expires.
+type RetiredPublicKey struct {
+	PEM     []byte
+	Expires time.Time
+}
+
+// PublicKeyRingNames names the entries of a PublicKeyRing in a key/value
+// store: the current key is stored under Current, and the retired ones under
+// <Current><Separator><n> and <Current><Separator><n><Separator><Expires>,
+// 1 being the most recent.
+type PublicKeyRingNames struct {
+	Current   string
+	Separator string
+	Expires   string
+}
+
+func (n PublicKeyRingNames) retired(i int) string {
+	return fmt.Sprint(n.Current, n.Separator, i)
+}
+
+func (n PublicKeyRingNames) expires(i int) string {
+	return fmt.Sprint(n.Current, n.Separator, i, n.Separator, n.Expires)
+}
+
+// ReadPublicKeyRing reads the ring stored in the store get reads. get returns
+// nil for the entries that don't exist.
+func ReadPublicKeyRing(get func(key string) ([]byte, error), names PublicKeyRingNames) (*PublicKeyRing, error) {
+	current, err := get(names.Current)
+	if err != nil {
+		return nil, err
+	}
+	r := &PublicKeyRing{Current: current}
+	for n := 1; ; n++ {
+		key := names.retired(n)
+		pem, err := get(key)
+		if err != nil {
+			return nil, err
+		}
+		if pem == nil {
+			return r, nil
+		}
+		expires, err := get(names.expires(n))
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339, string(expires))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing the expiry of %s", key)
+		}
+		r.Retired = append(r.Retired, RetiredPublicKey{PEM: pem, Expires: t})
+	}
+}
+
+// Rotate retires the current public key until expires, making pub the
+// current one, and drops the retired keys that expired by now.
+func (r *PublicKeyRing) Rotate(pub []byte, now, expires time.Time) {
+	retired := make([]RetiredPublicKey, 0, len(r.Retired)+1)
+	if len(r.Current) > 0 {
+		retired = append(retired, RetiredPublicKey{PEM: r.Current, Expires: expires})
+	}
+	for _, k := range r.Retired {
+		if k.Expires.After(now) {
+			retired = append(retired, k)
+		}
+	}
+	r.Current, r.Retired = pub, retired
+}
+
+// Write stores the ring with set, and removes the retired keys of the ring
+// it replaces, prev, that it doesn't have anymore with unset.
+func (r *PublicKeyRing) Write(prev *PublicKeyRing, names PublicKeyRingNames, set func(key string, value []byte) error, unset func(key string) error) error {
+	if err := set(names.Current, r.Current); err != nil {
+		return err
+	}
+	for i, k := range r.Retired {
+		if err := set(names.retired(i+1), k.PEM); err != nil {
+			return err
+		}
+		if err := set(names.expires(i+1), []byte(k.Expires.UTC().Format(time.RFC3339))); err != nil {
+			return err
+		}
+	}
+	if prev == nil {
+		return nil
+	}
+	for n := len(r.Retired) + 1; n <= len(prev.Retired); n++ {
+		if err := unset(names.retired(n)); err != nil {
+			return err
+		}
+		if err := unset(names.expires(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Valid returns the PEM encoded public keys of the ring that are still
+// accepted at now, concatenated.
+func (r *PublicKeyRing) Valid(now time.Time) []byte {
+	pems := [][]byte{r.Current}
+	for _, k := range r.Retired {
+		if k.Expires.After(now) {
+			pems = append(pems, k.PEM)
+		}
+	}
+	return bytes.Join(pems, []byte("\n"))
+}
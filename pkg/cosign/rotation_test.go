@@ -0,0 +1,96 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPublicKeyRing(t *testing.T) {
+	names := PublicKeyRingNames{Current: "pub", Separator: ".", Expires: "expires"}
+	now := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	store := map[string][]byte{"pub": []byte("one")}
+	get := func(key string) ([]byte, error) { return store[key], nil }
+	set := func(key string, value []byte) error {
+		store[key] = value
+		return nil
+	}
+	unset := func(key string) error {
+		delete(store, key)
+		return nil
+	}
+	rotate := func(pub string, now time.Time, retiredFor time.Duration) {
+		t.Helper()
+		ring, err := ReadPublicKeyRing(get, names)
+		if err != nil {
+			t.Fatalf("ReadPublicKeyRing() = %v", err)
+		}
+		prev := *ring
+		ring.Rotate([]byte(pub), now, now.Add(retiredFor))
+		if err := ring.Write(&prev, names, set, unset); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+	}
+
+	rotate("two", now, time.Hour)
+	rotate("three", now.Add(time.Minute), 24*time.Hour)
+	want := map[string][]byte{
+		"pub":           []byte("three"),
+		"pub.1":         []byte("two"),
+		"pub.1.expires": []byte("2021-12-02T00:01:00Z"),
+		"pub.2":         []byte("one"),
+		"pub.2.expires": []byte("2021-12-01T01:00:00Z"),
+	}
+	if !reflect.DeepEqual(store, want) {
+		t.Errorf("store = %q, wanted %q", store, want)
+	}
+
+	ring, err := ReadPublicKeyRing(get, names)
+	if err != nil {
+		t.Fatalf("ReadPublicKeyRing() = %v", err)
+	}
+	for _, tc := range []struct {
+		at   time.Time
+		want string
+	}{
+		{now, "three\ntwo\none"},
+		{now.Add(2 * time.Hour), "three\ntwo"},
+		{now.Add(48 * time.Hour), "three"},
+	} {
+		if got := string(ring.Valid(tc.at)); got != tc.want {
+			t.Errorf("Valid(%v) = %q, wanted %q", tc.at, got, tc.want)
+		}
+	}
+
+	// Rotating once "one" expired drops it from the store.
+	rotate("four", now.Add(2*time.Hour), time.Hour)
+	want = map[string][]byte{
+		"pub":           []byte("four"),
+		"pub.1":         []byte("three"),
+		"pub.1.expires": []byte("2021-12-01T03:00:00Z"),
+		"pub.2":         []byte("two"),
+		"pub.2.expires": []byte("2021-12-02T00:01:00Z"),
+	}
+	if !reflect.DeepEqual(store, want) {
+		t.Errorf("store = %q, wanted %q", store, want)
+	}
+
+	store["pub.1.expires"] = []byte("tomorrow")
+	if _, err := ReadPublicKeyRing(get, names); err == nil {
+		t.Error("ReadPublicKeyRing() with an invalid expiry succeeded")
+	}
+}
@@ -16,10 +16,11 @@ package cosign
 
 import (
 	"context"
-	"crypto/sha256"
+	"crypto"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/go-openapi/strfmt"
@@ -30,6 +31,7 @@ import (
 	"github.com/sigstore/cosign/pkg/cosign/bundle"
 	"github.com/sigstore/cosign/pkg/cosign/tuf"
 	"github.com/sigstore/rekor/pkg/generated/client/index"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 
 	"github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/generated/client/entries"
@@ -37,14 +39,27 @@ import (
 	"github.com/sigstore/rekor/pkg/generated/models"
 	hashedrekord_v001 "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
 	intoto_v001 "github.com/sigstore/rekor/pkg/types/intoto/v0.0.1"
+	rekord_v001 "github.com/sigstore/rekor/pkg/types/rekord/v0.0.1"
 )
 
 // This is the rekor public key target name
 var rekorTargetStr = `rekor.pub`
 
-// GetRekorPub retrieves the rekor public key from the embedded or cached TUF root. If expired, makes a
-// network call to retrieve the updated target.
+// This can be used to point to a local rekor public key file instead of the TUF target,
+// e.g. to verify offline or against a private instance.
+const altRekorPublicKey = "SIGSTORE_REKOR_PUBLIC_KEY"
+
+// GetRekorPub retrieves the rekor public key from the file named by SIGSTORE_REKOR_PUBLIC_KEY or,
+// if unset, from the embedded or cached TUF root. If expired, makes a network call to retrieve
+// the updated target.
 func GetRekorPub(ctx context.Context) ([]byte, error) {
+	if altPub := os.Getenv(altRekorPublicKey); altPub != "" {
+		raw, err := os.ReadFile(altPub)
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading alternate Rekor public key file")
+		}
+		return raw, nil
+	}
 	tuf, err := tuf.NewFromEnv(ctx)
 	if err != nil {
 		return nil, err
@@ -54,8 +69,27 @@ func GetRekorPub(ctx context.Context) ([]byte, error) {
 }
 
 // TLogUpload will upload the signature, public key and payload to the transparency log.
+// The payload is hashed with the algorithm matching the public key or certificate in pemBytes.
 func TLogUpload(ctx context.Context, rekorClient *client.Rekor, signature, payload []byte, pemBytes []byte) (*models.LogEntryAnon, error) {
-	re := rekorEntry(payload, signature, pemBytes)
+	hashAlgorithm, err := hashAlgorithmForPEM(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := hashPayload(payload, hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	return TLogUploadDigest(ctx, rekorClient, signature, digest, hashAlgorithm, pemBytes)
+}
+
+// TLogUploadDigest is like TLogUpload, but takes the digest of the payload, computed with
+// hashAlgorithm, instead of the payload itself, so that large blobs can be signed and logged
+// without reading them into memory.
+func TLogUploadDigest(ctx context.Context, rekorClient *client.Rekor, signature, digest []byte, hashAlgorithm crypto.Hash, pemBytes []byte) (*models.LogEntryAnon, error) {
+	re, err := rekorEntry(digest, hashAlgorithm, signature, pemBytes)
+	if err != nil {
+		return nil, err
+	}
 	returnVal := models.Hashedrekord{
 		APIVersion: swag.String(re.APIVersion()),
 		Spec:       re.HashedRekordObj,
@@ -108,17 +142,55 @@ func intotoEntry(signature, pubKey []byte) intoto_v001.V001Entry {
 	}
 }
 
-func rekorEntry(payload, signature, pubKey []byte) hashedrekord_v001.V001Entry {
-	// TODO: Signatures created on a digest using a hash algorithm other than SHA256 will fail
-	// upload right now. Plumb information on the hash algorithm used when signing from the
-	// SignerVerifier to use for the HashedRekordObj.Data.Hash.Algorithm.
-	h := sha256.Sum256(payload)
+// HashedRekordAlgorithm returns the name a hashedrekord entry uses for hashAlgorithm.
+func HashedRekordAlgorithm(hashAlgorithm crypto.Hash) (string, error) {
+	switch hashAlgorithm {
+	case crypto.SHA256:
+		return models.HashedrekordV001SchemaDataHashAlgorithmSha256, nil
+	case crypto.SHA384:
+		return "sha384", nil
+	case crypto.SHA512:
+		return "sha512", nil
+	case 0:
+		return "", errors.New("hashedrekord entries require a signature over a digest, which this key type does not produce")
+	default:
+		return "", fmt.Errorf("unsupported hash algorithm for a hashedrekord entry: %v", hashAlgorithm)
+	}
+}
+
+// hashAlgorithmForPEM returns the hash algorithm matching a PEM encoded public key or certificate.
+func hashAlgorithmForPEM(pemBytes []byte) (crypto.Hash, error) {
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(pemBytes)
+	if err != nil {
+		certs, certErr := cryptoutils.UnmarshalCertificatesFromPEM(pemBytes)
+		if certErr != nil || len(certs) == 0 {
+			return 0, errors.Wrap(err, "parsing public key or certificate")
+		}
+		pub = certs[0].PublicKey
+	}
+	return HashAlgorithmForKey(pub), nil
+}
+
+func hashPayload(payload []byte, hashAlgorithm crypto.Hash) ([]byte, error) {
+	if _, err := HashedRekordAlgorithm(hashAlgorithm); err != nil {
+		return nil, err
+	}
+	h := hashAlgorithm.New()
+	h.Write(payload)
+	return h.Sum(nil), nil
+}
+
+func rekorEntry(digest []byte, hashAlgorithm crypto.Hash, signature, pubKey []byte) (hashedrekord_v001.V001Entry, error) {
+	alg, err := HashedRekordAlgorithm(hashAlgorithm)
+	if err != nil {
+		return hashedrekord_v001.V001Entry{}, err
+	}
 	return hashedrekord_v001.V001Entry{
 		HashedRekordObj: models.HashedrekordV001Schema{
 			Data: &models.HashedrekordV001SchemaData{
 				Hash: &models.HashedrekordV001SchemaDataHash{
-					Algorithm: swag.String(models.HashedrekordV001SchemaDataHashAlgorithmSha256),
-					Value:     swag.String(hex.EncodeToString(h[:])),
+					Algorithm: swag.String(alg),
+					Value:     swag.String(hex.EncodeToString(digest)),
 				},
 			},
 			Signature: &models.HashedrekordV001SchemaSignature{
@@ -128,7 +200,7 @@ func rekorEntry(payload, signature, pubKey []byte) hashedrekord_v001.V001Entry {
 				},
 			},
 		},
-	}
+	}, nil
 }
 
 func GetTlogEntry(ctx context.Context, rekorClient *client.Rekor, uuid string) (*models.LogEntryAnon, error) {
@@ -136,7 +208,7 @@ func GetTlogEntry(ctx context.Context, rekorClient *client.Rekor, uuid string) (
 	params.SetEntryUUID(uuid)
 	resp, err := rekorClient.Entries.GetLogEntryByUUID(params)
 	if err != nil {
-		return nil, err
+		return nil, &TransparencyLogError{err}
 	}
 	for _, e := range resp.Payload {
 		return &e, nil
@@ -144,47 +216,115 @@ func GetTlogEntry(ctx context.Context, rekorClient *client.Rekor, uuid string) (
 	return nil, errors.New("empty response")
 }
 
-func proposedEntry(b64Sig string, payload, pubKey []byte) ([]models.ProposedEntry, error) {
-	var proposedEntry []models.ProposedEntry
+// FindTlogEntry returns the transparency log entry of the signature b64Sig, made with
+// pubKey over payload, or of the attestation payload if b64Sig is empty.
+func FindTlogEntry(ctx context.Context, rekorClient *client.Rekor, b64Sig string, payload, pubKey []byte) (uuid string, index int64, err error) {
 	signature, err := base64.StdEncoding.DecodeString(b64Sig)
 	if err != nil {
-		return nil, errors.Wrap(err, "decoding base64 signature")
+		return "", 0, errors.Wrap(err, "decoding base64 signature")
 	}
 
 	// The fact that there's no signature (or empty rather), implies
 	// that this is an Attestation that we're verifying.
 	if len(signature) == 0 {
 		te := intotoEntry(payload, pubKey)
-		entry := &models.Intoto{
+		return findTlogEntry(ctx, rekorClient, []models.ProposedEntry{&models.Intoto{
 			APIVersion: swag.String(te.APIVersion()),
 			Spec:       te.IntotoObj,
-		}
-		proposedEntry = []models.ProposedEntry{entry}
-	} else {
-		re := rekorEntry(payload, signature, pubKey)
-		entry := &models.Hashedrekord{
-			APIVersion: swag.String(re.APIVersion()),
-			Spec:       re.HashedRekordObj,
-		}
-		proposedEntry = []models.ProposedEntry{entry}
+		}})
+	}
+
+	hashAlgorithm, err := hashAlgorithmForPEM(pubKey)
+	if err != nil {
+		return "", 0, err
 	}
-	return proposedEntry, nil
+	digest, err := hashPayload(payload, hashAlgorithm)
+	if err != nil {
+		return "", 0, err
+	}
+	return FindTlogEntryByDigest(ctx, rekorClient, b64Sig, digest, hashAlgorithm, pubKey)
 }
 
-func FindTlogEntry(ctx context.Context, rekorClient *client.Rekor, b64Sig string, payload, pubKey []byte) (uuid string, index int64, err error) {
-	searchParams := entries.NewSearchLogQueryParamsWithContext(ctx)
-	searchLogQuery := models.SearchLogQuery{}
-	proposedEntry, err := proposedEntry(b64Sig, payload, pubKey)
+// FindLegacySHA256TlogEntry is like FindTlogEntry for a signature, but looks up
+// the entry of a SHA-256 signature made with a key that now signs with another
+// hash algorithm, see LegacySHA256Verifier.
+func FindLegacySHA256TlogEntry(ctx context.Context, rekorClient *client.Rekor, b64Sig string, payload, pubKey []byte) (uuid string, index int64, err error) {
+	hashAlgorithm, err := hashAlgorithmForPEM(pubKey)
+	if err != nil {
+		return "", 0, err
+	}
+	if hashAlgorithm == crypto.SHA256 || hashAlgorithm == crypto.Hash(0) {
+		return "", 0, errors.New("key never made legacy SHA-256 signatures")
+	}
+	digest, err := hashPayload(payload, crypto.SHA256)
 	if err != nil {
 		return "", 0, err
 	}
+	return FindTlogEntryByDigest(ctx, rekorClient, b64Sig, digest, crypto.SHA256, pubKey)
+}
+
+// FindTlogEntryByDigest is like FindTlogEntry for a signature, but takes the digest of the
+// payload, computed with hashAlgorithm, instead of the payload itself. The signature is
+// looked up as a hashedrekord entry or, for SHA-256 digests, as the rekord entry older
+// clients logged.
+func FindTlogEntryByDigest(ctx context.Context, rekorClient *client.Rekor, b64Sig string, digest []byte, hashAlgorithm crypto.Hash, pubKey []byte) (uuid string, index int64, err error) {
+	signature, err := base64.StdEncoding.DecodeString(b64Sig)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "decoding base64 signature")
+	}
+	re, err := rekorEntry(digest, hashAlgorithm, signature, pubKey)
+	if err != nil {
+		return "", 0, err
+	}
+	uuid, index, err = findTlogEntry(ctx, rekorClient, []models.ProposedEntry{&models.Hashedrekord{
+		APIVersion: swag.String(re.APIVersion()),
+		Spec:       re.HashedRekordObj,
+	}})
+	var tlerr *TransparencyLogError
+	if err == nil || hashAlgorithm != crypto.SHA256 || errors.As(err, &tlerr) {
+		return uuid, index, err
+	}
+	re2 := rekordEntry(digest, signature, pubKey)
+	if uuid, index, rerr := findTlogEntry(ctx, rekorClient, []models.ProposedEntry{&models.Rekord{
+		APIVersion: swag.String(re2.APIVersion()),
+		Spec:       re2.RekordObj,
+	}}); rerr == nil {
+		return uuid, index, nil
+	}
+	return "", 0, err
+}
+
+// rekordEntry returns the rekord entry of a signature over a payload with the
+// given SHA-256 digest, the only one rekord entries support.
+func rekordEntry(digest, signature, pubKey []byte) rekord_v001.V001Entry {
+	return rekord_v001.V001Entry{
+		RekordObj: models.RekordV001Schema{
+			Data: &models.RekordV001SchemaData{
+				Hash: &models.RekordV001SchemaDataHash{
+					Algorithm: swag.String(models.RekordV001SchemaDataHashAlgorithmSha256),
+					Value:     swag.String(hex.EncodeToString(digest)),
+				},
+			},
+			Signature: &models.RekordV001SchemaSignature{
+				Content: strfmt.Base64(signature),
+				Format:  models.RekordV001SchemaSignatureFormatX509,
+				PublicKey: &models.RekordV001SchemaSignaturePublicKey{
+					Content: strfmt.Base64(pubKey),
+				},
+			},
+		},
+	}
+}
 
+func findTlogEntry(ctx context.Context, rekorClient *client.Rekor, proposedEntry []models.ProposedEntry) (uuid string, index int64, err error) {
+	searchParams := entries.NewSearchLogQueryParamsWithContext(ctx)
+	searchLogQuery := models.SearchLogQuery{}
 	searchLogQuery.SetEntries(proposedEntry)
 
 	searchParams.SetEntry(&searchLogQuery)
 	resp, err := rekorClient.Entries.SearchLogQuery(searchParams)
 	if err != nil {
-		return "", 0, errors.Wrap(err, "searching log query")
+		return "", 0, &TransparencyLogError{errors.Wrap(err, "searching log query")}
 	}
 	if len(resp.Payload) == 0 {
 		return "", 0, errors.New("signature not found in transparency log")
@@ -207,16 +347,27 @@ func FindTlogEntry(ctx context.Context, rekorClient *client.Rekor, b64Sig string
 }
 
 func FindTLogEntriesByPayload(ctx context.Context, rekorClient *client.Rekor, payload []byte) (uuids []string, err error) {
+	digest, err := hashPayload(payload, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	return FindTLogEntriesByDigest(ctx, rekorClient, digest, crypto.SHA256)
+}
+
+// FindTLogEntriesByDigest is like FindTLogEntriesByPayload, but takes the digest of the payload,
+// computed with hashAlgorithm.
+func FindTLogEntriesByDigest(ctx context.Context, rekorClient *client.Rekor, digest []byte, hashAlgorithm crypto.Hash) (uuids []string, err error) {
+	alg, err := HashedRekordAlgorithm(hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
 	params := index.NewSearchIndexParamsWithContext(ctx)
 	params.Query = &models.SearchIndex{}
-
-	h := sha256.New()
-	h.Write(payload)
-	params.Query.Hash = fmt.Sprintf("sha256:%s", strings.ToLower(hex.EncodeToString(h.Sum(nil))))
+	params.Query.Hash = fmt.Sprintf("%s:%s", alg, strings.ToLower(hex.EncodeToString(digest)))
 
 	searchIndex, err := rekorClient.Index.SearchIndex(params)
 	if err != nil {
-		return nil, err
+		return nil, &TransparencyLogError{err}
 	}
 	return searchIndex.GetPayload(), nil
 }
@@ -227,7 +378,7 @@ func verifyTLogEntry(ctx context.Context, rekorClient *client.Rekor, uuid string
 
 	lep, err := rekorClient.Entries.GetLogEntryByUUID(params)
 	if err != nil {
-		return nil, err
+		return nil, &TransparencyLogError{err}
 	}
 
 	if len(lep.Payload) != 1 {
@@ -255,7 +406,7 @@ func verifyTLogEntry(ctx context.Context, rekorClient *client.Rekor, uuid string
 	// Verify rekor's signature over the SET.
 	resp, err := rekorClient.Pubkey.GetPublicKey(pubkey.NewGetPublicKeyParamsWithContext(ctx))
 	if err != nil {
-		return nil, errors.Wrap(err, "rekor public key")
+		return nil, &TransparencyLogError{errors.Wrap(err, "rekor public key")}
 	}
 	rekorPubKey, err := PemToECDSAKey([]byte(resp.Payload))
 	if err != nil {
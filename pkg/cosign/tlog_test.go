@@ -0,0 +1,101 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	rekorclient "github.com/sigstore/rekor/pkg/client"
+)
+
+func TestRekorEntryHashAlgorithm(t *testing.T) {
+	tests := []struct {
+		hashAlgorithm crypto.Hash
+		want          string
+		wantErr       bool
+	}{
+		{hashAlgorithm: crypto.SHA256, want: "sha256"},
+		{hashAlgorithm: crypto.SHA384, want: "sha384"},
+		{hashAlgorithm: crypto.SHA512, want: "sha512"},
+		{hashAlgorithm: crypto.SHA1, wantErr: true},
+		{hashAlgorithm: crypto.Hash(0), wantErr: true},
+	}
+	for _, tc := range tests {
+		re, err := rekorEntry([]byte("digest"), tc.hashAlgorithm, []byte("signature"), []byte("key"))
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("rekorEntry(%v) = %v, wanted err: %t", tc.hashAlgorithm, err, tc.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if got := *re.HashedRekordObj.Data.Hash.Algorithm; got != tc.want {
+			t.Errorf("rekorEntry(%v) algorithm = %s, wanted %s", tc.hashAlgorithm, got, tc.want)
+		}
+		if h, err := hashAlgorithmForName(tc.want); err != nil || h != tc.hashAlgorithm {
+			t.Errorf("hashAlgorithmForName(%s) = %v, %v", tc.want, h, err)
+		}
+	}
+}
+
+func TestFindTlogEntryByDigestKinds(t *testing.T) {
+	var kinds []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var query struct {
+			Entries []struct {
+				Kind string `json:"kind"`
+			} `json:"entries"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			t.Errorf("decoding search query: %v", err)
+		}
+		for _, e := range query.Entries {
+			kinds = append(kinds, e.Kind)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+	rekorClient, err := rekorclient.GetRekorClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256([]byte("payload"))
+	b64Sig := base64.StdEncoding.EncodeToString([]byte("signature"))
+	tests := []struct {
+		hashAlgorithm crypto.Hash
+		want          []string
+	}{
+		{hashAlgorithm: crypto.SHA256, want: []string{"hashedrekord", "rekord"}},
+		{hashAlgorithm: crypto.SHA384, want: []string{"hashedrekord"}},
+	}
+	for _, tc := range tests {
+		kinds = nil
+		if _, _, err := FindTlogEntryByDigest(context.Background(), rekorClient, b64Sig, digest[:], tc.hashAlgorithm, []byte("key")); err == nil {
+			t.Fatalf("FindTlogEntryByDigest(%v) succeeded without entries", tc.hashAlgorithm)
+		}
+		if !reflect.DeepEqual(kinds, tc.want) {
+			t.Errorf("FindTlogEntryByDigest(%v) searched %v, wanted %v", tc.hashAlgorithm, kinds, tc.want)
+		}
+	}
+}
@@ -37,11 +37,16 @@ import (
 )
 
 const (
-	DefaultRemoteRoot = "sigstore-tuf-root"
-	TufRootEnv        = "TUF_ROOT"
-	SigstoreNoCache   = "SIGSTORE_NO_CACHE"
+	DefaultRemoteRoot  = "sigstore-tuf-root"
+	TufRootEnv         = "TUF_ROOT"
+	SigstoreNoCache    = "SIGSTORE_NO_CACHE"
+	SigstoreTufOffline = "SIGSTORE_TUF_OFFLINE"
 )
 
+// ErrExpiredMetadata is returned in offline mode when the cached metadata
+// expired, instead of updating it.
+var ErrExpiredMetadata = errors.New("TUF metadata expired, run `cosign tuf refresh` to update it")
+
 type TUF struct {
 	client  *client.Client
 	targets targetImpl
@@ -53,52 +58,123 @@ func (t *TUF) Close() error {
 	return t.local.Close()
 }
 
+// remoteConfig is the remote.json file of the cache, recording the mirror
+// given to Initialize, and whether it is used offline.
+type remoteConfig struct {
+	Mirror  string `json:"mirror"`
+	Offline bool   `json:"offline,omitempty"`
+}
+
+// NewFromEnv returns a client of the TUF repository Initialize was given, or
+// of DefaultRemoteRoot, caching it in TUF_ROOT.
+// In offline mode, set by Initialize or SIGSTORE_TUF_OFFLINE, the cached (or
+// embedded) metadata is never updated, and expired metadata is an error.
 func NewFromEnv(ctx context.Context) (*TUF, error) {
-	remote, err := GcsRemoteStore(ctx, DefaultRemoteRoot, nil, nil)
+	cacheRoot := rootCacheDir()
+	rc, err := readRemoteConfig(cacheRoot)
+	if err != nil {
+		return nil, err
+	}
+	if rc.Offline || offline() {
+		return NewOffline(cacheRoot)
+	}
+	remote, err := RemoteStoreFromURL(ctx, rc.Mirror)
 	if err != nil {
 		return nil, err
 	}
-	return New(ctx, remote, rootCacheDir())
+	return New(ctx, remote, cacheRoot)
 }
 
-func New(ctx context.Context, remote client.RemoteStore, cacheRoot string) (*TUF, error) {
-	t := &TUF{}
-	// WE SHOULD:
-	// FIRST RESPECT THE FILES ON DISK (BYOTUF)
-	// IF THEY'RE OUT OF DATE:
-	//   UPDATE THEM IN MEMORY
-	//     MAYBE UPDATE THEM ON DISK
-	// IF THEY DONT EXIST:
-	//   THEN THE EMBEDDED ONES
-	//   IF THEY'RE OUT OF DATE:
-	//     UPDATE THEM IN MEMORY
-	//     MAYBE UPDATE THEM ON DISK
+// readRemoteConfig returns the remote.json of cacheRoot, defaulting to
+// DefaultRemoteRoot.
+func readRemoteConfig(cacheRoot string) (*remoteConfig, error) {
+	rc := &remoteConfig{}
+	b, err := os.ReadFile(filepath.Join(cacheRoot, "remote.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "reading remote.json")
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, rc); err != nil {
+			return nil, errors.Wrap(err, "parsing remote.json")
+		}
+	}
+	if rc.Mirror == "" {
+		rc.Mirror = DefaultRemoteRoot
+	}
+	return rc, nil
+}
 
-	tufDB := filepath.Join(cacheRoot, "tuf.db")
-	var local client.LocalStore
-	var err error
+func writeRemoteConfig(cacheRoot string, rc *remoteConfig) error {
+	b, err := json.Marshal(rc)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.WriteFile(filepath.Join(cacheRoot, "remote.json"), b, 0600), "writing remote.json")
+}
 
+// openLocal opens the local store of cacheRoot, or the embedded one if there
+// is none, with the matching targets implementation.
+func openLocal(cacheRoot string) (client.LocalStore, targetImpl, error) {
+	tufDB := filepath.Join(cacheRoot, "tuf.db")
 	_, statErr := os.Stat(tufDB)
 	switch {
 	case os.IsNotExist(statErr):
 		// There is no root at the location, try embedded
-		local, err = embeddedLocalStore()
+		local, err := embeddedLocalStore()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		t.targets = newEmbeddedImpl()
+		return local, newEmbeddedImpl(), nil
 	case statErr != nil:
 		// Some other error, bail
-		return nil, statErr
+		return nil, nil, statErr
 	default:
 		// There is a root! Happy path.
-		local, err = localStore(tufDB)
+		local, err := localStore(tufDB)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		t.targets = newFileImpl()
+		return local, newFileImpl(), nil
+	}
+}
+
+// NewOffline is like New, but never updates the metadata cached in cacheRoot
+// (or the embedded one): expired metadata is an error, ErrExpiredMetadata.
+func NewOffline(cacheRoot string) (*TUF, error) {
+	local, targets, err := openLocal(cacheRoot)
+	if err != nil {
+		return nil, err
+	}
+	trustedMeta, err := local.GetMeta()
+	if err != nil {
+		local.Close()
+		return nil, errors.Wrap(err, "getting trusted meta")
+	}
+	if expired := expiredMetadata(trustedMeta, time.Now()); len(expired) > 0 {
+		local.Close()
+		return nil, errors.Wrapf(ErrExpiredMetadata, "%v", expired)
 	}
+	return &TUF{client: client.NewClient(local, nil), targets: targets, local: local}, nil
+}
 
+func New(ctx context.Context, remote client.RemoteStore, cacheRoot string) (*TUF, error) {
+	t := &TUF{}
+	// WE SHOULD:
+	// FIRST RESPECT THE FILES ON DISK (BYOTUF)
+	// IF THEY'RE OUT OF DATE:
+	//   UPDATE THEM IN MEMORY
+	//     MAYBE UPDATE THEM ON DISK
+	// IF THEY DONT EXIST:
+	//   THEN THE EMBEDDED ONES
+	//   IF THEY'RE OUT OF DATE:
+	//     UPDATE THEM IN MEMORY
+	//     MAYBE UPDATE THEM ON DISK
+
+	local, targets, err := openLocal(cacheRoot)
+	if err != nil {
+		return nil, err
+	}
+	t.targets = targets
 	t.local = local
 	t.client = client.NewClient(local, remote)
 	trustedMeta, err := local.GetMeta()
@@ -115,8 +191,8 @@ func New(ctx context.Context, remote client.RemoteStore, cacheRoot string) (*TUF
 
 	// We need to update our tufdb.
 	// Warning: If a local cache already exists, you may get a local/remote mismatch
-	// since the default remote may not match the remote repository configured during
-	// a cosign initialize.
+	// if remote isn't the repository configured during a cosign initialize, which
+	// NewFromEnv uses.
 	trustedRoot, err := getRoot(trustedMeta)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting trusted root")
@@ -148,8 +224,20 @@ func getRoot(meta map[string]json.RawMessage) (json.RawMessage, error) {
 	return trustedRoot, nil
 }
 
-func Initialize(remote client.RemoteStore, root []byte) error {
-	tufDB := filepath.Join(rootCacheDir(), "tuf.db")
+// Initialize initializes the cache in TUF_ROOT from the TUF repository at
+// mirror, see RemoteStoreFromURL, trusting root. If root is nil, the root
+// already cached in TUF_ROOT is trusted, or the embedded one if there is none
+// yet. NewFromEnv then keeps using mirror, never updating the cache if offline.
+func Initialize(ctx context.Context, mirror string, root []byte, offline bool) error {
+	return initialize(ctx, rootCacheDir(), &remoteConfig{Mirror: mirror, Offline: offline}, root)
+}
+
+func initialize(ctx context.Context, cacheRoot string, rc *remoteConfig, root []byte) error {
+	remote, err := RemoteStoreFromURL(ctx, rc.Mirror)
+	if err != nil {
+		return err
+	}
+	tufDB := filepath.Join(cacheRoot, "tuf.db")
 	local, err := localStore(tufDB)
 	if err != nil {
 		return err
@@ -178,7 +266,7 @@ func Initialize(remote client.RemoteStore, root []byte) error {
 	if err := updateMetadataAndDownloadTargets(c, newFileImpl()); err != nil {
 		return errors.Wrap(err, "updating local metadata and targets")
 	}
-	return nil
+	return writeRemoteConfig(cacheRoot, rc)
 }
 
 func (t *TUF) GetTarget(name string) ([]byte, error) {
@@ -226,7 +314,7 @@ func localStore(cacheRoot string) (client.LocalStore, error) {
 
 func embeddedLocalStore() (client.LocalStore, error) {
 	local := client.MemoryLocalStore()
-	for _, mdFilename := range []string{"root.json", "targets.json", "snapshot.json", "timestamp.json"} {
+	for _, mdFilename := range topLevelMetadata {
 		b, err := embeddedRootRepo.ReadFile(path.Join("repository", mdFilename))
 		if err != nil {
 			return nil, errors.Wrap(err, "reading embedded file")
@@ -253,6 +341,17 @@ var isExpiredTimestamp = func(metadata []byte) bool {
 	return time.Until(sm.Expires) <= 0
 }
 
+// expiredMetadata returns the top-level metadata of meta that expired at now.
+func expiredMetadata(meta map[string]json.RawMessage, now time.Time) []string {
+	var expired []string
+	for _, name := range topLevelMetadata {
+		if st, err := metadataStatus(meta[name]); err != nil || !st.Expires.After(now) {
+			expired = append(expired, name)
+		}
+	}
+	return expired
+}
+
 func getRootKeys(rootFileBytes []byte) ([]*data.PublicKey, int, error) {
 	store := gtuf.MemoryStore(map[string]json.RawMessage{"root.json": rootFileBytes}, nil)
 	repo, err := gtuf.NewRepo(store)
@@ -392,6 +491,14 @@ func (d *diskCache) Set(p string, b []byte) error {
 	return os.WriteFile(fp, b, 0600)
 }
 
+func offline() bool {
+	b, err := strconv.ParseBool(os.Getenv(SigstoreTufOffline))
+	if err != nil {
+		return false
+	}
+	return b
+}
+
 func noCache() bool {
 	b, err := strconv.ParseBool(os.Getenv(SigstoreNoCache))
 	if err != nil {
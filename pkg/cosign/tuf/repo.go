@@ -0,0 +1,341 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuf
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+	"github.com/sigstore/sigstore/pkg/signature"
+	gtuf "github.com/theupdateframework/go-tuf"
+	"github.com/theupdateframework/go-tuf/data"
+	"github.com/theupdateframework/go-tuf/pkg/keys"
+)
+
+// OCIFileMediaType is the media type of the layers holding the files of a
+// TUF repository pushed by PushRepository.
+const OCIFileMediaType types.MediaType = "application/vnd.dev.sigstore.tuf.file"
+
+// RoleKeys are the public keys of a top-level role of a TUF repository, and
+// how many of them must sign its metadata.
+type RoleKeys struct {
+	Keys      []crypto.PublicKey
+	Threshold int
+}
+
+// Repositories are managed in a directory holding the staged metadata and
+// targets in staged/, and the committed ones in repository/, which is what
+// gets published and what `cosign initialize` consumes: its root.json is the
+// root to trust, and the directory itself can be used as a file:// mirror,
+// served over HTTP(S) or GCS, or pushed as an OCI artifact.
+//
+// Private keys are never stored in it: the metadata of each role is signed
+// separately by the holders of its keys, see SignRepository, so that it can
+// be done with keys held in KMS or PKCS#11 tokens.
+
+// RepositoryPath returns the directory of the committed repository of dir.
+func RepositoryPath(dir string) string {
+	return filepath.Join(dir, "repository")
+}
+
+// signerStore is the local store of a repository, furnishing the signers of
+// the current operation instead of stored private keys.
+type signerStore struct {
+	gtuf.LocalStore
+	signers []keys.Signer
+}
+
+func (s *signerStore) GetSigners(string) ([]keys.Signer, error) {
+	return s.signers, nil
+}
+
+func (s *signerStore) SaveSigner(string, keys.Signer) error {
+	return errors.New("private keys are not stored in the repository")
+}
+
+func openRepository(dir string, signers ...keys.Signer) (*gtuf.Repo, error) {
+	return gtuf.NewRepo(&signerStore{LocalStore: gtuf.FileSystemStore(dir, nil), signers: signers})
+}
+
+// InitRepository stages the root of a new repository in dir, with the keys of
+// each of the top-level roles, expiring at expires. It still needs to be
+// signed, see SignRepository.
+func InitRepository(dir string, roles map[string]RoleKeys, expires time.Time) error {
+	for _, role := range []string{"root", "targets", "snapshot", "timestamp"} {
+		rk, ok := roles[role]
+		if !ok || len(rk.Keys) == 0 {
+			return fmt.Errorf("no keys for the %s role", role)
+		}
+		if rk.Threshold < 1 || rk.Threshold > len(rk.Keys) {
+			return fmt.Errorf("invalid threshold %d for the %d keys of the %s role", rk.Threshold, len(rk.Keys), role)
+		}
+	}
+	repo, err := openRepository(dir)
+	if err != nil {
+		return err
+	}
+	if err := repo.Init(false); err != nil {
+		return errors.Wrap(err, "initializing repository")
+	}
+	for _, role := range []string{"root", "targets", "snapshot", "timestamp"} {
+		rk := roles[role]
+		for _, pub := range rk.Keys {
+			pk, err := publicKey(pub)
+			if err != nil {
+				return errors.Wrapf(err, "adding %s key", role)
+			}
+			if err := repo.AddVerificationKeyWithExpiration(role, pk, expires); err != nil {
+				return errors.Wrapf(err, "adding %s key", role)
+			}
+		}
+		if err := repo.SetThreshold(role, rk.Threshold); err != nil {
+			return errors.Wrapf(err, "setting %s threshold", role)
+		}
+	}
+	return nil
+}
+
+// AddTarget stages the target name of the repository in dir with content and
+// custom metadata, if any, with targets metadata expiring at expires. It
+// still needs to be signed, see SignRepository.
+func AddTarget(dir, name string, content []byte, custom json.RawMessage, expires time.Time) error {
+	repo, err := openRepository(dir)
+	if err != nil {
+		return err
+	}
+	staged := filepath.Join(dir, "staged", "targets", filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(staged), 0755); err != nil {
+		return errors.Wrap(err, "staging target")
+	}
+	if err := os.WriteFile(staged, content, 0644); err != nil {
+		return errors.Wrap(err, "staging target")
+	}
+	return errors.Wrapf(repo.AddTargetWithExpires(name, custom, expires), "adding target %s", name)
+}
+
+// SignRepository signs the staged metadata of the repository in dir of the
+// top-level roles sv is a key of, or only of roles if set. It returns the
+// roles it signed.
+func SignRepository(dir string, sv signature.Signer, roles []string) ([]string, error) {
+	signer, err := newKeySigner(sv)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := openRepository(dir, signer)
+	if err != nil {
+		return nil, err
+	}
+	keyRoles, err := rolesOf(repo, signer.PublicData())
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		// Only sign the staged metadata, the snapshot and timestamp are
+		// staged and signed when publishing.
+		for _, role := range keyRoles {
+			if _, err := os.Stat(filepath.Join(dir, "staged", role+".json")); err == nil {
+				roles = append(roles, role)
+			}
+		}
+	}
+	for _, role := range roles {
+		if !contains(keyRoles, role) {
+			return nil, fmt.Errorf("the key is not a key of the %s role", role)
+		}
+		if err := repo.Sign(role + ".json"); err != nil {
+			return nil, errors.Wrapf(err, "signing %s", role)
+		}
+	}
+	return roles, nil
+}
+
+// PublishRepository stages the snapshot and timestamp metadata of the
+// repository in dir, expiring at snapshotExpires and timestampExpires, signs
+// them with svs, and commits the staged metadata and targets once they are
+// signed by enough keys.
+func PublishRepository(dir string, svs []signature.Signer, snapshotExpires, timestampExpires time.Time) error {
+	signers := make([]keys.Signer, 0, len(svs))
+	for _, sv := range svs {
+		signer, err := newKeySigner(sv)
+		if err != nil {
+			return err
+		}
+		signers = append(signers, signer)
+	}
+	repo, err := openRepository(dir, signers...)
+	if err != nil {
+		return err
+	}
+	if err := repo.SnapshotWithExpires(snapshotExpires); err != nil {
+		return errors.Wrap(err, "staging snapshot")
+	}
+	if err := repo.TimestampWithExpires(timestampExpires); err != nil {
+		return errors.Wrap(err, "staging timestamp")
+	}
+	return errors.Wrap(repo.Commit(), "committing repository")
+}
+
+// PushRepository pushes the committed repository of dir as an OCI artifact to
+// ref, which OCIRemoteStore reads.
+func PushRepository(ctx context.Context, dir string, ref name.Reference, opts ...remote.Option) error {
+	root := RepositoryPath(dir)
+	var files []string
+	err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "reading repository")
+	}
+	sort.Strings(files)
+
+	img := empty.Image
+	for _, p := range files {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return errors.Wrap(err, "reading repository")
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		img, err = mutate.Append(img, mutate.Addendum{
+			Layer:       static.NewLayer(b, OCIFileMediaType),
+			Annotations: map[string]string{OCITitleAnnotation: filepath.ToSlash(rel)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Wrapf(remote.Write(ref, img, append(opts, remote.WithContext(ctx))...), "pushing repository to %s", ref)
+}
+
+// rolesOf returns the top-level roles of the staged root of repo pk is a key of.
+func rolesOf(repo *gtuf.Repo, pk *data.PublicKey) ([]string, error) {
+	s, err := repo.SignedMeta("root.json")
+	if err != nil {
+		return nil, err
+	}
+	root := &data.Root{}
+	if err := json.Unmarshal(s.Signed, root); err != nil {
+		return nil, errors.Wrap(err, "parsing root.json")
+	}
+	var roles []string
+	for _, role := range []string{"root", "targets", "snapshot", "timestamp"} {
+		r, ok := root.Roles[role]
+		if !ok {
+			continue
+		}
+		for _, id := range pk.IDs() {
+			if contains(r.KeyIDs, id) {
+				roles = append(roles, role)
+				break
+			}
+		}
+	}
+	return roles, nil
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// publicKey returns the TUF representation of pub.
+func publicKey(pub crypto.PublicKey) (*data.PublicKey, error) {
+	var value struct {
+		Public data.HexBytes `json:"public"`
+	}
+	pk := &data.PublicKey{Algorithms: data.HashAlgorithms}
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s, TUF supports P-256", k.Curve.Params().Name)
+		}
+		pk.Type, pk.Scheme = data.KeyTypeECDSA_SHA2_P256, data.KeySchemeECDSA_SHA2_P256
+		value.Public = elliptic.Marshal(k.Curve, k.X, k.Y)
+	case ed25519.PublicKey:
+		pk.Type, pk.Scheme = data.KeyTypeEd25519, data.KeySchemeEd25519
+		value.Public = data.HexBytes(k)
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, TUF supports ECDSA P-256 and Ed25519 keys", pub)
+	}
+	var err error
+	pk.Value, err = json.Marshal(&value)
+	return pk, err
+}
+
+// keySigner signs TUF metadata with a sigstore signer, e.g. of a KMS or
+// PKCS#11 key, whose private key can't be marshaled.
+type keySigner struct {
+	sv signature.Signer
+	pk *data.PublicKey
+}
+
+var _ keys.Signer = (*keySigner)(nil)
+
+func newKeySigner(sv signature.Signer) (*keySigner, error) {
+	pub, err := sv.PublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting public key")
+	}
+	pk, err := publicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{sv: sv, pk: pk}, nil
+}
+
+func (k *keySigner) MarshalPrivateKey() (*data.PrivateKey, error) {
+	return nil, errors.New("private keys can't be marshaled")
+}
+
+func (k *keySigner) UnmarshalPrivateKey(*data.PrivateKey) error {
+	return errors.New("private keys can't be unmarshaled")
+}
+
+func (k *keySigner) PublicData() *data.PublicKey {
+	return k.pk
+}
+
+// SignMessage hashes message with SHA-256 for ECDSA keys, as TUF expects.
+func (k *keySigner) SignMessage(message []byte) ([]byte, error) {
+	return k.sv.SignMessage(bytes.NewReader(message))
+}
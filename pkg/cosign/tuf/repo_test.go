@@ -0,0 +1,153 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuf
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func newTestSigner(t *testing.T, ed bool) signature.SignerVerifier {
+	t.Helper()
+	if ed {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sv, err := signature.LoadED25519SignerVerifier(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sv
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sv
+}
+
+func publicKeys(t *testing.T, svs ...signature.SignerVerifier) []crypto.PublicKey {
+	t.Helper()
+	var pubs []crypto.PublicKey
+	for _, sv := range svs {
+		pub, err := sv.PublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubs = append(pubs, pub)
+	}
+	return pubs
+}
+
+func TestRepository(t *testing.T) {
+	td := t.TempDir()
+	t.Setenv("TUF_ROOT", td)
+	t.Setenv(SigstoreNoCache, "false")
+	t.Setenv(SigstoreTufOffline, "")
+	ctx := context.Background()
+	dir := t.TempDir()
+	root1, root2 := newTestSigner(t, false), newTestSigner(t, true)
+	targets, online := newTestSigner(t, false), newTestSigner(t, false)
+	now := time.Now()
+
+	if err := InitRepository(dir, map[string]RoleKeys{
+		"root":      {Keys: publicKeys(t, root1, root2), Threshold: 2},
+		"targets":   {Keys: publicKeys(t, targets), Threshold: 1},
+		"snapshot":  {Keys: publicKeys(t, online), Threshold: 1},
+		"timestamp": {Keys: publicKeys(t, online), Threshold: 3},
+	}, now.Add(time.Hour)); err == nil {
+		t.Error("InitRepository() with a threshold above the number of keys succeeded")
+	}
+	if err := InitRepository(t.TempDir(), map[string]RoleKeys{
+		"root": {Keys: publicKeys(t, root1), Threshold: 1},
+	}, now.Add(time.Hour)); err == nil {
+		t.Error("InitRepository() without keys for all the roles succeeded")
+	}
+	if err := InitRepository(dir, map[string]RoleKeys{
+		"root":      {Keys: publicKeys(t, root1, root2), Threshold: 2},
+		"targets":   {Keys: publicKeys(t, targets), Threshold: 1},
+		"snapshot":  {Keys: publicKeys(t, online), Threshold: 1},
+		"timestamp": {Keys: publicKeys(t, online), Threshold: 1},
+	}, now.Add(365*24*time.Hour)); err != nil {
+		t.Fatalf("InitRepository() = %v", err)
+	}
+	if err := AddTarget(dir, "rekor.pub", []byte("rekor key"), nil, now.Add(90*24*time.Hour)); err != nil {
+		t.Fatalf("AddTarget() = %v", err)
+	}
+
+	publish := func() error {
+		return PublishRepository(dir, []signature.Signer{online}, now.Add(7*24*time.Hour), now.Add(24*time.Hour))
+	}
+	sign := func(sv signature.Signer, roles []string, want ...string) {
+		t.Helper()
+		got, err := SignRepository(dir, sv, roles)
+		if err != nil {
+			t.Fatalf("SignRepository() = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SignRepository() signed %v, wanted %v", got, want)
+		}
+	}
+
+	if err := publish(); err == nil {
+		t.Error("PublishRepository() of unsigned metadata succeeded")
+	}
+	sign(root1, nil, "root")
+	sign(targets, nil, "targets")
+	if err := publish(); err == nil {
+		t.Error("PublishRepository() below the root threshold succeeded")
+	}
+	if _, err := SignRepository(dir, online, []string{"root"}); err == nil {
+		t.Error("SignRepository() of a role the key isn't a key of succeeded")
+	}
+	sign(root2, []string{"root"}, "root")
+	if err := publish(); err != nil {
+		t.Fatalf("PublishRepository() = %v", err)
+	}
+
+	// The published repository can be used as is.
+	root, err := os.ReadFile(filepath.Join(RepositoryPath(dir), "root.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Initialize(ctx, "file://"+RepositoryPath(dir), root, false); err != nil {
+		t.Fatalf("Initialize() = %v", err)
+	}
+	tuf, err := NewFromEnv(ctx)
+	if err != nil {
+		t.Fatalf("NewFromEnv() = %v", err)
+	}
+	defer tuf.Close()
+	if b, err := tuf.GetTarget("rekor.pub"); err != nil || string(b) != "rekor key" {
+		t.Errorf("GetTarget(rekor.pub) = %q, %v", b, err)
+	}
+}
@@ -0,0 +1,148 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuf
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/theupdateframework/go-tuf/data"
+)
+
+// topLevelMetadata are the metadata files of the top-level roles.
+var topLevelMetadata = []string{"root.json", "targets.json", "snapshot.json", "timestamp.json"}
+
+// MetadataStatus is the version and expiry of a metadata file.
+type MetadataStatus struct {
+	Version int       `json:"version"`
+	Expires time.Time `json:"expires"`
+}
+
+// Status describes the TUF metadata and targets the verifications use.
+type Status struct {
+	// Mirror is the repository the metadata is updated from.
+	Mirror string `json:"mirror"`
+	// Offline is whether the metadata is never updated.
+	Offline bool `json:"offline"`
+	// Cached is whether the metadata is cached in TUF_ROOT, as opposed to
+	// the one embedded in cosign.
+	Cached bool `json:"cached"`
+	// Metadata is the status of the top-level metadata, by file name.
+	Metadata map[string]MetadataStatus `json:"metadata"`
+	// Targets are the names of the trusted targets.
+	Targets []string `json:"targets"`
+}
+
+func metadataStatus(raw json.RawMessage) (*MetadataStatus, error) {
+	s := &data.Signed{}
+	if err := json.Unmarshal(raw, s); err != nil {
+		return nil, err
+	}
+	st := &MetadataStatus{}
+	if err := json.Unmarshal(s.Signed, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// GetStatus returns the status of the TUF metadata cached in TUF_ROOT, or
+// embedded in cosign, without updating it.
+func GetStatus(ctx context.Context) (*Status, error) {
+	cacheRoot := rootCacheDir()
+	rc, err := readRemoteConfig(cacheRoot)
+	if err != nil {
+		return nil, err
+	}
+	local, _, err := openLocal(cacheRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer local.Close()
+	meta, err := local.GetMeta()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting trusted meta")
+	}
+
+	_, statErr := os.Stat(filepath.Join(cacheRoot, "tuf.db"))
+	status := &Status{
+		Mirror:   rc.Mirror,
+		Offline:  rc.Offline || offline(),
+		Cached:   statErr == nil,
+		Metadata: make(map[string]MetadataStatus, len(topLevelMetadata)),
+	}
+	for _, name := range topLevelMetadata {
+		raw, ok := meta[name]
+		if !ok {
+			continue
+		}
+		st, err := metadataStatus(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", name)
+		}
+		status.Metadata[name] = *st
+	}
+	if raw, ok := meta["targets.json"]; ok {
+		s := &data.Signed{}
+		if err := json.Unmarshal(raw, s); err != nil {
+			return nil, errors.Wrap(err, "parsing targets.json")
+		}
+		targets := &data.Targets{}
+		if err := json.Unmarshal(s.Signed, targets); err != nil {
+			return nil, errors.Wrap(err, "parsing targets.json")
+		}
+		for name := range targets.Targets {
+			status.Targets = append(status.Targets, name)
+		}
+		sort.Strings(status.Targets)
+	}
+	return status, nil
+}
+
+// Refresh updates the TUF metadata and targets cached in TUF_ROOT from the
+// mirror Initialize was given, even in offline mode, if any of the metadata
+// expired, or always if force is set. It returns whether it updated them.
+func Refresh(ctx context.Context, force bool) (bool, error) {
+	cacheRoot := rootCacheDir()
+	rc, err := readRemoteConfig(cacheRoot)
+	if err != nil {
+		return false, err
+	}
+	if !force {
+		if _, err := os.Stat(filepath.Join(cacheRoot, "tuf.db")); err == nil {
+			local, err := localStore(filepath.Join(cacheRoot, "tuf.db"))
+			if err != nil {
+				return false, err
+			}
+			meta, err := local.GetMeta()
+			local.Close()
+			if err != nil {
+				return false, errors.Wrap(err, "getting trusted meta")
+			}
+			if len(expiredMetadata(meta, time.Now())) == 0 {
+				return false, nil
+			}
+		}
+	}
+	if err := initialize(ctx, cacheRoot, rc, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
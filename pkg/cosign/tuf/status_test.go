@@ -0,0 +1,99 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuf
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOfflineStatusAndRefresh(t *testing.T) {
+	td := t.TempDir()
+	t.Setenv("TUF_ROOT", td)
+	t.Setenv(SigstoreNoCache, "false")
+	t.Setenv(SigstoreTufOffline, "")
+	ctx := context.Background()
+	dir, root := newTestRepository(t)
+
+	if err := Initialize(ctx, "file://"+dir, root, true); err != nil {
+		t.Fatalf("Initialize() = %v", err)
+	}
+
+	status, err := GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetStatus() = %v", err)
+	}
+	if status.Mirror != "file://"+dir || !status.Offline || !status.Cached {
+		t.Errorf("GetStatus() = %+v, wanted the offline, cached file://%s mirror", status, dir)
+	}
+	if want := []string{"rekor.pub"}; !reflect.DeepEqual(status.Targets, want) {
+		t.Errorf("GetStatus().Targets = %v, wanted %v", status.Targets, want)
+	}
+	for _, name := range topLevelMetadata {
+		md, ok := status.Metadata[name]
+		if !ok || md.Version != 1 || !md.Expires.After(time.Now()) {
+			t.Errorf("GetStatus().Metadata[%s] = %+v, wanted version 1, not expired", name, md)
+		}
+	}
+
+	tuf, err := NewFromEnv(ctx)
+	if err != nil {
+		t.Fatalf("NewFromEnv() = %v", err)
+	}
+	if b, err := tuf.GetTarget("rekor.pub"); err != nil || string(b) != "rekor key" {
+		t.Errorf("GetTarget(rekor.pub) = %q, %v", b, err)
+	}
+	tuf.Close()
+
+	if updated, err := Refresh(ctx, false); err != nil || updated {
+		t.Errorf("Refresh(false) = %t, %v, wanted no update", updated, err)
+	}
+	if updated, err := Refresh(ctx, true); err != nil || !updated {
+		t.Errorf("Refresh(true) = %t, %v, wanted an update", updated, err)
+	}
+	// Refreshing keeps the mirror and offline mode.
+	if rc, err := readRemoteConfig(td); err != nil || !reflect.DeepEqual(rc, &remoteConfig{Mirror: "file://" + dir, Offline: true}) {
+		t.Errorf("readRemoteConfig() = %+v, %v", rc, err)
+	}
+}
+
+func TestOfflineExpired(t *testing.T) {
+	t.Setenv("TUF_ROOT", t.TempDir())
+	t.Setenv(SigstoreTufOffline, "true")
+
+	// The embedded metadata expired long ago.
+	if _, err := NewFromEnv(context.Background()); !errors.Is(err, ErrExpiredMetadata) {
+		t.Errorf("NewFromEnv() = %v, wanted %v", err, ErrExpiredMetadata)
+	}
+
+	local, err := embeddedLocalStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta, err := local.GetMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := expiredMetadata(meta, time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)); len(got) != 0 {
+		t.Errorf("expiredMetadata(2021-12-01) = %v, wanted none", got)
+	}
+	if got, want := expiredMetadata(meta, time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)), []string{"snapshot.json", "timestamp.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expiredMetadata(2022-02-01) = %v, wanted %v", got, want)
+	}
+}
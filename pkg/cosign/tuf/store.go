@@ -17,14 +17,60 @@ package tuf
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"net/url"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"cloud.google.com/go/storage"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
 	"github.com/theupdateframework/go-tuf/client"
 	"google.golang.org/api/option"
 )
 
+// OCITitleAnnotation names the file of the TUF repository a layer of an OCI
+// artifact holds, see OCIRemoteStore.
+const OCITitleAnnotation = "org.opencontainers.image.title"
+
+// RemoteStoreFromURL returns the remote store mirror refers to:
+//   - http:// and https:// URLs are the base URLs of repositories served over HTTP(S),
+//   - file:// URLs are the paths of repositories on the filesystem,
+//   - oci:// URLs are the references of repositories stored as OCI artifacts,
+//   - gs:// URLs, and mirrors without a scheme, are GCS buckets.
+func RemoteStoreFromURL(ctx context.Context, mirror string) (client.RemoteStore, error) {
+	scheme, rest := "gs", mirror
+	if i := strings.Index(mirror, "://"); i >= 0 {
+		scheme, rest = mirror[:i], mirror[i+len("://"):]
+	}
+	switch scheme {
+	case "http", "https":
+		if _, err := url.ParseRequestURI(mirror); err != nil {
+			return nil, errors.Wrapf(err, "parsing TUF mirror %s", mirror)
+		}
+		return client.HTTPRemoteStore(mirror, nil, nil)
+	case "file":
+		return FileRemoteStore(rest)
+	case "oci":
+		ref, err := name.ParseReference(rest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing TUF mirror %s", mirror)
+		}
+		return OCIRemoteStore(ctx, ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	case "gs":
+		return GcsRemoteStore(ctx, rest, nil, nil)
+	default:
+		return nil, fmt.Errorf("unsupported TUF mirror %s, expected a http://, https://, file://, oci:// or gs:// URL", mirror)
+	}
+}
+
 type GcsRemoteOptions struct {
 	MetadataPath string
 	TargetsPath  string
@@ -76,3 +122,121 @@ func (h *gcsRemoteStore) get(s string) (io.ReadCloser, int64, error) {
 	}
 	return rc, attrs.Size, nil
 }
+
+type fileRemoteStore struct {
+	root string
+}
+
+// FileRemoteStore is a remote store for TUF metadata in the directory root,
+// laid out like a repository served over HTTP: metadata at the top and targets
+// in the targets subdirectory. It is meant for air-gapped environments.
+func FileRemoteStore(root string) (client.RemoteStore, error) {
+	fi, err := os.Stat(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening TUF repository")
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("TUF repository %s is not a directory", root)
+	}
+	return &fileRemoteStore{root: root}, nil
+}
+
+func (f *fileRemoteStore) GetMeta(name string) (io.ReadCloser, int64, error) {
+	return f.get(name)
+}
+
+func (f *fileRemoteStore) GetTarget(name string) (io.ReadCloser, int64, error) {
+	return f.get(path.Join("targets", name))
+}
+
+func (f *fileRemoteStore) get(s string) (io.ReadCloser, int64, error) {
+	// Clean the path as an absolute one so it can't escape the root.
+	fp := filepath.Join(f.root, filepath.FromSlash(path.Clean("/"+s)))
+	fd, err := os.Open(fp)
+	if os.IsNotExist(err) {
+		return nil, 0, client.ErrNotFound{File: s}
+	} else if err != nil {
+		return nil, 0, err
+	}
+	fi, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return nil, 0, err
+	}
+	return fd, fi.Size(), nil
+}
+
+type ociRemoteStore struct {
+	ref  name.Reference
+	opts []remote.Option
+
+	once  sync.Once
+	img   v1.Image
+	files map[string]v1.Hash
+	err   error
+}
+
+// OCIRemoteStore is a remote store for TUF metadata in the OCI artifact ref
+// refers to. Each of its layers holds a file of the repository, named by its
+// OCITitleAnnotation annotation: metadata like root.json at the top, and
+// targets like targets/fulcio.crt.pem. The artifact is only fetched once a
+// file is read, so that clients which end up not updating stay offline.
+func OCIRemoteStore(ctx context.Context, ref name.Reference, opts ...remote.Option) (client.RemoteStore, error) {
+	return &ociRemoteStore{ref: ref, opts: append(opts, remote.WithContext(ctx))}, nil
+}
+
+// load fetches the manifest of the artifact, on the first call only.
+func (o *ociRemoteStore) load() error {
+	o.once.Do(func() {
+		img, err := remote.Image(o.ref, o.opts...)
+		if err != nil {
+			o.err = errors.Wrapf(err, "fetching TUF repository %s", o.ref)
+			return
+		}
+		m, err := img.Manifest()
+		if err != nil {
+			o.err = errors.Wrapf(err, "fetching TUF repository %s", o.ref)
+			return
+		}
+		o.files = make(map[string]v1.Hash, len(m.Layers))
+		for _, l := range m.Layers {
+			if title := l.Annotations[OCITitleAnnotation]; title != "" {
+				o.files[title] = l.Digest
+			}
+		}
+		o.img = img
+	})
+	return o.err
+}
+
+func (o *ociRemoteStore) GetMeta(name string) (io.ReadCloser, int64, error) {
+	return o.get(name)
+}
+
+func (o *ociRemoteStore) GetTarget(name string) (io.ReadCloser, int64, error) {
+	return o.get(path.Join("targets", name))
+}
+
+func (o *ociRemoteStore) get(s string) (io.ReadCloser, int64, error) {
+	if err := o.load(); err != nil {
+		return nil, 0, err
+	}
+	h, ok := o.files[strings.TrimPrefix(path.Clean("/"+s), "/")]
+	if !ok {
+		return nil, 0, client.ErrNotFound{File: s}
+	}
+	l, err := o.img.LayerByDigest(h)
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := l.Size()
+	if err != nil {
+		return nil, 0, err
+	}
+	// The files are stored as is, not compressed.
+	rc, err := l.Compressed()
+	if err != nil {
+		return nil, 0, err
+	}
+	return rc, size, nil
+}
@@ -0,0 +1,186 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tuf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	gtuf "github.com/theupdateframework/go-tuf"
+	"github.com/theupdateframework/go-tuf/client"
+)
+
+// newTestRepository creates a TUF repository with a single target, returning
+// the directory it can be served from and its root.json.
+func newTestRepository(t *testing.T) (string, []byte) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := gtuf.NewRepo(gtuf.FileSystemStore(dir, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Init(false); err != nil {
+		t.Fatal(err)
+	}
+	for _, role := range []string{"root", "targets", "snapshot", "timestamp"} {
+		if _, err := repo.GenKey(role); err != nil {
+			t.Fatalf("GenKey(%s) = %v", role, err)
+		}
+	}
+	staged := filepath.Join(dir, "staged", "targets")
+	if err := os.MkdirAll(staged, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(staged, "rekor.pub"), []byte("rekor key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddTarget("rekor.pub", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Timestamp(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	served := filepath.Join(dir, "repository")
+	root, err := os.ReadFile(filepath.Join(served, "root.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return served, root
+}
+
+func TestRemoteStores(t *testing.T) {
+	ctx := context.Background()
+	dir, root := newTestRepository(t)
+
+	fileServer := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer fileServer.Close()
+
+	reg := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer reg.Close()
+	u, err := url.Parse(reg.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(fmt.Sprintf("%s/tuf/repository:latest", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PushRepository(ctx, filepath.Dir(dir), ref); err != nil {
+		t.Fatalf("PushRepository() = %v", err)
+	}
+
+	for _, mirror := range []string{
+		fileServer.URL,
+		"file://" + dir,
+		"oci://" + ref.String(),
+	} {
+		t.Run(strings.SplitN(mirror, ":", 2)[0], func(t *testing.T) {
+			remote, err := RemoteStoreFromURL(ctx, mirror)
+			if err != nil {
+				t.Fatalf("RemoteStoreFromURL() = %v", err)
+			}
+			if _, _, err := remote.GetMeta("missing.json"); !isNotFound(err) {
+				t.Errorf("GetMeta(missing.json) = %v, wanted not found", err)
+			}
+			if _, _, err := remote.GetTarget("missing.pem"); !isNotFound(err) {
+				t.Errorf("GetTarget(missing.pem) = %v, wanted not found", err)
+			}
+
+			rootKeys, rootThreshold, err := getRootKeys(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := client.NewClient(client.MemoryLocalStore(), remote)
+			if err := c.Init(rootKeys, rootThreshold); err != nil {
+				t.Fatalf("Init() = %v", err)
+			}
+			if _, err := c.Update(); err != nil {
+				t.Fatalf("Update() = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := downloadRemoteTarget("rekor.pub", c, &buf); err != nil {
+				t.Fatalf("downloading rekor.pub = %v", err)
+			}
+			if got := buf.String(); got != "rekor key" {
+				t.Errorf("rekor.pub = %q, wanted %q", got, "rekor key")
+			}
+		})
+	}
+}
+
+func isNotFound(err error) bool {
+	_, ok := err.(client.ErrNotFound)
+	return ok
+}
+
+func TestRemoteStoreFromURL(t *testing.T) {
+	ctx := context.Background()
+	for _, mirror := range []string{"ftp://example.com/repository", "file:///does/not/exist", "oci://in@valid"} {
+		if _, err := RemoteStoreFromURL(ctx, mirror); err == nil {
+			t.Errorf("RemoteStoreFromURL(%s) succeeded", mirror)
+		}
+	}
+
+	// OCI repositories are only fetched once read, so unreachable ones fail then.
+	remote, err := RemoteStoreFromURL(ctx, "oci://127.0.0.1:1/tuf/repository:latest")
+	if err != nil {
+		t.Fatalf("RemoteStoreFromURL() = %v", err)
+	}
+	if _, _, err := remote.GetMeta("root.json"); err == nil || isNotFound(err) {
+		t.Errorf("GetMeta(root.json) = %v, wanted a fetch error", err)
+	}
+}
+
+func TestInitializeMirror(t *testing.T) {
+	td := t.TempDir()
+	t.Setenv("TUF_ROOT", td)
+	t.Setenv(SigstoreNoCache, "true")
+	ctx := context.Background()
+	dir, root := newTestRepository(t)
+
+	if rc, err := readRemoteConfig(td); err != nil || rc.Mirror != DefaultRemoteRoot {
+		t.Errorf("readRemoteConfig() = %+v, %v, wanted %s", rc, err, DefaultRemoteRoot)
+	}
+	if err := Initialize(ctx, "file://"+dir, root, false); err != nil {
+		t.Fatalf("Initialize() = %v", err)
+	}
+	if rc, err := readRemoteConfig(td); err != nil || rc.Mirror != "file://"+dir {
+		t.Errorf("readRemoteConfig() = %+v, %v, wanted file://%s", rc, err, dir)
+	}
+
+	// Without a root, the one cached by the previous initialization is trusted.
+	if err := Initialize(ctx, "file://"+dir, nil, false); err != nil {
+		t.Errorf("Initialize() with the cached root = %v", err)
+	}
+}
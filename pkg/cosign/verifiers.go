@@ -19,7 +19,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/pkg/errors"
@@ -54,6 +56,73 @@ func SimpleClaimVerifier(sig oci.Signature, imageDigest v1.Hash, annotations map
 	return nil
 }
 
+// TimestampClaim is the optional claim of SimpleContainerImage payloads
+// holding the signing time, in seconds since the Unix epoch.
+const TimestampClaim = "timestamp"
+
+// maxClockSkew is how far in the future a timestamp claim may be.
+const maxClockSkew = 5 * time.Minute
+
+// verifyPayloadClaims verifies the docker-reference, annotations and timestamp
+// claims of the SimpleContainerImage payload of sig, as required by co.
+func verifyPayloadClaims(sig oci.Signature, co *CheckOpts) error {
+	if len(co.IdentityRepositories) == 0 && co.MaxAge == 0 && len(co.AnnotationMatchers) == 0 {
+		return nil
+	}
+	p, err := sig.Payload()
+	if err != nil {
+		return err
+	}
+	ss := &payload.SimpleContainerImage{}
+	if err := json.Unmarshal(p, ss); err != nil {
+		return err
+	}
+
+	if len(co.IdentityRepositories) > 0 {
+		if err := verifyDockerReference(ss.Critical.Identity.DockerReference, co.IdentityRepositories); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range co.AnnotationMatchers {
+		if err := m.Match(ss.Optional); err != nil {
+			return err
+		}
+	}
+
+	if co.MaxAge != 0 {
+		ts, ok := ss.Optional[TimestampClaim].(float64)
+		if !ok {
+			return fmt.Errorf("missing or invalid %s claim", TimestampClaim)
+		}
+		signed := time.Unix(int64(ts), 0)
+		now := time.Now()
+		if signed.After(now.Add(maxClockSkew)) {
+			return fmt.Errorf("signature timestamp %s is in the future", signed.UTC().Format(time.RFC3339))
+		}
+		if now.Sub(signed) > co.MaxAge {
+			return fmt.Errorf("signature timestamp %s is older than %s", signed.UTC().Format(time.RFC3339), co.MaxAge)
+		}
+	}
+	return nil
+}
+
+// verifyDockerReference checks that a docker-reference claim, which other
+// signers may write with a tag or digest, names one of repos.
+func verifyDockerReference(ref string, repos []name.Repository) error {
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return errors.Wrapf(err, "invalid docker-reference claim %q", ref)
+	}
+	found := parsed.Context()
+	for _, repo := range repos {
+		if found.Name() == repo.Name() {
+			return nil
+		}
+	}
+	return fmt.Errorf("docker-reference claim %s does not match the verified repository", found.Name())
+}
+
 // IntotoSubjectClaimVerifier verifies that sig.Payload() is an Intoto statement which references the given image digest.
 func IntotoSubjectClaimVerifier(sig oci.Signature, imageDigest v1.Hash, _ map[string]interface{}) error {
 	p, err := sig.Payload()
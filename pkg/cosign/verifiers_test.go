@@ -0,0 +1,179 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+func mustRepository(t *testing.T, s string) name.Repository {
+	t.Helper()
+	repo, err := name.NewRepository(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func mustGlob(t *testing.T, key, glob string) AnnotationMatcher {
+	t.Helper()
+	m, err := GlobAnnotationMatcher(key, glob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func signedPayload(t *testing.T, sv signature.SignerVerifier, image string, optional map[string]interface{}) oci.Signature {
+	t.Helper()
+	d, err := name.NewDigest(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := (&payload.Cosign{Image: d, Annotations: optional}).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sv.SignMessage(bytes.NewReader(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := static.NewSignature(p, base64.StdEncoding.EncodeToString(sig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestVerifyPayloadClaims(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const digest = "@sha256:6105d6cc76af400325e94d588ce511be5bfdbb73b437dc51eca43917d7a43e3d"
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name     string
+		image    string
+		optional map[string]interface{}
+		co       CheckOpts
+		wantErr  bool
+	}{{
+		name:  "no claims required",
+		image: "example.com/other/image" + digest,
+	}, {
+		name:  "matching repository",
+		image: "example.com/my/image" + digest,
+		co:    CheckOpts{IdentityRepositories: []name.Repository{mustRepository(t, "example.com/my/image")}},
+	}, {
+		name:  "matching docker hub repository",
+		image: "busybox" + digest,
+		co:    CheckOpts{IdentityRepositories: []name.Repository{mustRepository(t, "index.docker.io/library/busybox")}},
+	}, {
+		name:  "matching alias",
+		image: "upstream.example.com/my/image" + digest,
+		co: CheckOpts{IdentityRepositories: []name.Repository{
+			mustRepository(t, "mirror.example.com/my/image"),
+			mustRepository(t, "upstream.example.com/my/image"),
+		}},
+	}, {
+		name:    "copied from another repository",
+		image:   "example.com/other/image" + digest,
+		co:      CheckOpts{IdentityRepositories: []name.Repository{mustRepository(t, "example.com/my/image")}},
+		wantErr: true,
+	}, {
+		name:     "recent timestamp",
+		image:    "example.com/my/image" + digest,
+		optional: map[string]interface{}{TimestampClaim: now - 60},
+		co:       CheckOpts{MaxAge: time.Hour},
+	}, {
+		name:     "old timestamp",
+		image:    "example.com/my/image" + digest,
+		optional: map[string]interface{}{TimestampClaim: now - 7200},
+		co:       CheckOpts{MaxAge: time.Hour},
+		wantErr:  true,
+	}, {
+		name:     "future timestamp",
+		image:    "example.com/my/image" + digest,
+		optional: map[string]interface{}{TimestampClaim: now + 3600},
+		co:       CheckOpts{MaxAge: time.Hour},
+		wantErr:  true,
+	}, {
+		name:    "missing timestamp",
+		image:   "example.com/my/image" + digest,
+		co:      CheckOpts{MaxAge: time.Hour},
+		wantErr: true,
+	}, {
+		name:     "matching annotation glob",
+		image:    "example.com/my/image" + digest,
+		optional: map[string]interface{}{"dev.sigstore.cosign/ci-run-url": "https://github.com/my/repo/actions/runs/42"},
+		co:       CheckOpts{AnnotationMatchers: []AnnotationMatcher{mustGlob(t, "dev.sigstore.cosign/ci-run-url", "https://github.com/my/repo/*")}},
+	}, {
+		name:     "mismatching annotation glob",
+		image:    "example.com/my/image" + digest,
+		optional: map[string]interface{}{"dev.sigstore.cosign/ci-run-url": "https://github.com/other/repo/actions/runs/42"},
+		co:       CheckOpts{AnnotationMatchers: []AnnotationMatcher{mustGlob(t, "dev.sigstore.cosign/ci-run-url", "https://github.com/my/repo/*")}},
+		wantErr:  true,
+	}, {
+		name:    "missing annotation",
+		image:   "example.com/my/image" + digest,
+		co:      CheckOpts{AnnotationMatchers: []AnnotationMatcher{mustGlob(t, "dev.sigstore.cosign/ci-run-url", "*")}},
+		wantErr: true,
+	}, {
+		name:     "invalid timestamp",
+		image:    "example.com/my/image" + digest,
+		optional: map[string]interface{}{TimestampClaim: "yesterday"},
+		co:       CheckOpts{MaxAge: time.Hour},
+		wantErr:  true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sig := signedPayload(t, sv, test.image, test.optional)
+			test.co.SigVerifier = sv
+			h, err := v1.NewHash(digest[1:])
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = VerifyImageSignature(context.Background(), sig, h, &test.co)
+			if test.wantErr && err == nil {
+				t.Error("VerifyImageSignature() = nil, wanted error")
+			} else if !test.wantErr && err != nil {
+				t.Errorf("VerifyImageSignature() = %v", err)
+			}
+		})
+	}
+}
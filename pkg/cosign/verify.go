@@ -39,6 +39,7 @@ import (
 	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/pkg/errors"
 
 	ssldsse "github.com/secure-systems-lab/go-securesystemslib/dsse"
@@ -61,6 +62,9 @@ type CheckOpts struct {
 
 	// Annotations optionally specifies image signature annotations to verify.
 	Annotations map[string]interface{}
+	// AnnotationMatchers optionally require image signature annotations to
+	// match patterns, rather than to be equal to Annotations.
+	AnnotationMatchers []AnnotationMatcher
 	// ClaimVerifier, if provided, verifies claims present in the oci.Signature.
 	ClaimVerifier func(sig oci.Signature, imageDigest v1.Hash, annotations map[string]interface{}) error
 
@@ -74,13 +78,43 @@ type CheckOpts struct {
 
 	// RootCerts are the root CA certs used to verify a signature's chained certificate.
 	RootCerts *x509.CertPool
+	// IntermediateCerts are optional intermediate CA certs used to chain a signature's certificate to RootCerts.
+	IntermediateCerts *x509.CertPool
 	// CertEmail is the email expected for a certificate to be valid. The empty string means any certificate can be valid.
 	CertEmail string
 	// CertOidcIssuer is the OIDC issuer expected for a certificate to be valid. The empty string means any certificate can be valid.
 	CertOidcIssuer string
 
+	// PIVAttestation, if set, requires signatures to carry a PIV attestation
+	// proving the signing key was generated on a hardware token.
+	PIVAttestation *PIVAttestationOpts
+
 	// SignatureRef is the reference to the signature file
 	SignatureRef string
+
+	// ConfigMediaType and LayerMediaType, if set, require the signed manifest
+	// to have a config and a layer of these media types, e.g. to only accept
+	// a WASM module rather than any container image.
+	ConfigMediaType ggcrtypes.MediaType
+	LayerMediaType  ggcrtypes.MediaType
+
+	// IdentityRepositories, if set, requires the critical.identity.docker-reference
+	// claim of signature payloads to name one of these repositories, e.g. the
+	// repository being verified and its mirrors, so that a signature copied
+	// from another repository is rejected.
+	IdentityRepositories []name.Repository
+	// MaxAge, if non-zero, requires signature payloads to carry an
+	// optional.timestamp claim no older than MaxAge.
+	MaxAge time.Duration
+
+	// LegacySHA256, if set, also accepts the SHA-256 signatures cosign made
+	// with P-384 and P-521 keys before signing with the hash algorithm matching
+	// the key, see LegacySHA256Verifier.
+	LegacySHA256 bool
+
+	// RevocationList, if set, rejects the signatures and attestations it
+	// revokes. It must have been verified, see VerifyRevocationList.
+	RevocationList *RevocationList
 }
 
 func getSignedEntity(signedImgRef name.Reference, regClientOpts []ociremote.Option) (oci.SignedEntity, v1.Hash, error) {
@@ -112,6 +146,37 @@ func verifyOCISignature(ctx context.Context, verifier signature.Verifier, sig oc
 	return verifier.VerifySignature(bytes.NewReader(signature), bytes.NewReader(payload), options.WithContext(ctx))
 }
 
+// KeyRing is implemented by verifiers accepting the signatures of any of
+// several keys, e.g. the current and rotated keys of a key pair.
+type KeyRing interface {
+	Verifiers() []signature.Verifier
+}
+
+// SigningVerifier calls verify with verifier, or if it is a key ring, with
+// each of its keys, returning the verifier of the key that was accepted.
+func SigningVerifier(verifier signature.Verifier, verify func(signature.Verifier) error) (signature.Verifier, error) {
+	ring, ok := verifier.(KeyRing)
+	if !ok {
+		return verifier, verify(verifier)
+	}
+	err := errors.New("empty key ring")
+	for _, v := range ring.Verifiers() {
+		if err = verify(v); err == nil {
+			return v, nil
+		}
+	}
+	return nil, err
+}
+
+// hashVerifier returns verifier, also accepting legacy SHA-256 signatures if
+// co.LegacySHA256 is set.
+func hashVerifier(verifier signature.Verifier, co *CheckOpts) (signature.Verifier, error) {
+	if !co.LegacySHA256 {
+		return verifier, nil
+	}
+	return LegacySHA256Verifier(verifier)
+}
+
 // For unit testing
 type payloader interface {
 	Payload() ([]byte, error)
@@ -142,13 +207,13 @@ func verifyOCIAttestation(_ context.Context, verifier signature.Verifier, att pa
 // ValidateAndUnpackCert creates a Verifier from a certificate. Veries that the certificate
 // chains up to a trusted root. Optionally verifies the subject of the certificate.
 func ValidateAndUnpackCert(cert *x509.Certificate, co *CheckOpts) (signature.Verifier, error) {
-	verifier, err := signature.LoadECDSAVerifier(cert.PublicKey.(*ecdsa.PublicKey), crypto.SHA256)
+	verifier, err := LoadVerifier(cert.PublicKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid certificate found on signature")
 	}
 
 	// Now verify the cert, then the signature.
-	if err := TrustedCert(cert, co.RootCerts); err != nil {
+	if err := trustedCert(cert, co.RootCerts, co.IntermediateCerts); err != nil {
 		return nil, err
 	}
 	if co.CertEmail != "" {
@@ -178,7 +243,21 @@ func ValidateAndUnpackCert(cert *x509.Certificate, co *CheckOpts) (signature.Ver
 	return verifier, nil
 }
 
-func tlogValidatePublicKey(ctx context.Context, rekorClient *client.Rekor, pub crypto.PublicKey, sig oci.Signature) error {
+// findSignatureTlogEntry returns the UUID of the transparency log entry of a
+// signature, which may be a legacy SHA-256 one if legacySHA256 is set.
+func findSignatureTlogEntry(ctx context.Context, rekorClient *client.Rekor, b64sig string, payload, pemBytes []byte, legacySHA256 bool) (string, error) {
+	uuid, _, err := FindTlogEntry(ctx, rekorClient, b64sig, payload, pemBytes)
+	var tlerr *TransparencyLogError
+	if err == nil || !legacySHA256 || errors.As(err, &tlerr) {
+		return uuid, err
+	}
+	if uuid, _, lerr := FindLegacySHA256TlogEntry(ctx, rekorClient, b64sig, payload, pemBytes); lerr == nil {
+		return uuid, nil
+	}
+	return "", err
+}
+
+func tlogValidatePublicKey(ctx context.Context, rekorClient *client.Rekor, pub crypto.PublicKey, sig oci.Signature, legacySHA256 bool) error {
 	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(pub)
 	if err != nil {
 		return err
@@ -191,11 +270,11 @@ func tlogValidatePublicKey(ctx context.Context, rekorClient *client.Rekor, pub c
 	if err != nil {
 		return err
 	}
-	_, _, err = FindTlogEntry(ctx, rekorClient, b64sig, payload, pemBytes)
+	_, err = findSignatureTlogEntry(ctx, rekorClient, b64sig, payload, pemBytes, legacySHA256)
 	return err
 }
 
-func tlogValidateCertificate(ctx context.Context, rekorClient *client.Rekor, sig oci.Signature) error {
+func tlogValidateCertificate(ctx context.Context, rekorClient *client.Rekor, sig oci.Signature, legacySHA256 bool) error {
 	cert, err := sig.Cert()
 	if err != nil {
 		return err
@@ -212,7 +291,7 @@ func tlogValidateCertificate(ctx context.Context, rekorClient *client.Rekor, sig
 	if err != nil {
 		return err
 	}
-	uuid, _, err := FindTlogEntry(ctx, rekorClient, b64sig, payload, pemBytes)
+	uuid, err := findSignatureTlogEntry(ctx, rekorClient, b64sig, payload, pemBytes, legacySHA256)
 	if err != nil {
 		return err
 	}
@@ -248,6 +327,9 @@ func VerifyImageSignatures(ctx context.Context, signedImgRef name.Reference, co
 	if err != nil {
 		return nil, false, err
 	}
+	if err := checkMediaTypes(se, co); err != nil {
+		return nil, false, err
+	}
 
 	var sigs oci.Signatures
 	sigRef := co.SignatureRef
@@ -303,6 +385,14 @@ func VerifyLocalImageSignatures(ctx context.Context, path string, co *CheckOpts)
 	default:
 		return nil, false, errors.New("must verify either an image index or image")
 	}
+	if i != nil {
+		err = checkMediaTypes(i, co)
+	} else {
+		err = checkMediaTypes(ii, co)
+	}
+	if err != nil {
+		return nil, false, err
+	}
 
 	sigs, err := se.Signatures()
 	if err != nil {
@@ -312,19 +402,43 @@ func VerifyLocalImageSignatures(ctx context.Context, path string, co *CheckOpts)
 	return verifySignatures(ctx, sigs, h, co)
 }
 
+// VerifyEntitySignatures verifies the signatures attached to an already
+// resolved entity, e.g. one read from a saved, local image, returning the
+// verified signatures.
+// If there were no valid signatures, we return an error.
+func VerifyEntitySignatures(ctx context.Context, se oci.SignedEntity, co *CheckOpts) (checkedSignatures []oci.Signature, bundleVerified bool, err error) {
+	// Enforce this up front.
+	if co.RootCerts == nil && co.SigVerifier == nil {
+		return nil, false, errors.New("one of verifier or root certs is required")
+	}
+
+	h, err := se.(interface{ Digest() (v1.Hash, error) }).Digest()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := checkMediaTypes(se, co); err != nil {
+		return nil, false, err
+	}
+	sigs, err := se.Signatures()
+	if err != nil {
+		return nil, false, err
+	}
+	return verifySignatures(ctx, sigs, h, co)
+}
+
 func verifySignatures(ctx context.Context, sigs oci.Signatures, h v1.Hash, co *CheckOpts) (checkedSignatures []oci.Signature, bundleVerified bool, err error) {
 	sl, err := sigs.Get()
 	if err != nil {
 		return nil, false, err
 	}
 
-	validationErrs := []string{}
+	validationErrs := []error{}
 
 	for _, sig := range sl {
 		verified, err := VerifyImageSignature(ctx, sig, h, co)
 		bundleVerified = bundleVerified || verified
 		if err != nil {
-			validationErrs = append(validationErrs, err.Error())
+			validationErrs = append(validationErrs, err)
 			continue
 		}
 
@@ -332,7 +446,7 @@ func verifySignatures(ctx context.Context, sigs oci.Signatures, h v1.Hash, co *C
 		checkedSignatures = append(checkedSignatures, sig)
 	}
 	if len(checkedSignatures) == 0 {
-		return nil, false, fmt.Errorf("no matching signatures:\n%s", strings.Join(validationErrs, "\n "))
+		return nil, false, &NoMatchingSignaturesError{Errs: validationErrs}
 	}
 	return checkedSignatures, bundleVerified, nil
 }
@@ -355,16 +469,37 @@ func VerifyImageSignature(ctx context.Context, sig oci.Signature, h v1.Hash, co
 		}
 	}
 
-	if err := verifyOCISignature(ctx, verifier, sig); err != nil {
+	// Checks against the signing key need the key of a ring that made the signature.
+	verifier, err = SigningVerifier(verifier, func(v signature.Verifier) error {
+		v, err := hashVerifier(v, co)
+		if err != nil {
+			return err
+		}
+		return verifyOCISignature(ctx, v, sig)
+	})
+	if err != nil {
 		return bundleVerified, err
 	}
 
+	if co.PIVAttestation != nil {
+		pub, err := verifier.PublicKey(co.PKOpts...)
+		if err != nil {
+			return bundleVerified, err
+		}
+		if _, err := VerifyPIVAttestation(sig, pub, co.PIVAttestation); err != nil {
+			return bundleVerified, err
+		}
+	}
+
 	// We can't check annotations without claims, both require unmarshalling the payload.
 	if co.ClaimVerifier != nil {
 		if err := co.ClaimVerifier(sig, h, co.Annotations); err != nil {
 			return bundleVerified, err
 		}
 	}
+	if err := verifyPayloadClaims(sig, co); err != nil {
+		return bundleVerified, err
+	}
 
 	bundleVerified, err = VerifyBundle(ctx, sig)
 	if err != nil && co.RekorClient == nil {
@@ -373,17 +508,19 @@ func VerifyImageSignature(ctx context.Context, sig oci.Signature, h v1.Hash, co
 
 	if !bundleVerified && co.RekorClient != nil {
 		if co.SigVerifier != nil {
-			pub, err := co.SigVerifier.PublicKey(co.PKOpts...)
+			pub, err := verifier.PublicKey(co.PKOpts...)
 			if err != nil {
 				return bundleVerified, err
 			}
-			return bundleVerified, tlogValidatePublicKey(ctx, co.RekorClient, pub, sig)
+			if err := tlogValidatePublicKey(ctx, co.RekorClient, pub, sig, co.LegacySHA256); err != nil {
+				return bundleVerified, err
+			}
+		} else if err := tlogValidateCertificate(ctx, co.RekorClient, sig, co.LegacySHA256); err != nil {
+			return bundleVerified, err
 		}
-
-		return bundleVerified, tlogValidateCertificate(ctx, co.RekorClient, sig)
 	}
 
-	return bundleVerified, nil
+	return bundleVerified, checkRevocation(sig, verifier, bundleVerified, co)
 }
 
 func loadSignatureFromFile(sigRef string, signedImgRef name.Reference, co *CheckOpts) (oci.Signatures, error) {
@@ -493,6 +630,27 @@ func VerifyLocalImageAttestations(ctx context.Context, path string, co *CheckOpt
 	return verifyImageAttestations(ctx, atts, h, co)
 }
 
+// VerifyEntityAttestations verifies the attestations attached to an already
+// resolved entity, e.g. one read from a saved, local image, returning the
+// verified attestations.
+// If there were no valid attestations, we return an error.
+func VerifyEntityAttestations(ctx context.Context, se oci.SignedEntity, co *CheckOpts) (checkedAttestations []oci.Signature, bundleVerified bool, err error) {
+	// Enforce this up front.
+	if co.RootCerts == nil && co.SigVerifier == nil {
+		return nil, false, errors.New("one of verifier or root certs is required")
+	}
+
+	h, err := se.(interface{ Digest() (v1.Hash, error) }).Digest()
+	if err != nil {
+		return nil, false, err
+	}
+	atts, err := se.Attestations()
+	if err != nil {
+		return nil, false, err
+	}
+	return verifyImageAttestations(ctx, atts, h, co)
+}
+
 func verifyImageAttestations(ctx context.Context, atts oci.Signatures, h v1.Hash, co *CheckOpts) (checkedAttestations []oci.Signature, bundleVerified bool, err error) {
 	sl, err := atts.Get()
 	if err != nil {
@@ -518,7 +676,14 @@ func verifyImageAttestations(ctx context.Context, atts oci.Signatures, h v1.Hash
 				}
 			}
 
-			if err := verifyOCIAttestation(ctx, verifier, att); err != nil {
+			verifier, err := SigningVerifier(verifier, func(v signature.Verifier) error {
+				v, err := hashVerifier(v, co)
+				if err != nil {
+					return err
+				}
+				return verifyOCIAttestation(ctx, v, att)
+			})
+			if err != nil {
 				return err
 			}
 
@@ -537,16 +702,18 @@ func verifyImageAttestations(ctx context.Context, atts oci.Signatures, h v1.Hash
 
 			if !verified && co.RekorClient != nil {
 				if co.SigVerifier != nil {
-					pub, err := co.SigVerifier.PublicKey(co.PKOpts...)
+					pub, err := verifier.PublicKey(co.PKOpts...)
 					if err != nil {
 						return err
 					}
-					return tlogValidatePublicKey(ctx, co.RekorClient, pub, att)
+					if err := tlogValidatePublicKey(ctx, co.RekorClient, pub, att, co.LegacySHA256); err != nil {
+						return err
+					}
+				} else if err := tlogValidateCertificate(ctx, co.RekorClient, att, co.LegacySHA256); err != nil {
+					return err
 				}
-
-				return tlogValidateCertificate(ctx, co.RekorClient, att)
 			}
-			return nil
+			return checkRevocation(att, verifier, verified, co)
 		}(att); err != nil {
 			validationErrs = append(validationErrs, err.Error())
 			continue
@@ -561,6 +728,20 @@ func verifyImageAttestations(ctx context.Context, atts oci.Signatures, h v1.Hash
 	return checkedAttestations, bundleVerified, nil
 }
 
+// VerifyBlobAttestation verifies an attestation whose in-toto statement has
+// the blob with digest h as its subject, e.g. one created by `cosign attest-blob`.
+func VerifyBlobAttestation(ctx context.Context, att oci.Signature, h v1.Hash, co *CheckOpts) (bundleVerified bool, err error) {
+	// Enforce this up front.
+	if co.RootCerts == nil && co.SigVerifier == nil {
+		return false, errors.New("one of verifier or root certs is required")
+	}
+
+	_, bundleVerified, err = verifyImageAttestations(ctx, &fakeOCISignatures{
+		signatures: []oci.Signature{att},
+	}, h, co)
+	return bundleVerified, err
+}
+
 // CheckExpiry confirms the time provided is within the valid period of the cert
 func CheckExpiry(cert *x509.Certificate, it time.Time) error {
 	ft := func(t time.Time) string {
@@ -585,17 +766,7 @@ func VerifyBundle(ctx context.Context, sig oci.Signature) (bool, error) {
 		return false, nil
 	}
 
-	pub, err := GetRekorPub(ctx)
-	if err != nil {
-		return false, errors.Wrap(err, "retrieving rekor public key")
-	}
-
-	rekorPubKey, err := PemToECDSAKey(pub)
-	if err != nil {
-		return false, errors.Wrap(err, "pem to ecdsa")
-	}
-
-	if err := VerifySET(bundle.Payload, bundle.SignedEntryTimestamp, rekorPubKey); err != nil {
+	if err := verifyBundleSET(ctx, bundle); err != nil {
 		return false, err
 	}
 
@@ -621,15 +792,74 @@ func VerifyBundle(ctx context.Context, sig oci.Signature) (bool, error) {
 	}
 
 	alg, bundlehash, err := bundleHash(bundle.Payload.Body.(string), signature)
-	h := sha256.Sum256(payload)
-	payloadHash := hex.EncodeToString(h[:])
-
-	if alg != "sha256" || bundlehash != payloadHash {
+	if err != nil {
 		return false, errors.Wrap(err, "matching bundle to payload")
 	}
+	hashAlgorithm, err := hashAlgorithmForName(alg)
+	if err != nil {
+		return false, nil
+	}
+	payloadHash, err := hashPayload(payload, hashAlgorithm)
+	if err != nil || bundlehash != hex.EncodeToString(payloadHash) {
+		return false, nil
+	}
 	return true, nil
 }
 
+// hashAlgorithmForName is the inverse of HashedRekordAlgorithm.
+func hashAlgorithmForName(alg string) (crypto.Hash, error) {
+	for _, h := range []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		if name, _ := HashedRekordAlgorithm(h); name == alg {
+			return h, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported hash algorithm %q", alg)
+}
+
+// VerifyBundleDigest is like VerifyBundle, for a signature over a payload that is only
+// known by its digest computed with hashAlgorithm, e.g. a blob too large to hold in memory.
+// Unlike VerifyBundle, the entry is always checked against the payload, with or without a certificate.
+func VerifyBundleDigest(ctx context.Context, bundle *cbundle.RekorBundle, cert *x509.Certificate, b64sig string, digest []byte, hashAlgorithm crypto.Hash) error {
+	if err := verifyBundleSET(ctx, bundle); err != nil {
+		return err
+	}
+	if cert != nil {
+		if err := CheckExpiry(cert, time.Unix(bundle.Payload.IntegratedTime, 0)); err != nil {
+			return errors.Wrap(err, "checking expiry on cert")
+		}
+	}
+	body, ok := bundle.Payload.Body.(string)
+	if !ok {
+		return errors.New("unexpected bundle body")
+	}
+	alg, bundlehash, err := bundleHash(body, b64sig)
+	if err != nil {
+		return errors.Wrap(err, "reading bundle hash")
+	}
+	wantAlg, err := HashedRekordAlgorithm(hashAlgorithm)
+	if err != nil {
+		return err
+	}
+	if alg != wantAlg || bundlehash != hex.EncodeToString(digest) {
+		return errors.New("bundle does not match payload")
+	}
+	return nil
+}
+
+func verifyBundleSET(ctx context.Context, bundle *cbundle.RekorBundle) error {
+	pub, err := GetRekorPub(ctx)
+	if err != nil {
+		return &TransparencyLogError{errors.Wrap(err, "retrieving rekor public key")}
+	}
+
+	rekorPubKey, err := PemToECDSAKey(pub)
+	if err != nil {
+		return errors.Wrap(err, "pem to ecdsa")
+	}
+
+	return VerifySET(bundle.Payload, bundle.SignedEntryTimestamp, rekorPubKey)
+}
+
 func bundleHash(bundleBody, signature string) (string, string, error) {
 	var toto models.Intoto
 	var rekord models.Rekord
@@ -710,12 +940,17 @@ func VerifySET(bundlePayload cbundle.RekorPayload, signature []byte, pub *ecdsa.
 }
 
 func TrustedCert(cert *x509.Certificate, roots *x509.CertPool) error {
+	return trustedCert(cert, roots, nil)
+}
+
+func trustedCert(cert *x509.Certificate, roots, intermediates *x509.CertPool) error {
 	if _, err := cert.Verify(x509.VerifyOptions{
 		// THIS IS IMPORTANT: WE DO NOT CHECK TIMES HERE
 		// THE CERTIFICATE IS TREATED AS TRUSTED FOREVER
 		// WE CHECK THAT THE SIGNATURES WERE CREATED DURING THIS WINDOW
-		CurrentTime: cert.NotBefore,
-		Roots:       roots,
+		CurrentTime:   cert.NotBefore,
+		Roots:         roots,
+		Intermediates: intermediates,
 		KeyUsages: []x509.ExtKeyUsage{
 			x509.ExtKeyUsageCodeSigning,
 		},
@@ -15,17 +15,25 @@
 package cosign
 
 import (
+	"bytes"
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"testing"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/pkg/errors"
 	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/cosign/pkg/oci/static"
 	"github.com/sigstore/cosign/pkg/types"
 	"github.com/sigstore/cosign/test"
 	"github.com/sigstore/sigstore/pkg/signature"
@@ -49,6 +57,21 @@ func (m *mockVerifier) VerifySignature(signature, message io.Reader, opts ...sig
 
 var _ signature.Verifier = (*mockVerifier)(nil)
 
+// mockKeyRing accepts the signatures of any of its verifiers.
+type mockKeyRing []signature.Verifier
+
+func (m mockKeyRing) Verifiers() []signature.Verifier {
+	return m
+}
+
+func (m mockKeyRing) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return m[0].PublicKey(opts...)
+}
+
+func (m mockKeyRing) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	return errors.New("keys of a ring are checked one by one")
+}
+
 type mockAttestation struct {
 	payload interface{}
 }
@@ -196,3 +219,51 @@ func TestValidateAndUnpackCertInvalidEmail(t *testing.T) {
 	_, err := ValidateAndUnpackCert(leafCert, co)
 	require.Contains(t, err.Error(), "expected email not found in certificate")
 }
+
+func TestVerifyImageSignatureKeyRing(t *testing.T) {
+	var ring mockKeyRing
+	var signer signature.SignerVerifier
+	for i := 0; i < 2; i++ {
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sv, err := signature.LoadECDSASignerVerifier(priv, crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ring = append(ring, sv)
+		signer = sv
+	}
+
+	payload := []byte("payload")
+	rawSig, err := signer.SignMessage(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := static.NewSignature(payload, base64.StdEncoding.EncodeToString(rawSig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyImageSignature(context.Background(), sig, v1.Hash{}, &CheckOpts{SigVerifier: ring}); err != nil {
+		t.Fatalf("VerifyImageSignature() = %v", err)
+	}
+
+	// Checks against the signing key must see the second key, not the first one of the ring.
+	pub, err := signer.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	co := &CheckOpts{
+		SigVerifier:    ring,
+		RevocationList: &RevocationList{Revocations: []Revocation{{KeyID: fmt.Sprintf("%x", sha256.Sum256(der))}}},
+	}
+	if _, err := VerifyImageSignature(context.Background(), sig, v1.Hash{}, co); err == nil {
+		t.Error("VerifyImageSignature() with the signing key revoked succeeded")
+	}
+}
@@ -16,7 +16,6 @@
 package empty
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -41,7 +40,7 @@ func (se *signedImage) Attestations() (oci.Signatures, error) {
 }
 
 func (se *signedImage) Attachment(name string) (oci.File, error) {
-	return nil, errors.New("no attachments")
+	return nil, fmt.Errorf("%w: %q", oci.ErrAttachmentNotFound, name)
 }
 
 func (se *signedImage) Digest() (v1.Hash, error) {
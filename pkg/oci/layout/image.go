@@ -0,0 +1,73 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"io"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/pkg/oci"
+)
+
+type image struct {
+	v1.Image
+	// root is the index.json of the layout the image was read from.
+	root v1.ImageIndex
+	// target is the digest of the image.
+	target v1.Hash
+}
+
+var _ oci.SignedImage = (*image)(nil)
+
+// Signatures implements oci.SignedImage
+func (i *image) Signatures() (oci.Signatures, error) {
+	return signaturesFor(i.root, sigsAnnotation, i.target)
+}
+
+// Attestations implements oci.SignedImage
+func (i *image) Attestations() (oci.Signatures, error) {
+	return signaturesFor(i.root, attsAnnotation, i.target)
+}
+
+// Attachment implements oci.SignedImage
+func (i *image) Attachment(name string) (oci.File, error) {
+	return attachmentFor(i.root, name, i.target)
+}
+
+type attached struct {
+	oci.SignedImage
+	layer v1.Layer
+}
+
+var _ oci.File = (*attached)(nil)
+
+// FileMediaType implements oci.File
+func (f *attached) FileMediaType() (types.MediaType, error) {
+	return f.layer.MediaType()
+}
+
+// Payload implements oci.File
+func (f *attached) Payload() ([]byte, error) {
+	// attachments are written uncompressed, so use
+	// "Compressed" to access the raw byte stream.
+	rc, err := f.layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
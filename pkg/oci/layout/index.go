@@ -21,7 +21,7 @@ import (
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/sigstore/cosign/pkg/oci"
-	"github.com/sigstore/cosign/pkg/oci/signed"
+	"github.com/sigstore/cosign/pkg/oci/empty"
 )
 
 const (
@@ -30,60 +30,108 @@ const (
 	imageIndexAnnotation = "dev.cosignproject.cosign/imageIndex"
 	sigsAnnotation       = "dev.cosignproject.cosign/sigs"
 	attsAnnotation       = "dev.cosignproject.cosign/atts"
+	attachmentAnnotation = "dev.cosignproject.cosign/attachment"
+
+	// refNameAnnotation records the reference an image or image index was saved from.
+	refNameAnnotation = "org.opencontainers.image.ref.name"
+	// targetAnnotation records the digest of the entity that a set of signatures,
+	// attestations or an attachment belongs to.
+	targetAnnotation = "dev.cosignproject.cosign/target"
+	// attachmentNameAnnotation records the name of an attachment.
+	attachmentNameAnnotation = "dev.cosignproject.cosign/attachmentName"
 )
 
 // SignedImageIndex provides access to a local index reference, and its signatures.
 func SignedImageIndex(path string) (oci.SignedImageIndex, error) {
-	p, err := layout.FromPath(path)
-	if err != nil {
-		return nil, err
-	}
-	ii, err := p.ImageIndex()
+	ii, err := layoutIndex(path)
 	if err != nil {
 		return nil, err
 	}
 	return &index{
 		v1Index: ii,
+		root:    ii,
 	}, nil
 }
 
+// SignedEntities provides access to every image and image index saved
+// in the layout at path, keyed by the reference each was saved from.
+// Layouts written without a reference (e.g. by WriteSignedImage) hold
+// a single entity keyed by the empty string.
+func SignedEntities(path string) (map[string]oci.SignedEntity, error) {
+	ii, err := layoutIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := ii.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	entities := make(map[string]oci.SignedEntity)
+	for _, m := range manifest.Manifests {
+		refName := m.Annotations[refNameAnnotation]
+		var se oci.SignedEntity
+		switch m.Annotations[kindAnnotation] {
+		case imageAnnotation:
+			img, err := ii.Image(m.Digest)
+			if err != nil {
+				return nil, err
+			}
+			se = &image{Image: img, root: ii, target: m.Digest}
+		case imageIndexAnnotation:
+			idx, err := ii.ImageIndex(m.Digest)
+			if err != nil {
+				return nil, err
+			}
+			se = &index{v1Index: idx, root: ii, target: m.Digest}
+		default:
+			continue
+		}
+		if _, ok := entities[refName]; ok {
+			return nil, fmt.Errorf("layout contains more than one entity for reference %q", refName)
+		}
+		entities[refName] = se
+	}
+	return entities, nil
+}
+
+func layoutIndex(path string) (v1.ImageIndex, error) {
+	p, err := layout.FromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.ImageIndex()
+}
+
 // We alias ImageIndex so that we can inline it without the type
 // name colliding with the name of a method it had to implement.
 type v1Index v1.ImageIndex
 
 type index struct {
 	v1Index
+	// root is the index.json of the layout, which lists the signatures,
+	// attestations and attachments of every entity saved in it.
+	root v1.ImageIndex
+	// target is the digest signatures, attestations and attachments
+	// are looked up by. It is empty for the layout's index.json itself,
+	// which resolves them for the first entity saved in the layout.
+	target v1.Hash
 }
 
 var _ oci.SignedImageIndex = (*index)(nil)
 
 // Signatures implements oci.SignedImageIndex
 func (i *index) Signatures() (oci.Signatures, error) {
-	img, err := i.imageByAnnotation(sigsAnnotation)
-	if err != nil {
-		return nil, err
-	}
-	if img == nil {
-		return nil, nil
-	}
-	return &sigs{img}, nil
+	return signaturesFor(i.root, sigsAnnotation, i.target)
 }
 
 // Attestations implements oci.SignedImageIndex
 func (i *index) Attestations() (oci.Signatures, error) {
-	img, err := i.imageByAnnotation(attsAnnotation)
-	if err != nil {
-		return nil, err
-	}
-	if img == nil {
-		return nil, nil
-	}
-	return &sigs{img}, nil
+	return signaturesFor(i.root, attsAnnotation, i.target)
 }
 
-// Attestations implements oci.SignedImage
+// Attachment implements oci.SignedImageIndex
 func (i *index) Attachment(name string) (oci.File, error) {
-	return nil, fmt.Errorf("not yet implemented")
+	return attachmentFor(i.root, name, i.target)
 }
 
 // SignedImage implements oci.SignedImageIndex
@@ -92,63 +140,135 @@ func (i *index) SignedImage(h v1.Hash) (oci.SignedImage, error) {
 	var img v1.Image
 	var err error
 	if h.String() == ":" {
-		img, err = i.imageByAnnotation(imageAnnotation)
-	} else {
-		img, err = i.Image(h)
+		var desc *v1.Descriptor
+		desc, err = i.descriptorByKind(imageAnnotation)
+		if err != nil || desc == nil {
+			return nil, err
+		}
+		h = desc.Digest
 	}
+	img, err = i.Image(h)
 	if err != nil {
 		return nil, err
 	}
-	if img == nil {
-		return nil, nil
+	return &image{
+		Image:  img,
+		root:   i.root,
+		target: h,
+	}, nil
+}
+
+// SignedImageIndex implements oci.SignedImageIndex
+func (i *index) SignedImageIndex(h v1.Hash) (oci.SignedImageIndex, error) {
+	var ii v1.ImageIndex
+	var err error
+	if h.String() == ":" {
+		var desc *v1.Descriptor
+		desc, err = i.descriptorByKind(imageIndexAnnotation)
+		if err != nil || desc == nil {
+			return nil, err
+		}
+		h = desc.Digest
+	}
+	ii, err = i.ImageIndex(h)
+	if err != nil {
+		return nil, err
 	}
-	return signed.Image(img), nil
+	return &index{
+		v1Index: ii,
+		root:    i.root,
+		target:  h,
+	}, nil
 }
 
-// imageByAnnotation searches through all manifests in the index.json
-// and returns the image that has the matching annotation
-func (i *index) imageByAnnotation(annotation string) (v1.Image, error) {
+// descriptorByKind searches through all manifests in the index.json
+// and returns the first one with the matching kind annotation
+func (i *index) descriptorByKind(kind string) (*v1.Descriptor, error) {
 	manifest, err := i.IndexManifest()
 	if err != nil {
 		return nil, err
 	}
 	for _, m := range manifest.Manifests {
-		if val, ok := m.Annotations[kindAnnotation]; ok && val == annotation {
-			return i.Image(m.Digest)
+		if val, ok := m.Annotations[kindAnnotation]; ok && val == kind {
+			m := m
+			return &m, nil
 		}
 	}
 	return nil, nil
 }
 
-func (i *index) imageIndexByAnnotation(annotation string) (v1.ImageIndex, error) {
-	manifest, err := i.IndexManifest()
+// findByTarget searches through all manifests in the layout's index.json
+// and returns the descriptor of the given kind that belongs to target.
+// Layouts written before entities were tagged with their target hold a
+// single entity, so untagged descriptors belong to the first entity.
+func findByTarget(root v1.ImageIndex, target v1.Hash, match func(map[string]string) bool) (*v1.Descriptor, error) {
+	manifest, err := root.IndexManifest()
 	if err != nil {
 		return nil, err
 	}
+	first := v1.Hash{}
+	for _, m := range manifest.Manifests {
+		if kind := m.Annotations[kindAnnotation]; kind == imageAnnotation || kind == imageIndexAnnotation {
+			first = m.Digest
+			break
+		}
+	}
+	if target == (v1.Hash{}) {
+		target = first
+	}
 	for _, m := range manifest.Manifests {
-		if val, ok := m.Annotations[kindAnnotation]; ok && val == annotation {
-			return i.ImageIndex(m.Digest)
+		if !match(m.Annotations) {
+			continue
+		}
+		t, ok := m.Annotations[targetAnnotation]
+		if (!ok && target == first) || t == target.String() {
+			m := m
+			return &m, nil
 		}
 	}
 	return nil, nil
 }
 
-// SignedImageIndex implements oci.SignedImageIndex
-func (i *index) SignedImageIndex(h v1.Hash) (oci.SignedImageIndex, error) {
-	var ii v1.ImageIndex
-	var err error
-	if h.String() == ":" {
-		ii, err = i.imageIndexByAnnotation(imageIndexAnnotation)
-	} else {
-		ii, err = i.ImageIndex(h)
+func signaturesFor(root v1.ImageIndex, kind string, target v1.Hash) (oci.Signatures, error) {
+	desc, err := findByTarget(root, target, func(annotations map[string]string) bool {
+		return annotations[kindAnnotation] == kind
+	})
+	if err != nil {
+		return nil, err
 	}
+	if desc == nil {
+		return empty.Signatures(), nil
+	}
+	img, err := root.Image(desc.Digest)
 	if err != nil {
 		return nil, err
 	}
-	if ii == nil {
-		return nil, nil
+	return &sigs{img}, nil
+}
+
+func attachmentFor(root v1.ImageIndex, name string, target v1.Hash) (oci.File, error) {
+	desc, err := findByTarget(root, target, func(annotations map[string]string) bool {
+		return annotations[kindAnnotation] == attachmentAnnotation && annotations[attachmentNameAnnotation] == name
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &index{
-		v1Index: ii,
+	if desc == nil {
+		return nil, fmt.Errorf("%w: %q", oci.ErrAttachmentNotFound, name)
+	}
+	img, err := root.Image(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	ls, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(ls) != 1 {
+		return nil, fmt.Errorf("expected exactly one layer in attachment, got %d", len(ls))
+	}
+	return &attached{
+		SignedImage: &image{Image: img, root: root, target: desc.Digest},
+		layer:       ls[0],
 	}, nil
 }
@@ -16,13 +16,22 @@
 package layout
 
 import (
+	"context"
+	"fmt"
+	"sort"
+
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/pkg/errors"
 	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/walk"
 )
 
+// AttachmentNames are the attachments that are saved alongside
+// each entity, when present.
+var AttachmentNames = []string{"sbom"}
+
 // WriteSignedImage writes the image and all related signatures, attestations and attachments
 func WriteSignedImage(path string, si oci.SignedImage) error {
 	// First, write an empty index
@@ -30,11 +39,7 @@ func WriteSignedImage(path string, si oci.SignedImage) error {
 	if err != nil {
 		return err
 	}
-	// write the image
-	if err := appendImage(layoutPath, si, imageAnnotation); err != nil {
-		return errors.Wrap(err, "appending signed image")
-	}
-	return writeSignedEntity(layoutPath, si)
+	return appendSignedEntity(layoutPath, "", si)
 }
 
 // WriteSignedImageIndex writes the image index and all related signatures, attestations and attachments
@@ -44,23 +49,72 @@ func WriteSignedImageIndex(path string, si oci.SignedImageIndex) error {
 	if err != nil {
 		return err
 	}
-	// write the image index
-	if err := layoutPath.AppendIndex(si, layout.WithAnnotations(
-		map[string]string{kindAnnotation: imageIndexAnnotation},
-	)); err != nil {
-		return errors.Wrap(err, "appending signed image index")
+	return appendSignedEntity(layoutPath, "", si)
+}
+
+// WriteSignedEntities writes each image or image index, keyed by the reference
+// it was saved from, to a new layout at path. The signatures, attestations and
+// attachments of each entity, and of each entity nested within an image index,
+// are written alongside it.
+func WriteSignedEntities(path string, entities map[string]oci.SignedEntity) error {
+	// First, write an empty index
+	layoutPath, err := layout.Write(path, empty.Index)
+	if err != nil {
+		return err
+	}
+	refNames := make([]string, 0, len(entities))
+	for refName := range entities {
+		refNames = append(refNames, refName)
+	}
+	sort.Strings(refNames)
+	for _, refName := range refNames {
+		if err := appendSignedEntity(layoutPath, refName, entities[refName]); err != nil {
+			return errors.Wrapf(err, "writing %s", refName)
+		}
+	}
+	return nil
+}
+
+func appendSignedEntity(path layout.Path, refName string, se oci.SignedEntity) error {
+	annotations := map[string]string{}
+	if refName != "" {
+		annotations[refNameAnnotation] = refName
+	}
+	switch obj := se.(type) {
+	case oci.SignedImage:
+		annotations[kindAnnotation] = imageAnnotation
+		if err := path.AppendImage(obj, layout.WithAnnotations(annotations)); err != nil {
+			return errors.Wrap(err, "appending signed image")
+		}
+	case oci.SignedImageIndex:
+		annotations[kindAnnotation] = imageIndexAnnotation
+		if err := path.AppendIndex(obj, layout.WithAnnotations(annotations)); err != nil {
+			return errors.Wrap(err, "appending signed image index")
+		}
+	default:
+		return fmt.Errorf("unsupported type: %T", se)
 	}
-	return writeSignedEntity(layoutPath, si)
+
+	// The nested images of an index are written along with it, but their
+	// signatures, attestations and attachments need to be written separately.
+	return walk.SignedEntity(context.Background(), se, func(_ context.Context, se oci.SignedEntity) error {
+		return writeSignedEntity(path, se)
+	})
 }
 
 func writeSignedEntity(path layout.Path, se oci.SignedEntity) error {
+	h, err := se.(interface{ Digest() (v1.Hash, error) }).Digest()
+	if err != nil {
+		return errors.Wrap(err, "getting digest")
+	}
+
 	// write the signatures
 	sigs, err := se.Signatures()
 	if err != nil {
 		return errors.Wrap(err, "getting signatures")
 	}
 	if !isEmpty(sigs) {
-		if err := appendImage(path, sigs, sigsAnnotation); err != nil {
+		if err := appendImage(path, sigs, sigsAnnotation, h); err != nil {
 			return errors.Wrap(err, "appending signatures")
 		}
 	}
@@ -71,22 +125,45 @@ func writeSignedEntity(path layout.Path, se oci.SignedEntity) error {
 		return errors.Wrap(err, "getting atts")
 	}
 	if !isEmpty(atts) {
-		if err := appendImage(path, atts, attsAnnotation); err != nil {
+		if err := appendImage(path, atts, attsAnnotation, h); err != nil {
 			return errors.Wrap(err, "appending atts")
 		}
 	}
-	// TODO (priyawadhwa@) and attachments
+
+	// write attachments
+	for _, name := range AttachmentNames {
+		f, err := se.Attachment(name)
+		if err != nil {
+			if errors.Is(err, oci.ErrAttachmentNotFound) {
+				continue
+			}
+			return errors.Wrapf(err, "getting attachment %s", name)
+		}
+		if err := path.AppendImage(f, layout.WithAnnotations(map[string]string{
+			kindAnnotation:           attachmentAnnotation,
+			attachmentNameAnnotation: name,
+			targetAnnotation:         h.String(),
+		})); err != nil {
+			return errors.Wrapf(err, "appending attachment %s", name)
+		}
+	}
 	return nil
 }
 
 // isEmpty returns true if the signatures or attestations are empty
 func isEmpty(s oci.Signatures) bool {
+	if s == nil {
+		return true
+	}
 	ss, _ := s.Get()
-	return ss == nil
+	return len(ss) == 0
 }
 
-func appendImage(path layout.Path, img v1.Image, annotation string) error {
+func appendImage(path layout.Path, img v1.Image, annotation string, target v1.Hash) error {
 	return path.AppendImage(img, layout.WithAnnotations(
-		map[string]string{kindAnnotation: annotation},
+		map[string]string{
+			kindAnnotation:   annotation,
+			targetAnnotation: target.String(),
+		},
 	))
 }
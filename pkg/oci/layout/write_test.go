@@ -16,11 +16,13 @@
 package layout
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/sigstore/cosign/pkg/oci"
 	"github.com/sigstore/cosign/pkg/oci/mutate"
@@ -90,6 +92,102 @@ func TestReadWrite(t *testing.T) {
 	}
 }
 
+func TestWriteSignedEntities(t *testing.T) {
+	si := randomSignedImage(t)
+	sbom, err := static.NewFile([]byte("sbom"), static.WithLayerMediaType("text/spdx"))
+	if err != nil {
+		t.Fatalf("static.NewFile() = %v", err)
+	}
+	si, err = mutate.AttachFileToImage(si, "sbom", sbom)
+	if err != nil {
+		t.Fatalf("AttachFileToImage() = %v", err)
+	}
+
+	child := randomSignedImage(t)
+	sii := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: child})
+	sig, err := static.NewSignature(nil, "index")
+	if err != nil {
+		t.Fatalf("static.NewSignature() = %v", err)
+	}
+	sii, err = mutate.AttachSignatureToImageIndex(sii, sig)
+	if err != nil {
+		t.Fatalf("AttachSignatureToImageIndex() = %v", err)
+	}
+
+	tmp := t.TempDir()
+	if err := WriteSignedEntities(tmp, map[string]oci.SignedEntity{
+		"example.com/image:v1": si,
+		"example.com/index:v1": sii,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	entities, err := SignedEntities(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("expected 2 entities, got %d", len(entities))
+	}
+
+	gotImage, ok := entities["example.com/image:v1"].(oci.SignedImage)
+	if !ok {
+		t.Fatal("expected a signed image")
+	}
+	compareDigests(t, si, gotImage)
+	checkCount(t, gotImage.Signatures, 6)
+	checkCount(t, gotImage.Attestations, 5)
+	f, err := gotImage.Attachment("sbom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := f.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != "sbom" {
+		t.Fatalf("unexpected sbom payload %q", payload)
+	}
+	if _, err := gotImage.Attachment("missing"); !errors.Is(err, oci.ErrAttachmentNotFound) {
+		t.Fatalf("Attachment(missing) = %v, wanted ErrAttachmentNotFound", err)
+	}
+
+	gotIndex, ok := entities["example.com/index:v1"].(oci.SignedImageIndex)
+	if !ok {
+		t.Fatal("expected a signed image index")
+	}
+	checkCount(t, gotIndex.Signatures, 1)
+	checkCount(t, gotIndex.Attestations, 0)
+
+	// the signatures of the nested image are written alongside the index
+	h, err := child.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotChild, err := gotIndex.SignedImage(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareDigests(t, child, gotChild)
+	checkCount(t, gotChild.Signatures, 6)
+	checkCount(t, gotChild.Attestations, 5)
+}
+
+func checkCount(t *testing.T, get func() (oci.Signatures, error), want int) {
+	t.Helper()
+	s, err := get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sl, err := s.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sl) != want {
+		t.Fatalf("expected %d signatures, got %d", want, len(sl))
+	}
+}
+
 func randomSignedImage(t *testing.T) oci.SignedImage {
 	i, err := random.Image(300 /* byteSize */, 7 /* layers */)
 	if err != nil {
@@ -16,7 +16,6 @@
 package mutate
 
 import (
-	"errors"
 	"fmt"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -80,7 +79,7 @@ func (i *indexWrapper) Attestations() (oci.Signatures, error) {
 
 // Attachment implements oci.SignedImage
 func (*indexWrapper) Attachment(name string) (oci.File, error) {
-	return nil, errors.New("unimplemented")
+	return nil, fmt.Errorf("%w: %q", oci.ErrAttachmentNotFound, name)
 }
 
 // SignedImage implements oci.SignedImageIndex
@@ -252,7 +251,7 @@ func (si *signedImage) Attachment(attName string) (oci.File, error) {
 	if f, ok := si.attachments[attName]; ok {
 		return f, nil
 	}
-	return nil, fmt.Errorf("attachment %q not found", attName)
+	return nil, fmt.Errorf("%w: %q", oci.ErrAttachmentNotFound, attName)
 }
 
 // AttachSignatureToImageIndex attaches the provided signature to the provided image index.
@@ -346,5 +345,5 @@ func (sii *signedImageIndex) Attachment(attName string) (oci.File, error) {
 	if f, ok := sii.attachments[attName]; ok {
 		return f, nil
 	}
-	return nil, fmt.Errorf("attachment %q not found", attName)
+	return nil, fmt.Errorf("%w: %q", oci.ErrAttachmentNotFound, attName)
 }
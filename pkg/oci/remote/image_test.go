@@ -16,12 +16,17 @@
 package remote
 
 import (
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/sigstore/cosign/pkg/oci"
 )
 
 func TestSignedImage(t *testing.T) {
@@ -105,3 +110,48 @@ func TestSignedImageWithAttachment(t *testing.T) {
 		t.Errorf("Payload() = %d bytes, wanted %d", len(payload), 300)
 	}
 }
+
+func TestSignedImageAttachmentErrors(t *testing.T) {
+	ri := remote.Image
+	t.Cleanup(func() {
+		remoteImage = ri
+	})
+
+	ref, err := name.ParseReference("gcr.io/distroless/static:nonroot")
+	if err != nil {
+		t.Fatalf("ParseRef() = %v", err)
+	}
+
+	t.Run("404 is not found", func(t *testing.T) {
+		remoteImage = func(ref name.Reference, options ...remote.Option) (v1.Image, error) {
+			if strings.HasSuffix(ref.Identifier(), ".sbom") {
+				return nil, &transport.Error{StatusCode: http.StatusNotFound}
+			}
+			return random.Image(300 /* byteSize */, 1 /* layers */)
+		}
+		si, err := SignedImage(ref)
+		if err != nil {
+			t.Fatalf("SignedImage() = %v", err)
+		}
+		if _, err := si.Attachment("sbom"); !errors.Is(err, oci.ErrAttachmentNotFound) {
+			t.Errorf("Attachment() = %v, wanted ErrAttachmentNotFound", err)
+		}
+	})
+
+	t.Run("other transport errors propagate", func(t *testing.T) {
+		want := &transport.Error{StatusCode: http.StatusUnauthorized}
+		remoteImage = func(ref name.Reference, options ...remote.Option) (v1.Image, error) {
+			if strings.HasSuffix(ref.Identifier(), ".sbom") {
+				return nil, want
+			}
+			return random.Image(300 /* byteSize */, 1 /* layers */)
+		}
+		si, err := SignedImage(ref)
+		if err != nil {
+			t.Fatalf("SignedImage() = %v", err)
+		}
+		if _, err := si.Attachment("sbom"); !errors.Is(err, want) {
+			t.Errorf("Attachment() = %v, wanted %v", err, want)
+		}
+	})
+}
@@ -16,7 +16,10 @@
 package remote
 
 import (
+	"context"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -31,6 +34,29 @@ const (
 	CustomTagPrefix      = ""
 
 	RepoOverrideEnvKey = "COSIGN_REPOSITORY"
+
+	// DefaultWriteAttempts is how many times a signature or attestation tag
+	// write is attempted when it races with concurrent writers.
+	DefaultWriteAttempts = 5
+	// DefaultWriteBackoff is the initial delay between these attempts, doubled
+	// after each of them.
+	DefaultWriteBackoff = 250 * time.Millisecond
+)
+
+// StorageMode selects how signatures and attestations are stored in a registry.
+type StorageMode string
+
+const (
+	// StorageModeTags stores signatures and attestations as the layers of a
+	// single image, tagged after the digest of the signed entity, e.g.
+	// sha256-<digest>.sig.
+	StorageModeTags StorageMode = "tags"
+
+	// StorageModeReferrers pushes each signature or attestation as its own
+	// artifact manifest, whose subject is the signed entity, and discovers them
+	// through the OCI 1.1 Referrers API, or the referrers tag schema on
+	// registries that don't support it.
+	StorageModeReferrers StorageMode = "referrers"
 )
 
 // Option is a functional option for remote operations.
@@ -42,8 +68,17 @@ type options struct {
 	SBOMSuffix        string
 	TagPrefix         string
 	TargetRepository  name.Repository
+	StorageMode       StorageMode
+	WriteAttempts     int
+	WriteBackoff      time.Duration
 	ROpt              []remote.Option
 
+	// These are only used for the requests GGCR has no support for,
+	// i.e. querying the Referrers API.
+	Context   context.Context
+	Keychain  authn.Keychain
+	Transport http.RoundTripper
+
 	OriginalOptions []Option
 }
 
@@ -59,7 +94,13 @@ func makeOptions(target name.Repository, opts ...Option) *options {
 		SBOMSuffix:        SBOMTagSuffix,
 		TagPrefix:         CustomTagPrefix,
 		TargetRepository:  target,
+		StorageMode:       StorageModeTags,
+		WriteAttempts:     DefaultWriteAttempts,
+		WriteBackoff:      DefaultWriteBackoff,
 		ROpt:              defaultOptions,
+		Context:           context.Background(),
+		Keychain:          authn.DefaultKeychain,
+		Transport:         remote.DefaultTransport,
 
 		// Keep the original options around for things that want
 		// to call something that takes options!
@@ -121,6 +162,48 @@ func WithTargetRepository(repo name.Repository) Option {
 	}
 }
 
+// WithStorageMode is a functional option for overriding the default
+// StorageModeTags storage of signatures and attestations.
+func WithStorageMode(mode StorageMode) Option {
+	return func(o *options) {
+		o.StorageMode = mode
+	}
+}
+
+// WithWriteRetry is a functional option for overriding how many times, and
+// with which initial backoff, signature and attestation tag writes are
+// attempted when they race with concurrent writers.
+func WithWriteRetry(attempts int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.WriteAttempts = attempts
+		o.WriteBackoff = backoff
+	}
+}
+
+// WithContext is a functional option for the context of the requests
+// made outside of GGCR, which should match the one in the remote options.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.Context = ctx
+	}
+}
+
+// WithKeychain is a functional option for the keychain authenticating the
+// requests made outside of GGCR, which should match the one in the remote options.
+func WithKeychain(keychain authn.Keychain) Option {
+	return func(o *options) {
+		o.Keychain = keychain
+	}
+}
+
+// WithTransport is a functional option for the transport of the requests
+// made outside of GGCR, which should match the one in the remote options.
+func WithTransport(t http.RoundTripper) Option {
+	return func(o *options) {
+		o.Transport = t
+	}
+}
+
 // GetEnvTargetRepository returns the Repository specified by
 // `os.Getenv(RepoOverrideEnvKey)`, or the empty value if not set.
 // Returns an error if the value is set but cannot be parsed.
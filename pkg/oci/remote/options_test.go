@@ -16,9 +16,11 @@
 package remote
 
 import (
+	"context"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -53,7 +55,13 @@ func TestOptions(t *testing.T) {
 			AttestationSuffix: AttestationTagSuffix,
 			SBOMSuffix:        SBOMTagSuffix,
 			TargetRepository:  repo,
+			StorageMode:       StorageModeTags,
+			WriteAttempts:     DefaultWriteAttempts,
+			WriteBackoff:      DefaultWriteBackoff,
 			ROpt:              defaultOptions,
+			Context:           context.Background(),
+			Keychain:          authn.DefaultKeychain,
+			Transport:         remote.DefaultTransport,
 		},
 	}, {
 		name: "signature option",
@@ -63,7 +71,13 @@ func TestOptions(t *testing.T) {
 			AttestationSuffix: AttestationTagSuffix,
 			SBOMSuffix:        SBOMTagSuffix,
 			TargetRepository:  repo,
+			StorageMode:       StorageModeTags,
+			WriteAttempts:     DefaultWriteAttempts,
+			WriteBackoff:      DefaultWriteBackoff,
 			ROpt:              defaultOptions,
+			Context:           context.Background(),
+			Keychain:          authn.DefaultKeychain,
+			Transport:         remote.DefaultTransport,
 		},
 	}, {
 		name: "attestation option",
@@ -73,7 +87,13 @@ func TestOptions(t *testing.T) {
 			AttestationSuffix: "pig",
 			SBOMSuffix:        SBOMTagSuffix,
 			TargetRepository:  repo,
+			StorageMode:       StorageModeTags,
+			WriteAttempts:     DefaultWriteAttempts,
+			WriteBackoff:      DefaultWriteBackoff,
 			ROpt:              defaultOptions,
+			Context:           context.Background(),
+			Keychain:          authn.DefaultKeychain,
+			Transport:         remote.DefaultTransport,
 		},
 	}, {
 		name: "sbom option",
@@ -83,7 +103,13 @@ func TestOptions(t *testing.T) {
 			AttestationSuffix: AttestationTagSuffix,
 			SBOMSuffix:        "pig",
 			TargetRepository:  repo,
+			StorageMode:       StorageModeTags,
+			WriteAttempts:     DefaultWriteAttempts,
+			WriteBackoff:      DefaultWriteBackoff,
 			ROpt:              defaultOptions,
+			Context:           context.Background(),
+			Keychain:          authn.DefaultKeychain,
+			Transport:         remote.DefaultTransport,
 		},
 	}, {
 		name: "target repo option",
@@ -93,7 +119,13 @@ func TestOptions(t *testing.T) {
 			AttestationSuffix: AttestationTagSuffix,
 			SBOMSuffix:        SBOMTagSuffix,
 			TargetRepository:  overrideRepo,
+			StorageMode:       StorageModeTags,
+			WriteAttempts:     DefaultWriteAttempts,
+			WriteBackoff:      DefaultWriteBackoff,
 			ROpt:              defaultOptions,
+			Context:           context.Background(),
+			Keychain:          authn.DefaultKeychain,
+			Transport:         remote.DefaultTransport,
 		},
 	}, {
 		name: "remote options option",
@@ -103,7 +135,45 @@ func TestOptions(t *testing.T) {
 			AttestationSuffix: AttestationTagSuffix,
 			SBOMSuffix:        SBOMTagSuffix,
 			TargetRepository:  repo,
+			StorageMode:       StorageModeTags,
+			WriteAttempts:     DefaultWriteAttempts,
+			WriteBackoff:      DefaultWriteBackoff,
 			ROpt:              otherROpt,
+			Context:           context.Background(),
+			Keychain:          authn.DefaultKeychain,
+			Transport:         remote.DefaultTransport,
+		},
+	}, {
+		name: "storage mode option",
+		opts: []Option{WithStorageMode(StorageModeReferrers)},
+		want: &options{
+			SignatureSuffix:   SignatureTagSuffix,
+			AttestationSuffix: AttestationTagSuffix,
+			SBOMSuffix:        SBOMTagSuffix,
+			TargetRepository:  repo,
+			StorageMode:       StorageModeReferrers,
+			WriteAttempts:     DefaultWriteAttempts,
+			WriteBackoff:      DefaultWriteBackoff,
+			ROpt:              defaultOptions,
+			Context:           context.Background(),
+			Keychain:          authn.DefaultKeychain,
+			Transport:         remote.DefaultTransport,
+		},
+	}, {
+		name: "write retry option",
+		opts: []Option{WithWriteRetry(2, time.Second)},
+		want: &options{
+			SignatureSuffix:   SignatureTagSuffix,
+			AttestationSuffix: AttestationTagSuffix,
+			SBOMSuffix:        SBOMTagSuffix,
+			TargetRepository:  repo,
+			StorageMode:       StorageModeTags,
+			WriteAttempts:     2,
+			WriteBackoff:      time.Second,
+			ROpt:              defaultOptions,
+			Context:           context.Background(),
+			Keychain:          authn.DefaultKeychain,
+			Transport:         remote.DefaultTransport,
 		},
 	}}
 
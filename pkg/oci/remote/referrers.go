@@ -0,0 +1,433 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/empty"
+	"github.com/sigstore/cosign/pkg/oci/internal/signature"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+)
+
+const (
+	// SignatureArtifactType is the artifactType of the manifests holding
+	// a signature in StorageModeReferrers.
+	SignatureArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+
+	// AttestationArtifactType is the artifactType of the manifests holding
+	// an attestation in StorageModeReferrers.
+	AttestationArtifactType = "application/vnd.dev.cosign.artifact.att.v1+json"
+
+	// emptyConfigMediaType is the media type of the empty config of artifact manifests.
+	emptyConfigMediaType types.MediaType = "application/vnd.oci.empty.v1+json"
+)
+
+// artifactManifest is an OCI 1.1 image manifest, with the artifactType and
+// subject fields that v1.Manifest lacks.
+type artifactManifest struct {
+	SchemaVersion int64             `json:"schemaVersion"`
+	MediaType     types.MediaType   `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        v1.Descriptor     `json:"config"`
+	Layers        []v1.Descriptor   `json:"layers"`
+	Subject       *v1.Descriptor    `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// referrersIndex is the image index returned by the Referrers API, and
+// stored under the referrers tag schema.
+type referrersIndex struct {
+	SchemaVersion int64           `json:"schemaVersion"`
+	MediaType     types.MediaType `json:"mediaType"`
+	Manifests     []referrer      `json:"manifests"`
+}
+
+// referrer describes a manifest referring to a subject.
+type referrer struct {
+	v1.Descriptor
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// rawManifest implements remote.Taggable for manifests GGCR can't represent.
+type rawManifest struct {
+	raw       []byte
+	mediaType types.MediaType
+}
+
+// RawManifest implements remote.Taggable
+func (r *rawManifest) RawManifest() ([]byte, error) {
+	return r.raw, nil
+}
+
+// MediaType implements remote.withMediaType
+func (r *rawManifest) MediaType() (types.MediaType, error) {
+	return r.mediaType, nil
+}
+
+// referrerLayer is a layer of a referrer. Like the signatures and attestations
+// it was written from, it is stored uncompressed, so its descriptor describes it fully.
+type referrerLayer struct {
+	v1.Layer
+	desc v1.Descriptor
+}
+
+// MediaType implements v1.Layer
+func (l *referrerLayer) MediaType() (types.MediaType, error) {
+	return l.desc.MediaType, nil
+}
+
+// Size implements v1.Layer
+func (l *referrerLayer) Size() (int64, error) {
+	return l.desc.Size, nil
+}
+
+// DiffID implements v1.Layer
+func (l *referrerLayer) DiffID() (v1.Hash, error) {
+	return l.desc.Digest, nil
+}
+
+// Uncompressed implements v1.Layer
+func (l *referrerLayer) Uncompressed() (io.ReadCloser, error) {
+	return l.Layer.Compressed()
+}
+
+type describable interface {
+	Digest() (v1.Hash, error)
+	MediaType() (types.MediaType, error)
+	Size() (int64, error)
+}
+
+// subjectDescriptor returns the descriptor referrers use to point at se.
+func subjectDescriptor(se oci.SignedEntity) (*v1.Descriptor, error) {
+	d, ok := se.(describable)
+	if !ok {
+		return nil, fmt.Errorf("unable to describe %T as a referrers subject", se)
+	}
+	h, err := d.Digest()
+	if err != nil {
+		return nil, err
+	}
+	mt, err := d.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	size, err := d.Size()
+	if err != nil {
+		return nil, err
+	}
+	return &v1.Descriptor{
+		MediaType: mt,
+		Size:      size,
+		Digest:    h,
+	}, nil
+}
+
+// referrersTag returns the tag of the referrers tag schema, which registries
+// without the Referrers API use to list the referrers of h: <alg>-<hex>.
+func referrersTag(repo name.Repository, h v1.Hash) name.Tag {
+	return repo.Tag(normalize(h, "", ""))
+}
+
+// referrersSubject reverses suffixTag, returning the digest a signature or
+// attestation tag was derived from, and the matching artifactType.
+func referrersSubject(ref name.Reference, o *options) (name.Digest, string, bool) {
+	tag, ok := ref.(name.Tag)
+	if !ok {
+		return name.Digest{}, "", false
+	}
+	t := strings.TrimPrefix(tag.TagStr(), o.TagPrefix)
+	dot := strings.LastIndex(t, ".")
+	if dot < 0 {
+		return name.Digest{}, "", false
+	}
+	var artifactType string
+	switch t[dot+1:] {
+	case o.SignatureSuffix:
+		artifactType = SignatureArtifactType
+	case o.AttestationSuffix:
+		artifactType = AttestationArtifactType
+	default:
+		return name.Digest{}, "", false
+	}
+	h, err := v1.NewHash(strings.Replace(t[:dot], "-", ":", 1))
+	if err != nil {
+		return name.Digest{}, "", false
+	}
+	return tag.Context().Digest(h.String()), artifactType, true
+}
+
+// referrerSignatures returns the signatures held by the manifests of the
+// given artifactType that refer to d.
+func referrerSignatures(d name.Digest, artifactType string, o *options) (oci.Signatures, error) {
+	refs, err := listReferrers(d, artifactType, o)
+	if err != nil {
+		return nil, err
+	}
+	var sigs []oci.Signature
+	for _, r := range refs {
+		img, err := remoteImage(d.Context().Digest(r.Digest.String()), o.ROpt...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fetching referrer %s", r.Digest)
+		}
+		m, err := img.Manifest()
+		if err != nil {
+			return nil, err
+		}
+		for _, desc := range m.Layers {
+			layer, err := remoteLayer(d.Context().Digest(desc.Digest.String()), o.ROpt...)
+			if err != nil {
+				return nil, err
+			}
+			sigs = append(sigs, signature.New(&referrerLayer{Layer: layer, desc: desc}, desc))
+		}
+	}
+	return mutate.AppendSignatures(empty.Signatures(), sigs...)
+}
+
+// listReferrers lists the manifests of the given artifactType that refer to d,
+// through the Referrers API or, if the registry doesn't support it, the
+// referrers tag schema.
+func listReferrers(d name.Digest, artifactType string, o *options) ([]referrer, error) {
+	idx, err := referrersAPI(d, artifactType, o)
+	if err != nil {
+		return nil, err
+	}
+	if idx == nil {
+		h, err := v1.NewHash(d.DigestStr())
+		if err != nil {
+			return nil, err
+		}
+		if idx, err = referrersTagIndex(referrersTag(d.Context(), h), o); err != nil {
+			return nil, err
+		}
+	}
+	// The registry may not apply the artifactType filter.
+	var refs []referrer
+	for _, r := range idx.Manifests {
+		if r.ArtifactType == artifactType {
+			refs = append(refs, r)
+		}
+	}
+	return refs, nil
+}
+
+// referrersAPI queries the Referrers API for the manifests that refer to d,
+// returning a nil index if the registry doesn't support the API.
+func referrersAPI(d name.Digest, artifactType string, o *options) (*referrersIndex, error) {
+	repo := d.Context()
+	auth, err := o.Keychain.Resolve(repo)
+	if err != nil {
+		return nil, err
+	}
+	tr, err := transport.NewWithContext(o.Context, repo.Registry, auth, o.Transport, []string{repo.Scope(transport.PullScope)})
+	if err != nil {
+		return nil, err
+	}
+	u := url.URL{
+		Scheme: repo.Registry.Scheme(),
+		Host:   repo.RegistryStr(),
+		Path:   fmt.Sprintf("/v2/%s/referrers/%s", repo.RepositoryStr(), d.DigestStr()),
+	}
+	if artifactType != "" {
+		u.RawQuery = url.Values{"artifactType": []string{artifactType}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(o.Context, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(types.OCIImageIndex))
+	resp, err := (&http.Client{Transport: tr}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := transport.CheckError(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	var idx referrersIndex
+	if err := json.NewDecoder(resp.Body).Decode(&idx); err != nil {
+		return nil, errors.Wrap(err, "decoding referrers")
+	}
+	return &idx, nil
+}
+
+// referrersTagIndex fetches the index stored under the referrers tag schema,
+// returning an empty one if there is none yet.
+func referrersTagIndex(tag name.Tag, o *options) (*referrersIndex, error) {
+	desc, err := remoteGet(tag, o.ROpt...)
+	var te *transport.Error
+	if errors.As(err, &te) && te.StatusCode == http.StatusNotFound {
+		return &referrersIndex{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var idx referrersIndex
+	if err := json.Unmarshal(desc.Manifest, &idx); err != nil {
+		return nil, errors.Wrapf(err, "decoding referrers tag %s", tag)
+	}
+	return &idx, nil
+}
+
+// writeReferrers pushes each of sigs as an artifact manifest of the given
+// artifactType whose subject is se, and lists them under the referrers tag
+// schema if the registry doesn't support the Referrers API.
+func writeReferrers(se oci.SignedEntity, sigs oci.Signatures, artifactType string, o *options) error {
+	repo := o.TargetRepository
+	subject, err := subjectDescriptor(se)
+	if err != nil {
+		return err
+	}
+	m, err := sigs.Manifest()
+	if err != nil {
+		return err
+	}
+
+	config := static.NewLayer([]byte("{}"), emptyConfigMediaType)
+	configDigest, err := config.Digest()
+	if err != nil {
+		return err
+	}
+	if err := remoteWriteLayer(repo, config, o.ROpt...); err != nil {
+		return errors.Wrap(err, "writing empty config")
+	}
+
+	refs := make([]referrer, 0, len(m.Layers))
+	for _, desc := range m.Layers {
+		layer, err := sigs.LayerByDigest(desc.Digest)
+		if err != nil {
+			return err
+		}
+		if err := remoteWriteLayer(repo, layer, o.ROpt...); err != nil {
+			return errors.Wrapf(err, "writing layer %s", desc.Digest)
+		}
+		raw, err := json.Marshal(&artifactManifest{
+			SchemaVersion: 2,
+			MediaType:     types.OCIManifestSchema1,
+			ArtifactType:  artifactType,
+			Config: v1.Descriptor{
+				MediaType: emptyConfigMediaType,
+				Size:      2,
+				Digest:    configDigest,
+			},
+			Layers:  []v1.Descriptor{desc},
+			Subject: subject,
+		})
+		if err != nil {
+			return err
+		}
+		h, size, err := v1.SHA256(bytes.NewReader(raw))
+		if err != nil {
+			return err
+		}
+		if err := remotePut(repo.Digest(h.String()), &rawManifest{raw: raw, mediaType: types.OCIManifestSchema1}, o.ROpt...); err != nil {
+			return errors.Wrapf(err, "writing referrer %s", h)
+		}
+		refs = append(refs, referrer{
+			Descriptor: v1.Descriptor{
+				MediaType: types.OCIManifestSchema1,
+				Size:      size,
+				Digest:    h,
+			},
+			ArtifactType: artifactType,
+		})
+	}
+
+	idx, err := referrersAPI(repo.Digest(subject.Digest.String()), "", o)
+	if err != nil {
+		return err
+	}
+	if idx != nil {
+		// The registry keeps track of the referrers itself.
+		return nil
+	}
+	return updateReferrersTag(referrersTag(repo, subject.Digest), refs, o)
+}
+
+// updateReferrersTag adds refs to the index stored under the referrers tag
+// schema, retrying like writeSignaturesTag when racing with concurrent writers.
+func updateReferrersTag(tag name.Tag, refs []referrer, o *options) error {
+	return retryOnRace(tag, o, func() error {
+		before, err := tagDigest(tag, o)
+		if err != nil {
+			return err
+		}
+		idx, err := referrersTagIndex(tag, o)
+		if err != nil {
+			return err
+		}
+		missing := missingReferrers(idx, refs)
+		if len(missing) == 0 {
+			return nil
+		}
+		idx.Manifests = append(idx.Manifests, missing...)
+		idx.SchemaVersion = 2
+		idx.MediaType = types.OCIImageIndex
+		raw, err := json.Marshal(idx)
+		if err != nil {
+			return err
+		}
+
+		if current, err := tagDigest(tag, o); err != nil {
+			return err
+		} else if current != before {
+			return errTagMoved
+		}
+		if err := remotePut(tag, &rawManifest{raw: raw, mediaType: types.OCIImageIndex}, o.ROpt...); err != nil {
+			return err
+		}
+
+		// Check a concurrent writer didn't overwrite our referrers right after.
+		if idx, err = referrersTagIndex(tag, o); err != nil {
+			return err
+		} else if len(missingReferrers(idx, refs)) != 0 {
+			return errTagMoved
+		}
+		return nil
+	})
+}
+
+// missingReferrers returns the refs idx doesn't list.
+func missingReferrers(idx *referrersIndex, refs []referrer) []referrer {
+	known := make(map[v1.Hash]bool, len(idx.Manifests))
+	for _, r := range idx.Manifests {
+		known[r.Digest] = true
+	}
+	var missing []referrer
+	for _, r := range refs {
+		if !known[r.Digest] {
+			missing = append(missing, r)
+			known[r.Digest] = true
+		}
+	}
+	return missing
+}
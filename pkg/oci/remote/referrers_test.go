@@ -0,0 +1,192 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+// referrersRegistry adds the Referrers API to a registry that lacks it.
+type referrersRegistry struct {
+	inner http.Handler
+
+	lock      sync.Mutex
+	referrers map[string][]referrer
+}
+
+func (r *referrersRegistry) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	elems := strings.Split(req.URL.Path, "/")
+	if req.Method == http.MethodGet && len(elems) > 2 && elems[len(elems)-2] == "referrers" {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		resp.Header().Set("Content-Type", string(types.OCIImageIndex))
+		json.NewEncoder(resp).Encode(&referrersIndex{
+			SchemaVersion: 2,
+			MediaType:     types.OCIImageIndex,
+			Manifests:     r.referrers[elems[len(elems)-1]],
+		})
+		return
+	}
+	if req.Method == http.MethodPut && len(elems) > 2 && elems[len(elems)-2] == "manifests" {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var m artifactManifest
+		if err := json.Unmarshal(body, &m); err == nil && m.Subject != nil {
+			h, size, _ := v1.SHA256(bytes.NewReader(body))
+			r.lock.Lock()
+			if !r.known(m.Subject.Digest.String(), h) {
+				r.referrers[m.Subject.Digest.String()] = append(r.referrers[m.Subject.Digest.String()], referrer{
+					Descriptor:   v1.Descriptor{MediaType: m.MediaType, Size: size, Digest: h},
+					ArtifactType: m.ArtifactType,
+				})
+			}
+			r.lock.Unlock()
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	r.inner.ServeHTTP(resp, req)
+}
+
+// known returns whether the manifest h is already listed as a referrer of subject.
+func (r *referrersRegistry) known(subject string, h v1.Hash) bool {
+	for _, ref := range r.referrers[subject] {
+		if ref.Digest == h {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReferrersStorage(t *testing.T) {
+	for _, api := range []bool{false, true} {
+		t.Run(fmt.Sprintf("referrers API %t", api), func(t *testing.T) {
+			var handler http.Handler = registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+			if api {
+				handler = &referrersRegistry{inner: handler, referrers: map[string][]referrer{}}
+			}
+			s := httptest.NewServer(handler)
+			defer s.Close()
+			u, err := url.Parse(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			i, err := random.Image(300 /* byteSize */, 1 /* layers */)
+			if err != nil {
+				t.Fatalf("random.Image() = %v", err)
+			}
+			h, err := i.Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := name.ParseReference(fmt.Sprintf("%s/test/image@%s", u.Host, h))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.Write(ref, i); err != nil {
+				t.Fatalf("remote.Write() = %v", err)
+			}
+
+			opts := []Option{WithStorageMode(StorageModeReferrers)}
+			signAndWrite := func(payloads ...string) {
+				t.Helper()
+				si, err := SignedImage(ref, opts...)
+				if err != nil {
+					t.Fatalf("SignedImage() = %v", err)
+				}
+				for _, p := range payloads {
+					sig, err := static.NewSignature([]byte(p), "c2lnbmF0dXJl")
+					if err != nil {
+						t.Fatalf("static.NewSignature() = %v", err)
+					}
+					if si, err = mutate.AttachSignatureToImage(si, sig); err != nil {
+						t.Fatalf("AttachSignatureToImage() = %v", err)
+					}
+				}
+				if err := WriteSignatures(ref.Context(), si, opts...); err != nil {
+					t.Fatalf("WriteSignatures() = %v", err)
+				}
+			}
+			countSignatures := func(ref name.Reference, opts ...Option) int {
+				t.Helper()
+				sigs, err := Signatures(ref, opts...)
+				if err != nil {
+					t.Fatalf("Signatures() = %v", err)
+				}
+				sl, err := sigs.Get()
+				if err != nil {
+					t.Fatalf("Get() = %v", err)
+				}
+				return len(sl)
+			}
+
+			signAndWrite("one", "two")
+			signAndWrite("three")
+
+			sigTag, err := SignatureTag(ref, opts...)
+			if err != nil {
+				t.Fatalf("SignatureTag() = %v", err)
+			}
+			if got, want := countSignatures(sigTag, opts...), 3; got != want {
+				t.Errorf("Signatures() got %d signatures, wanted %d", got, want)
+			}
+			if got := countSignatures(sigTag); got != 0 {
+				t.Errorf("Signatures() in tags mode got %d signatures, wanted 0", got)
+			}
+
+			si, err := SignedImage(ref, opts...)
+			if err != nil {
+				t.Fatalf("SignedImage() = %v", err)
+			}
+			atts, err := si.Attestations()
+			if err != nil {
+				t.Fatalf("Attestations() = %v", err)
+			}
+			if al, err := atts.Get(); err != nil || len(al) != 0 {
+				t.Errorf("Attestations() = %d, %v, wanted none", len(al), err)
+			}
+
+			// Only registries without the Referrers API get the fallback tag.
+			_, err = remote.Get(referrersTag(ref.Context(), h))
+			if api && err == nil {
+				t.Error("referrers tag written to a registry supporting the Referrers API")
+			} else if !api && err != nil {
+				t.Errorf("referrers tag not written: %v", err)
+			}
+		})
+	}
+}
@@ -16,22 +16,30 @@
 package remote
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/sigstore/cosign/pkg/oci"
 )
 
 // These enable mocking for unit testing without faking an entire registry.
 var (
-	remoteImage = remote.Image
-	remoteIndex = remote.Index
-	remoteGet   = remote.Get
-	remoteWrite = remote.Write
+	remoteImage      = remote.Image
+	remoteIndex      = remote.Index
+	remoteGet        = remote.Get
+	remoteHead       = remote.Head
+	remoteLayer      = remote.Layer
+	remoteWrite      = remote.Write
+	remoteWriteIndex = remote.WriteIndex
+	remoteWriteLayer = remote.WriteLayer
+	remotePut        = remote.Put
 )
 
 // SignedEntity provides access to a remote reference, and its signatures.
@@ -126,6 +134,9 @@ func signatures(digestable digestable, o *options) (oci.Signatures, error) {
 	if err != nil {
 		return nil, err
 	}
+	if o.StorageMode == StorageModeReferrers {
+		return referrerSignatures(o.TargetRepository.Digest(h.String()), SignatureArtifactType, o)
+	}
 	return Signatures(o.TargetRepository.Tag(normalize(h, o.TagPrefix, o.SignatureSuffix)), o.OriginalOptions...)
 }
 
@@ -135,6 +146,9 @@ func attestations(digestable digestable, o *options) (oci.Signatures, error) {
 	if err != nil {
 		return nil, err
 	}
+	if o.StorageMode == StorageModeReferrers {
+		return referrerSignatures(o.TargetRepository.Digest(h.String()), AttestationArtifactType, o)
+	}
 	return Signatures(o.TargetRepository.Tag(normalize(h, o.TagPrefix, o.AttestationSuffix)), o.OriginalOptions...)
 }
 
@@ -146,6 +160,10 @@ func attachment(digestable digestable, attName string, o *options) (oci.File, er
 	}
 	img, err := SignedImage(o.TargetRepository.Tag(normalize(h, o.TagPrefix, attName)), o.OriginalOptions...)
 	if err != nil {
+		var te *transport.Error
+		if errors.As(err, &te) && te.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %q", oci.ErrAttachmentNotFound, attName)
+		}
 		return nil, err
 	}
 	ls, err := img.Layers()
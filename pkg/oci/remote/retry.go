@@ -0,0 +1,157 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/pkg/errors"
+	"github.com/sigstore/cosign/pkg/oci"
+)
+
+// errTagMoved reports that a tag was written by someone else while it was
+// being read, merged and written back.
+var errTagMoved = errors.New("tag was concurrently modified")
+
+// sleep enables skipping the backoff in unit tests.
+var sleep = time.Sleep
+
+// retryOnRace calls try until it doesn't report errTagMoved, backing off
+// exponentially, with jitter, between the attempts.
+func retryOnRace(tag name.Tag, o *options, try func() error) error {
+	backoff := o.WriteBackoff
+	for attempt := 1; ; attempt++ {
+		err := try()
+		if !errors.Is(err, errTagMoved) {
+			return err
+		}
+		if attempt >= o.WriteAttempts {
+			return errors.Wrapf(err, "writing %s: giving up after %d attempts", tag, attempt)
+		}
+		if backoff > 0 {
+			sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff)))) // #nosec G404
+			backoff *= 2
+		}
+	}
+}
+
+// tagDigest returns the digest of the manifest tag points to, or the zero
+// v1.Hash if there is none.
+func tagDigest(tag name.Tag, o *options) (v1.Hash, error) {
+	desc, err := remoteHead(tag, o.ROpt...)
+	var te *transport.Error
+	if errors.As(err, &te) && te.StatusCode == http.StatusNotFound {
+		return v1.Hash{}, nil
+	} else if err != nil {
+		return v1.Hash{}, err
+	}
+	return desc.Digest, nil
+}
+
+// writeSignaturesTag writes the signatures returned by get to tag.
+//
+// Registries have no conditional writes, so the signatures already in tag
+// can be lost to a concurrent writer that read it before us. This detects that
+// the tag moved between get reading it and our write, or that our write was
+// overwritten right after, and then calls get again, so the signatures are
+// merged with the ones in tag anew, and retries.
+func writeSignaturesTag(tag name.Tag, get func() (oci.Signatures, error), o *options) error {
+	return retryOnRace(tag, o, func() error {
+		before, err := tagDigest(tag, o)
+		if err != nil {
+			return err
+		}
+		sigs, err := get()
+		if err != nil {
+			return err
+		}
+		h, err := sigs.Digest()
+		if err != nil {
+			return err
+		}
+		if h == before {
+			// Nothing new to write.
+			return nil
+		}
+		if current, err := tagDigest(tag, o); err != nil {
+			return err
+		} else if current != before {
+			return errTagMoved
+		}
+
+		if err := remoteWrite(tag, sigs, o.ROpt...); err != nil {
+			return err
+		}
+
+		after, err := tagDigest(tag, o)
+		if err != nil {
+			return err
+		} else if after == h {
+			return nil
+		}
+		// Someone wrote the tag since, check they kept our signatures.
+		got, err := Signatures(tag, o.OriginalOptions...)
+		if err != nil {
+			return err
+		}
+		if ok, err := containsSignatures(got, sigs); err != nil {
+			return err
+		} else if !ok {
+			return errTagMoved
+		}
+		return nil
+	})
+}
+
+// containsSignatures returns whether every layer of want is in got.
+func containsSignatures(got, want oci.Signatures) (bool, error) {
+	keys := func(sigs oci.Signatures) (map[string]bool, error) {
+		m, err := sigs.Manifest()
+		if err != nil {
+			return nil, err
+		}
+		keys := make(map[string]bool, len(m.Layers))
+		for _, desc := range m.Layers {
+			// The same payload can be signed by several keys,
+			// so tell the layers apart by their annotations too.
+			ann, err := json.Marshal(desc.Annotations)
+			if err != nil {
+				return nil, err
+			}
+			keys[desc.Digest.String()+string(ann)] = true
+		}
+		return keys, nil
+	}
+	gotKeys, err := keys(got)
+	if err != nil {
+		return false, err
+	}
+	wantKeys, err := keys(want)
+	if err != nil {
+		return false, err
+	}
+	for k := range wantKeys {
+		if !gotKeys[k] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
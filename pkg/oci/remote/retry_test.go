@@ -0,0 +1,223 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/mutate"
+	"github.com/sigstore/cosign/pkg/oci/static"
+)
+
+// racingRegistry lets a concurrent writer race with the requests to a tag.
+type racingRegistry struct {
+	inner http.Handler
+	path  string
+
+	// race is called with the method of each request to path, once it was
+	// served, except for the requests it makes itself.
+	race   func(method string)
+	racing int32
+}
+
+func (r *racingRegistry) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	r.inner.ServeHTTP(resp, req)
+	if r.race == nil || req.URL.Path != r.path || !atomic.CompareAndSwapInt32(&r.racing, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&r.racing, 0)
+	r.race(req.Method)
+}
+
+func TestWriteSignaturesRace(t *testing.T) {
+	sleep = func(time.Duration) {}
+	t.Cleanup(func() {
+		sleep = time.Sleep
+	})
+
+	tests := []struct {
+		name string
+		// race returns the payload of the signature a concurrent writer adds
+		// to the tag, appending it to the signatures in base, or "" to not race.
+		race    func(method string, n int) (payload string, base bool)
+		want    []string
+		wantErr bool
+	}{{
+		name: "no race",
+		race: func(string, int) (string, bool) { return "", false },
+		want: []string{"existing", "ours"},
+	}, {
+		name: "tag moved after it was read",
+		race: func(method string, n int) (string, bool) {
+			if method == http.MethodGet && n == 0 {
+				return "theirs", true
+			}
+			return "", false
+		},
+		want: []string{"existing", "ours", "theirs"},
+	}, {
+		name: "write overwritten by a writer that read the tag before it",
+		race: func(method string, n int) (string, bool) {
+			if method == http.MethodPut && n == 0 {
+				return "theirs", false
+			}
+			return "", false
+		},
+		want: []string{"existing", "ours", "theirs"},
+	}, {
+		name: "tag always moves",
+		race: func(method string, n int) (string, bool) {
+			if method == http.MethodGet {
+				return fmt.Sprintf("theirs-%d", n), true
+			}
+			return "", false
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rr := &racingRegistry{inner: registry.New(registry.Logger(log.New(io.Discard, "", 0)))}
+			s := httptest.NewServer(rr)
+			defer s.Close()
+			u, err := url.Parse(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			i, err := random.Image(300 /* byteSize */, 1 /* layers */)
+			if err != nil {
+				t.Fatalf("random.Image() = %v", err)
+			}
+			h, err := i.Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := name.ParseReference(fmt.Sprintf("%s/test/image@%s", u.Host, h))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := remote.Write(ref, i); err != nil {
+				t.Fatalf("remote.Write() = %v", err)
+			}
+			tag, err := SignatureTag(ref)
+			if err != nil {
+				t.Fatalf("SignatureTag() = %v", err)
+			}
+			rr.path = fmt.Sprintf("/v2/%s/manifests/%s", tag.RepositoryStr(), tag.TagStr())
+
+			sign := func(sigs oci.Signatures, payload string) oci.Signatures {
+				t.Helper()
+				sig, err := static.NewSignature([]byte(payload), "c2lnbmF0dXJl")
+				if err != nil {
+					t.Fatalf("static.NewSignature() = %v", err)
+				}
+				sigs, err = mutate.AppendSignatures(sigs, sig)
+				if err != nil {
+					t.Fatalf("AppendSignatures() = %v", err)
+				}
+				return sigs
+			}
+			initial := sign(mustSignatures(t, tag), "existing")
+			if err := remote.Write(tag, initial); err != nil {
+				t.Fatalf("remote.Write() = %v", err)
+			}
+
+			n := 0
+			rr.race = func(method string) {
+				payload, base := test.race(method, n)
+				if payload == "" {
+					return
+				}
+				n++
+				// The concurrent writer either read the tag just now,
+				// or at the same time as us, before our write.
+				sigs := initial
+				if base {
+					sigs = mustSignatures(t, tag)
+				}
+				if err := remote.Write(tag, sign(sigs, payload)); err != nil {
+					t.Errorf("remote.Write() = %v", err)
+				}
+			}
+
+			si, err := SignedImage(ref)
+			if err != nil {
+				t.Fatalf("SignedImage() = %v", err)
+			}
+			sig, err := static.NewSignature([]byte("ours"), "c2lnbmF0dXJl")
+			if err != nil {
+				t.Fatalf("static.NewSignature() = %v", err)
+			}
+			si, err = mutate.AttachSignatureToImage(si, sig)
+			if err != nil {
+				t.Fatalf("AttachSignatureToImage() = %v", err)
+			}
+
+			err = WriteSignatures(ref.Context(), si, WithWriteRetry(3, DefaultWriteBackoff))
+			if test.wantErr {
+				if !errors.Is(err, errTagMoved) {
+					t.Errorf("WriteSignatures() = %v, wanted %v", err, errTagMoved)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("WriteSignatures() = %v", err)
+			}
+
+			rr.race = func(string) {}
+			sl, err := mustSignatures(t, tag).Get()
+			if err != nil {
+				t.Fatalf("Get() = %v", err)
+			}
+			var got []string
+			for _, sig := range sl {
+				p, err := sig.Payload()
+				if err != nil {
+					t.Fatalf("Payload() = %v", err)
+				}
+				got = append(got, string(p))
+			}
+			sort.Strings(got)
+			if d := cmp.Diff(test.want, got); d != "" {
+				t.Errorf("signatures (-want +got): %s", d)
+			}
+		})
+	}
+}
+
+func mustSignatures(t *testing.T, tag name.Tag) oci.Signatures {
+	t.Helper()
+	sigs, err := Signatures(tag)
+	if err != nil {
+		t.Fatalf("Signatures() = %v", err)
+	}
+	return sigs
+}
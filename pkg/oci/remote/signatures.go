@@ -29,8 +29,15 @@ import (
 
 // Signatures fetches the signatures image represented by the named reference.
 // If the tag is not found, this returns an empty oci.Signatures.
+// In StorageModeReferrers, signature and attestation tags are resolved to the
+// referrers of the digest they were derived from instead.
 func Signatures(ref name.Reference, opts ...Option) (oci.Signatures, error) {
 	o := makeOptions(ref.Context(), opts...)
+	if o.StorageMode == StorageModeReferrers {
+		if d, artifactType, ok := referrersSubject(ref, o); ok {
+			return referrerSignatures(d, artifactType, o)
+		}
+	}
 	img, err := remoteImage(ref, o.ROpt...)
 	var te *transport.Error
 	if errors.As(err, &te) {
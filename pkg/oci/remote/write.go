@@ -16,17 +16,19 @@
 package remote
 
 import (
+	"context"
+
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/pkg/errors"
 	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/walk"
 )
 
 // WriteSignedImageIndexImages writes the images within the image index
 // This includes the signed image and associated signatures in the image index
 // TODO (priyawadhwa@): write the `index.json` itself to the repo as well
-// TODO (priyawadhwa@): write the attestations
 func WriteSignedImageIndexImages(ref name.Reference, sii oci.SignedImageIndex, opts ...Option) error {
 	repo := ref.Context()
 	o := makeOptions(repo, opts...)
@@ -58,7 +60,7 @@ func WriteSignedImageIndexImages(ref name.Reference, sii oci.SignedImageIndex, o
 	if err != nil {
 		return err
 	}
-	if sigs != nil { // will be nil if there are no associated signatures
+	if !isEmpty(sigs) {
 		sigsTag, err := SignatureTag(ref, opts...)
 		if err != nil {
 			return errors.Wrap(err, "sigs tag")
@@ -73,7 +75,7 @@ func WriteSignedImageIndexImages(ref name.Reference, sii oci.SignedImageIndex, o
 	if err != nil {
 		return err
 	}
-	if atts != nil { // will be nil if there are no associated attestations
+	if !isEmpty(atts) {
 		attsTag, err := AttestationTag(ref, opts...)
 		if err != nil {
 			return errors.Wrap(err, "sigs tag")
@@ -88,10 +90,13 @@ func WriteSignedImageIndexImages(ref name.Reference, sii oci.SignedImageIndex, o
 func WriteSignatures(repo name.Repository, se oci.SignedEntity, opts ...Option) error {
 	o := makeOptions(repo, opts...)
 
-	// Access the signature list to publish
-	sigs, err := se.Signatures()
-	if err != nil {
-		return err
+	if o.StorageMode == StorageModeReferrers {
+		// Access the signature list to publish
+		sigs, err := se.Signatures()
+		if err != nil {
+			return err
+		}
+		return writeReferrers(se, sigs, SignatureArtifactType, o)
 	}
 
 	// Determine the tag to which these signatures should be published.
@@ -101,8 +106,9 @@ func WriteSignatures(repo name.Repository, se oci.SignedEntity, opts ...Option)
 	}
 	tag := o.TargetRepository.Tag(normalize(h, o.TagPrefix, o.SignatureSuffix))
 
-	// Write the Signatures image to the tag, with the provided remote.Options
-	return remoteWrite(tag, sigs, o.ROpt...)
+	// Write the Signatures image to the tag, with the provided remote.Options,
+	// accessing the signature list to publish anew if the tag moved meanwhile.
+	return writeSignaturesTag(tag, se.Signatures, o)
 }
 
 // WriteAttestations publishes the attestations attached to the given entity
@@ -110,10 +116,13 @@ func WriteSignatures(repo name.Repository, se oci.SignedEntity, opts ...Option)
 func WriteAttestations(repo name.Repository, se oci.SignedEntity, opts ...Option) error {
 	o := makeOptions(repo, opts...)
 
-	// Access the signature list to publish
-	atts, err := se.Attestations()
-	if err != nil {
-		return err
+	if o.StorageMode == StorageModeReferrers {
+		// Access the signature list to publish
+		atts, err := se.Attestations()
+		if err != nil {
+			return err
+		}
+		return writeReferrers(se, atts, AttestationArtifactType, o)
 	}
 
 	// Determine the tag to which these signatures should be published.
@@ -123,6 +132,94 @@ func WriteAttestations(repo name.Repository, se oci.SignedEntity, opts ...Option
 	}
 	tag := o.TargetRepository.Tag(normalize(h, o.TagPrefix, o.AttestationSuffix))
 
-	// Write the Signatures image to the tag, with the provided remote.Options
-	return remoteWrite(tag, atts, o.ROpt...)
+	// Write the Signatures image to the tag, with the provided remote.Options,
+	// accessing the signature list to publish anew if the tag moved meanwhile.
+	return writeSignaturesTag(tag, se.Attestations, o)
+}
+
+// WriteAttachment publishes the named attachment of the given entity
+// into the provided repository.
+func WriteAttachment(repo name.Repository, se oci.SignedEntity, attName string, opts ...Option) error {
+	o := makeOptions(repo, opts...)
+
+	// Access the attachment to publish
+	f, err := se.Attachment(attName)
+	if err != nil {
+		return err
+	}
+
+	// Determine the tag to which the attachment should be published.
+	h, err := se.(digestable).Digest()
+	if err != nil {
+		return err
+	}
+	tag := o.TargetRepository.Tag(normalize(h, o.TagPrefix, attName))
+
+	// Write the attachment image to the tag, with the provided remote.Options
+	return remoteWrite(tag, f, o.ROpt...)
+}
+
+// WriteSignedEntity writes the image or image index to ref, then publishes
+// the signatures, attestations and named attachments of it, and of each
+// entity nested within an image index, into the repository of ref.
+func WriteSignedEntity(ctx context.Context, ref name.Reference, se oci.SignedEntity, attNames []string, opts ...Option) error {
+	o := makeOptions(ref.Context(), opts...)
+
+	switch obj := se.(type) {
+	case oci.SignedImage:
+		if err := remoteWrite(ref, obj, o.ROpt...); err != nil {
+			return errors.Wrap(err, "writing image")
+		}
+	case oci.SignedImageIndex:
+		if err := remoteWriteIndex(ref, obj, o.ROpt...); err != nil {
+			return errors.Wrap(err, "writing index")
+		}
+	default:
+		return errors.Errorf("unsupported type: %T", se)
+	}
+
+	return walk.SignedEntity(ctx, se, func(_ context.Context, se oci.SignedEntity) error {
+		sigs, err := se.Signatures()
+		if err != nil {
+			return err
+		}
+		if !isEmpty(sigs) {
+			if err := WriteSignatures(ref.Context(), se, opts...); err != nil {
+				return errors.Wrap(err, "writing signatures")
+			}
+		}
+
+		atts, err := se.Attestations()
+		if err != nil {
+			return err
+		}
+		if !isEmpty(atts) {
+			if err := WriteAttestations(ref.Context(), se, opts...); err != nil {
+				return errors.Wrap(err, "writing attestations")
+			}
+		}
+
+		for _, attName := range attNames {
+			if _, err := se.Attachment(attName); err != nil {
+				if errors.Is(err, oci.ErrAttachmentNotFound) {
+					// The entity does not have this attachment.
+					continue
+				}
+				return errors.Wrapf(err, "getting attachment %s", attName)
+			}
+			if err := WriteAttachment(ref.Context(), se, attName, opts...); err != nil {
+				return errors.Wrapf(err, "writing attachment %s", attName)
+			}
+		}
+		return nil
+	})
+}
+
+// isEmpty returns true if the signatures or attestations are empty
+func isEmpty(s oci.Signatures) bool {
+	if s == nil {
+		return true
+	}
+	ss, _ := s.Get()
+	return len(ss) == 0
 }
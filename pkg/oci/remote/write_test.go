@@ -16,18 +16,51 @@
 package remote
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/sigstore/cosign/pkg/oci/mutate"
 	"github.com/sigstore/cosign/pkg/oci/signed"
 	"github.com/sigstore/cosign/pkg/oci/static"
 )
 
+// headWrittenTags mocks remoteHead to serve the digests of the images
+// written through the current remoteWrite mock.
+func headWrittenTags(t *testing.T) {
+	t.Helper()
+	rh, rw := remoteHead, remoteWrite
+	t.Cleanup(func() {
+		remoteHead = rh
+	})
+	written := map[string]v1.Hash{}
+	remoteWrite = func(ref name.Reference, img v1.Image, options ...remote.Option) error {
+		if err := rw(ref, img, options...); err != nil {
+			return err
+		}
+		h, err := img.Digest()
+		if err != nil {
+			return err
+		}
+		written[ref.String()] = h
+		return nil
+	}
+	remoteHead = func(ref name.Reference, options ...remote.Option) (*v1.Descriptor, error) {
+		if h, ok := written[ref.String()]; ok {
+			return &v1.Descriptor{Digest: h}, nil
+		}
+		return nil, &transport.Error{StatusCode: http.StatusNotFound}
+	}
+}
+
 func TestWriteSignatures(t *testing.T) {
 	rw := remote.Write
 	t.Cleanup(func() {
@@ -65,6 +98,7 @@ func TestWriteSignatures(t *testing.T) {
 
 		return nil
 	}
+	headWrittenTags(t)
 	if err := WriteSignatures(ref.Context(), si); err != nil {
 		t.Fatalf("WriteSignature() = %v", err)
 	}
@@ -107,7 +141,79 @@ func TestWriteAttestations(t *testing.T) {
 
 		return nil
 	}
+	headWrittenTags(t)
 	if err := WriteAttestations(ref.Context(), si); err != nil {
 		t.Fatalf("WriteAttestations() = %v", err)
 	}
 }
+
+func TestWriteSignedEntity(t *testing.T) {
+	rw, rwi := remote.Write, remote.WriteIndex
+	t.Cleanup(func() {
+		remoteWrite, remoteWriteIndex = rw, rwi
+	})
+
+	i, err := random.Image(300 /* byteSize */, 7 /* layers */)
+	if err != nil {
+		t.Fatalf("random.Image() = %v", err)
+	}
+	sig, err := static.NewSignature(nil, "child")
+	if err != nil {
+		t.Fatalf("static.NewSignature() = %v", err)
+	}
+	child, err := mutate.AttachSignatureToImage(signed.Image(i), sig)
+	if err != nil {
+		t.Fatalf("AttachSignatureToImage() = %v", err)
+	}
+	sbom, err := static.NewFile([]byte("sbom"))
+	if err != nil {
+		t.Fatalf("static.NewFile() = %v", err)
+	}
+	child, err = mutate.AttachFileToImage(child, "sbom", sbom)
+	if err != nil {
+		t.Fatalf("AttachFileToImage() = %v", err)
+	}
+
+	sii := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: child})
+	att, err := static.NewAttestation([]byte("index"))
+	if err != nil {
+		t.Fatalf("static.NewAttestation() = %v", err)
+	}
+	sii, err = mutate.AttachAttestationToImageIndex(sii, att)
+	if err != nil {
+		t.Fatalf("AttachAttestationToImageIndex() = %v", err)
+	}
+
+	ref := name.MustParseReference("gcr.io/bistroless/static:nonroot")
+	written := map[string]bool{}
+	remoteWrite = func(ref name.Reference, img v1.Image, options ...remote.Option) error {
+		written[ref.String()] = true
+		return nil
+	}
+	remoteWriteIndex = func(ref name.Reference, ii v1.ImageIndex, options ...remote.Option) error {
+		written[ref.String()] = true
+		return nil
+	}
+	headWrittenTags(t)
+	if err := WriteSignedEntity(context.Background(), ref, sii, []string{"sbom"}); err != nil {
+		t.Fatalf("WriteSignedEntity() = %v", err)
+	}
+
+	ih, err := sii.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := child.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{
+		ref.String(): true,
+		ref.Context().Tag(normalize(ih, "", AttestationTagSuffix)).String(): true,
+		ref.Context().Tag(normalize(ch, "", SignatureTagSuffix)).String():   true,
+		ref.Context().Tag(normalize(ch, "", "sbom")).String():               true,
+	}
+	if d := cmp.Diff(want, written); d != "" {
+		t.Errorf("unexpected writes (-want +got): %s", d)
+	}
+}
@@ -16,7 +16,7 @@
 package signed
 
 import (
-	"errors"
+	"fmt"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 
@@ -49,5 +49,5 @@ func (*image) Attestations() (oci.Signatures, error) {
 
 // Attestations implements oci.SignedImage
 func (*image) Attachment(name string) (oci.File, error) {
-	return nil, errors.New("unimplemented")
+	return nil, fmt.Errorf("%w: %q", oci.ErrAttachmentNotFound, name)
 }
@@ -16,7 +16,7 @@
 package signed
 
 import (
-	"errors"
+	"fmt"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 
@@ -70,5 +70,5 @@ func (*index) Attestations() (oci.Signatures, error) {
 
 // Attestations implements oci.SignedImage
 func (*index) Attachment(name string) (oci.File, error) {
-	return nil, errors.New("unimplemented")
+	return nil, fmt.Errorf("%w: %q", oci.ErrAttachmentNotFound, name)
 }
@@ -0,0 +1,97 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package static
+
+import (
+	"bytes"
+	"encoding/json"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/pkg/errors"
+	"github.com/sigstore/cosign/pkg/oci"
+	"github.com/sigstore/cosign/pkg/oci/signed"
+)
+
+// NewArtifact constructs a new v1.Image for an OCI artifact with the provided
+// raw config, such as the metadata of a Helm chart, and layers.
+func NewArtifact(config []byte, configMediaType types.MediaType, layers ...v1.Layer) (oci.SignedImage, error) {
+	a := &artifact{
+		config: config,
+		layers: make(map[v1.Hash]v1.Layer, len(layers)),
+	}
+	configDigest, configSize, err := v1.SHA256(bytes.NewReader(config))
+	if err != nil {
+		return nil, err
+	}
+	m := v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config: v1.Descriptor{
+			MediaType: configMediaType,
+			Size:      configSize,
+			Digest:    configDigest,
+		},
+	}
+	for _, l := range layers {
+		desc, err := partial.Descriptor(l)
+		if err != nil {
+			return nil, errors.Wrap(err, "describing layer")
+		}
+		m.Layers = append(m.Layers, *desc)
+		a.layers[desc.Digest] = l
+	}
+	if a.manifest, err = json.Marshal(m); err != nil {
+		return nil, err
+	}
+	img, err := partial.CompressedToImage(a)
+	if err != nil {
+		return nil, err
+	}
+	return signed.Image(img), nil
+}
+
+type artifact struct {
+	manifest []byte
+	config   []byte
+	layers   map[v1.Hash]v1.Layer
+}
+
+var _ partial.CompressedImageCore = (*artifact)(nil)
+
+// RawConfigFile implements partial.CompressedImageCore
+func (a *artifact) RawConfigFile() ([]byte, error) {
+	return a.config, nil
+}
+
+// MediaType implements partial.CompressedImageCore
+func (a *artifact) MediaType() (types.MediaType, error) {
+	return types.OCIManifestSchema1, nil
+}
+
+// RawManifest implements partial.CompressedImageCore
+func (a *artifact) RawManifest() ([]byte, error) {
+	return a.manifest, nil
+}
+
+// LayerByDigest implements partial.CompressedImageCore
+func (a *artifact) LayerByDigest(h v1.Hash) (partial.CompressedLayer, error) {
+	if l, ok := a.layers[h]; ok {
+		return l, nil
+	}
+	return nil, errors.Errorf("unknown layer %s", h)
+}
@@ -0,0 +1,76 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package static
+
+import (
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ggcrstatic "github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestNewArtifact(t *testing.T) {
+	config := `{"name":"mychart","version":"0.1.0"}`
+	img, err := NewArtifact([]byte(config), "application/vnd.example.config",
+		ggcrstatic.NewLayer([]byte("content"), "application/vnd.example.content"),
+		ggcrstatic.NewLayer([]byte("provenance"), "application/vnd.example.provenance"))
+	if err != nil {
+		t.Fatalf("NewArtifact() = %v", err)
+	}
+
+	m, err := img.Manifest()
+	if err != nil {
+		t.Fatalf("Manifest() = %v", err)
+	}
+	if got, want := m.MediaType, types.OCIManifestSchema1; got != want {
+		t.Errorf("MediaType = %s, wanted %s", got, want)
+	}
+	if got, want := m.Config.MediaType, types.MediaType("application/vnd.example.config"); got != want {
+		t.Errorf("Config.MediaType = %s, wanted %s", got, want)
+	}
+	var gotLayers []types.MediaType
+	for _, l := range m.Layers {
+		gotLayers = append(gotLayers, l.MediaType)
+	}
+	if diff := cmp.Diff([]types.MediaType{"application/vnd.example.content", "application/vnd.example.provenance"}, gotLayers); diff != "" {
+		t.Errorf("layer media types (-want +got): %s", diff)
+	}
+
+	l, err := img.LayerByDigest(m.Layers[1].Digest)
+	if err != nil {
+		t.Fatalf("LayerByDigest() = %v", err)
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed() = %v", err)
+	}
+	defer rc.Close()
+	if b, err := io.ReadAll(rc); err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	} else if string(b) != "provenance" {
+		t.Errorf("layer content = %q, wanted provenance", b)
+	}
+
+	gotConfig, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatalf("RawConfigFile() = %v", err)
+	}
+	if string(gotConfig) != config {
+		t.Errorf("RawConfigFile() = %s, wanted %s", gotConfig, config)
+	}
+}
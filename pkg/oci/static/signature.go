@@ -34,6 +34,10 @@ const (
 	ChainAnnotationKey       = "dev.sigstore.cosign/chain"
 	BundleAnnotationKey      = "dev.sigstore.cosign/bundle"
 	TimestampAnnotationKey   = "dev.sigstore.cosign/timestamp"
+	// PIVAttestationAnnotationKey holds the PEM attestation certificate of
+	// the PIV slot the signing key was generated in, followed by the
+	// attestation certificate of the device.
+	PIVAttestationAnnotationKey = "dev.sigstore.cosign/piv-attestation"
 )
 
 // NewSignature constructs a new oci.Signature from the provided options.
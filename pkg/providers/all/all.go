@@ -19,14 +19,21 @@ import (
 	"github.com/sigstore/cosign/pkg/providers"
 
 	// Link in all of the providers.
+	_ "github.com/sigstore/cosign/pkg/providers/buildkite"
+	_ "github.com/sigstore/cosign/pkg/providers/circleci"
 	_ "github.com/sigstore/cosign/pkg/providers/filesystem"
 	_ "github.com/sigstore/cosign/pkg/providers/github"
+	_ "github.com/sigstore/cosign/pkg/providers/gitlab"
 	_ "github.com/sigstore/cosign/pkg/providers/google"
+	_ "github.com/sigstore/cosign/pkg/providers/kubernetes"
 	_ "github.com/sigstore/cosign/pkg/providers/spiffe"
 )
 
 // Alias these methods, so that folks can import this to get all providers.
 var (
-	Enabled = providers.Enabled
-	Provide = providers.Provide
+	Enabled     = providers.Enabled
+	Detected    = providers.Detected
+	Names       = providers.Names
+	Provide     = providers.Provide
+	ProvideFrom = providers.ProvideFrom
 )
@@ -0,0 +1,110 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildkite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/sigstore/cosign/pkg/providers"
+)
+
+func init() {
+	providers.RegisterWithPriority("buildkite-agent", providers.PriorityCI, &buildkiteAgent{})
+}
+
+type buildkiteAgent struct{}
+
+var _ providers.Interface = (*buildkiteAgent)(nil)
+var _ providers.BuildDescriber = (*buildkiteAgent)(nil)
+
+const (
+	AccessTokenEnvKey = "BUILDKITE_AGENT_ACCESS_TOKEN"
+	EndpointEnvKey    = "BUILDKITE_AGENT_ENDPOINT"
+	JobIDEnvKey       = "BUILDKITE_JOB_ID"
+
+	// DefaultEndpoint is the agent API used when BUILDKITE_AGENT_ENDPOINT
+	// isn't set.
+	DefaultEndpoint = "https://agent.buildkite.com/v3"
+)
+
+// Enabled implements providers.Interface
+func (ba *buildkiteAgent) Enabled(ctx context.Context) bool {
+	if os.Getenv(AccessTokenEnvKey) == "" {
+		return false
+	}
+	if os.Getenv(JobIDEnvKey) == "" {
+		return false
+	}
+	return true
+}
+
+// Provide implements providers.Interface
+// It requests a token for the current job from the agent API, like
+// `buildkite-agent oidc request-token` does.
+func (ba *buildkiteAgent) Provide(ctx context.Context, audience string) (string, error) {
+	endpoint := os.Getenv(EndpointEnvKey)
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	body, err := json.Marshal(struct {
+		Audience string `json:"audience"`
+	}{audience})
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("%s/jobs/%s/oidc/tokens", endpoint, os.Getenv(JobIDEnvKey))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Authorization", "Token "+os.Getenv(AccessTokenEnvKey))
+	req.Header.Add("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("requesting an OIDC token from the Buildkite agent API: %s", resp.Status)
+	}
+
+	var payload struct {
+		Token string `json:"token"`
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	if err := decoder.Decode(&payload); err != nil {
+		return "", err
+	}
+	return payload.Token, nil
+}
+
+// DescribeBuild implements providers.BuildDescriber
+func (ba *buildkiteAgent) DescribeBuild(ctx context.Context) (providers.Build, bool) {
+	if os.Getenv("BUILDKITE") != "true" {
+		return providers.Build{}, false
+	}
+	return providers.Build{
+		RunURL:    os.Getenv("BUILDKITE_BUILD_URL"),
+		CommitSHA: os.Getenv("BUILDKITE_COMMIT"),
+	}, true
+}
@@ -0,0 +1,72 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProvide(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v3/jobs/job-id/oidc/tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Token agent-token" {
+			http.Error(w, "bad authorization "+got, http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Audience string `json:"audience"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"token": "token-for-" + req.Audience})
+	}))
+	defer s.Close()
+
+	ctx := context.Background()
+	ba := &buildkiteAgent{}
+	t.Setenv(AccessTokenEnvKey, "")
+	t.Setenv(JobIDEnvKey, "job-id")
+	if ba.Enabled(ctx) {
+		t.Error("Enabled() without an agent access token")
+	}
+	t.Setenv(AccessTokenEnvKey, "agent-token")
+	t.Setenv(EndpointEnvKey, s.URL+"/v3")
+	if !ba.Enabled(ctx) {
+		t.Error("Enabled() = false, wanted true")
+	}
+	tok, err := ba.Provide(ctx, "sigstore")
+	if err != nil {
+		t.Fatalf("Provide() = %v", err)
+	}
+	if tok != "token-for-sigstore" {
+		t.Errorf("Provide() = %q, wanted token-for-sigstore", tok)
+	}
+
+	t.Setenv(AccessTokenEnvKey, "wrong")
+	if _, err := ba.Provide(ctx, "sigstore"); err == nil {
+		t.Error("Provide() with a wrong agent access token succeeded")
+	}
+}
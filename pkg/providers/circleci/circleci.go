@@ -0,0 +1,66 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circleci
+
+import (
+	"context"
+	"os"
+
+	"github.com/sigstore/cosign/pkg/providers"
+)
+
+func init() {
+	providers.RegisterWithPriority("circleci", providers.PriorityCI, &circleCI{})
+}
+
+type circleCI struct{}
+
+var _ providers.Interface = (*circleCI)(nil)
+var _ providers.BuildDescriber = (*circleCI)(nil)
+
+const (
+	// TokenEnvKey is the environment variable CircleCI jobs get their
+	// OIDC token in.
+	TokenEnvKey = "CIRCLE_OIDC_TOKEN"
+	// TokenV2EnvKey is the environment variable CircleCI jobs get their
+	// OIDC token in, with additional claims, preferred when set.
+	TokenV2EnvKey = "CIRCLE_OIDC_TOKEN_V2"
+)
+
+// Enabled implements providers.Interface
+func (cc *circleCI) Enabled(ctx context.Context) bool {
+	return os.Getenv(TokenV2EnvKey) != "" || os.Getenv(TokenEnvKey) != ""
+}
+
+// Provide implements providers.Interface
+// The audience of the token is the ID of the CircleCI organization.
+func (cc *circleCI) Provide(ctx context.Context, audience string) (string, error) {
+	if tok := os.Getenv(TokenV2EnvKey); tok != "" {
+		return tok, nil
+	}
+	return os.Getenv(TokenEnvKey), nil
+}
+
+// DescribeBuild implements providers.BuildDescriber
+func (cc *circleCI) DescribeBuild(ctx context.Context) (providers.Build, bool) {
+	if os.Getenv("CIRCLECI") != "true" {
+		return providers.Build{}, false
+	}
+	return providers.Build{
+		RunURL:    os.Getenv("CIRCLE_BUILD_URL"),
+		CommitSHA: os.Getenv("CIRCLE_SHA1"),
+	}, true
+}
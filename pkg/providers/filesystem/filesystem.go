@@ -23,7 +23,7 @@ import (
 )
 
 func init() {
-	providers.Register("filesystem", &filesystem{})
+	providers.RegisterWithPriority("filesystem", providers.PriorityFile, &filesystem{})
 }
 
 type filesystem struct{}
@@ -18,6 +18,7 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 
@@ -25,12 +26,13 @@ import (
 )
 
 func init() {
-	providers.Register("github-actions", &githubActions{})
+	providers.RegisterWithPriority("github-actions", providers.PriorityCI, &githubActions{})
 }
 
 type githubActions struct{}
 
 var _ providers.Interface = (*githubActions)(nil)
+var _ providers.BuildDescriber = (*githubActions)(nil)
 
 const (
 	RequestTokenEnvKey = "ACTIONS_ID_TOKEN_REQUEST_TOKEN"
@@ -74,3 +76,14 @@ func (ga *githubActions) Provide(ctx context.Context, audience string) (string,
 	}
 	return payload.Value, nil
 }
+
+// DescribeBuild implements providers.BuildDescriber
+func (ga *githubActions) DescribeBuild(ctx context.Context) (providers.Build, bool) {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return providers.Build{}, false
+	}
+	return providers.Build{
+		RunURL:    fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")),
+		CommitSHA: os.Getenv("GITHUB_SHA"),
+	}, true
+}
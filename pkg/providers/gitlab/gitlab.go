@@ -0,0 +1,60 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitlab
+
+import (
+	"context"
+	"os"
+
+	"github.com/sigstore/cosign/pkg/providers"
+)
+
+func init() {
+	providers.RegisterWithPriority("gitlab-ci", providers.PriorityCI, &gitlabCI{})
+}
+
+type gitlabCI struct{}
+
+var _ providers.Interface = (*gitlabCI)(nil)
+var _ providers.BuildDescriber = (*gitlabCI)(nil)
+
+const (
+	// TokenEnvKey is the environment variable GitLab CI jobs get their
+	// OIDC token in.
+	TokenEnvKey = "CI_JOB_JWT_V2"
+)
+
+// Enabled implements providers.Interface
+func (gl *gitlabCI) Enabled(ctx context.Context) bool {
+	return os.Getenv(TokenEnvKey) != ""
+}
+
+// Provide implements providers.Interface
+// The audience of the token is the one configured for the GitLab instance.
+func (gl *gitlabCI) Provide(ctx context.Context, audience string) (string, error) {
+	return os.Getenv(TokenEnvKey), nil
+}
+
+// DescribeBuild implements providers.BuildDescriber
+func (gl *gitlabCI) DescribeBuild(ctx context.Context) (providers.Build, bool) {
+	if os.Getenv("GITLAB_CI") != "true" {
+		return providers.Build{}, false
+	}
+	return providers.Build{
+		RunURL:    os.Getenv("CI_JOB_URL"),
+		CommitSHA: os.Getenv("CI_COMMIT_SHA"),
+	}, true
+}
@@ -27,8 +27,8 @@ import (
 )
 
 func init() {
-	providers.Register("google-workload-identity", &googleWorkloadIdentity{})
-	providers.Register("google-impersonate", &googleImpersonate{})
+	providers.RegisterWithPriority("google-workload-identity", providers.PriorityCloud, &googleWorkloadIdentity{})
+	providers.RegisterWithPriority("google-impersonate", providers.PriorityCloud, &googleImpersonate{})
 }
 
 type googleWorkloadIdentity struct{}
@@ -19,12 +19,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+)
+
+// Priorities of the built-in providers. Enabled providers are tried from the
+// highest priority to the lowest, and in name order for equal priorities, so
+// the token used doesn't depend on registration order: providers set up
+// explicitly for the job at hand (CI systems, mounted tokens) come before the
+// ones that are merely available in the environment (cloud metadata).
+const (
+	PriorityCI       = 100
+	PriorityFile     = 80
+	PriorityWorkload = 60
+	PriorityCloud    = 40
+	// DefaultPriority is the priority of the providers registered with Register.
+	DefaultPriority = 0
 )
 
 var (
 	m         sync.Mutex
-	providers = make(map[string]Interface)
+	providers = make(map[string]provider)
 )
 
 // Interface is what providers need to implement to participate in furnishing OIDC tokens.
@@ -36,15 +53,88 @@ type Interface interface {
 	Provide(ctx context.Context, audience string) (string, error)
 }
 
-// Register is used by providers to participate in furnishing OIDC tokens.
+// Build describes a CI build.
+type Build struct {
+	// RunURL is the URL of the CI run.
+	RunURL string
+	// CommitSHA is the commit being built.
+	CommitSHA string
+}
+
+// BuildDescriber is implemented by the providers of CI systems, to describe
+// the build they run in whether or not they can furnish OIDC tokens there.
+type BuildDescriber interface {
+	// DescribeBuild returns the build running in this execution context, and
+	// whether there is one.
+	DescribeBuild(ctx context.Context) (Build, bool)
+}
+
+// provider is a registered provider.
+type provider struct {
+	Interface
+	name     string
+	priority int
+}
+
+// Register is used by providers to participate in furnishing OIDC tokens,
+// with DefaultPriority.
 func Register(name string, p Interface) {
+	RegisterWithPriority(name, DefaultPriority, p)
+}
+
+// RegisterWithPriority is like Register, but the enabled providers with a
+// higher priority are tried first.
+func RegisterWithPriority(name string, priority int, p Interface) {
 	m.Lock()
 	defer m.Unlock()
 
 	if prev, ok := providers[name]; ok {
-		panic(fmt.Sprintf("duplicate provider for name %q, %T and %T", name, prev, p))
+		panic(fmt.Sprintf("duplicate provider for name %q, %T and %T", name, prev.Interface, p))
+	}
+	providers[name] = provider{Interface: p, name: name, priority: priority}
+}
+
+// ordered returns the registered providers in the order they are tried.
+func ordered(registered map[string]provider) []provider {
+	all := make([]provider, 0, len(registered))
+	for _, p := range registered {
+		all = append(all, p)
 	}
-	providers[name] = p
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].priority != all[j].priority {
+			return all[i].priority > all[j].priority
+		}
+		return all[i].name < all[j].name
+	})
+	return all
+}
+
+// selectProviders returns the providers to fetch a token from, in order: the
+// provider called name if it is set, whether it detects itself as enabled or
+// not, or else the enabled ones.
+func selectProviders(ctx context.Context, registered map[string]provider, name string) ([]provider, error) {
+	if name != "" {
+		p, ok := registered[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown OIDC provider %q, expected one of %v", name, names(ordered(registered)))
+		}
+		return []provider{p}, nil
+	}
+	var enabled []provider
+	for _, p := range ordered(registered) {
+		if p.Enabled(ctx) {
+			enabled = append(enabled, p)
+		}
+	}
+	return enabled, nil
+}
+
+func names(ps []provider) []string {
+	n := make([]string, 0, len(ps))
+	for _, p := range ps {
+		n = append(n, p.name)
+	}
+	return n
 }
 
 // Enabled checks whether any of the registered providers are enabled in this execution context.
@@ -60,21 +150,50 @@ func Enabled(ctx context.Context) bool {
 	return false
 }
 
+// Detected returns the names of the providers enabled in this execution
+// context, in the order Provide tries them.
+func Detected(ctx context.Context) []string {
+	m.Lock()
+	defer m.Unlock()
+
+	enabled, _ := selectProviders(ctx, providers, "")
+	return names(enabled)
+}
+
+// Names returns the names of the registered providers, in the order Provide
+// tries them when they are enabled.
+func Names() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	return names(ordered(providers))
+}
+
 // Provide fetches an OIDC token from one of the active providers.
 func Provide(ctx context.Context, audience string) (string, error) {
+	return ProvideFrom(ctx, "", audience)
+}
+
+// ProvideFrom fetches an OIDC token from the provider called name, or from
+// one of the active providers, like Provide, if name is empty.
+func ProvideFrom(ctx context.Context, name, audience string) (string, error) {
 	m.Lock()
 	defer m.Unlock()
 
+	selected, err := selectProviders(ctx, providers, name)
+	if err != nil {
+		return "", err
+	}
+	logs.Debug.Printf("OIDC providers to try: %v", names(selected))
+
 	var id string
-	var err error
-	for _, provider := range providers {
-		if !provider.Enabled(ctx) {
-			continue
-		}
+	for _, provider := range selected {
 		id, err = provider.Provide(ctx, audience)
 		if err == nil {
+			logs.Debug.Printf("Fetched an OIDC token from provider %q", provider.name)
 			return id, err
 		}
+		logs.Debug.Printf("Fetching an OIDC token from provider %q: %v", provider.name, err)
 	}
 	// return the last id/err combo, unless there wasn't an error in
 	// which case provider.Enabled() wasn't checked.
@@ -83,3 +202,26 @@ func Provide(ctx context.Context, audience string) (string, error) {
 	}
 	return id, err
 }
+
+// DescribeBuild returns the build running in this execution context, as
+// described by the first registered provider, in the order Provide tries
+// them, that detects one.
+func DescribeBuild(ctx context.Context) (Build, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	return describeBuild(ctx, providers)
+}
+
+func describeBuild(ctx context.Context, registered map[string]provider) (Build, bool) {
+	for _, p := range ordered(registered) {
+		bd, ok := p.Interface.(BuildDescriber)
+		if !ok {
+			continue
+		}
+		if b, ok := bd.DescribeBuild(ctx); ok {
+			return b, true
+		}
+	}
+	return Build{}, false
+}
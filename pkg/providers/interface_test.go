@@ -0,0 +1,144 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fakeProvider struct {
+	enabled bool
+	token   string
+	err     error
+}
+
+func (f *fakeProvider) Enabled(context.Context) bool { return f.enabled }
+
+func (f *fakeProvider) Provide(context.Context, string) (string, error) { return f.token, f.err }
+
+func TestSelectProviders(t *testing.T) {
+	ctx := context.Background()
+	registered := map[string]provider{}
+	for _, p := range []provider{
+		{Interface: &fakeProvider{enabled: true}, name: "google-workload-identity", priority: PriorityCloud},
+		{Interface: &fakeProvider{enabled: true}, name: "google-impersonate", priority: PriorityCloud},
+		{Interface: &fakeProvider{enabled: true}, name: "filesystem", priority: PriorityFile},
+		{Interface: &fakeProvider{}, name: "spiffe", priority: PriorityWorkload},
+		{Interface: &fakeProvider{enabled: true}, name: "github-actions", priority: PriorityCI},
+		{Interface: &fakeProvider{enabled: true}, name: "custom", priority: DefaultPriority},
+	} {
+		registered[p.name] = p
+	}
+
+	tests := []struct {
+		name    string
+		force   string
+		want    []string
+		wantErr bool
+	}{{
+		name: "detected",
+		want: []string{"github-actions", "filesystem", "google-impersonate", "google-workload-identity", "custom"},
+	}, {
+		name:  "forced",
+		force: "google-workload-identity",
+		want:  []string{"google-workload-identity"},
+	}, {
+		name:  "forced but not enabled",
+		force: "spiffe",
+		want:  []string{"spiffe"},
+	}, {
+		name:    "unknown",
+		force:   "gitlab",
+		wantErr: true,
+	}}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// Map iteration order varies, selection shouldn't.
+			for i := 0; i < 10; i++ {
+				got, err := selectProviders(ctx, registered, tc.force)
+				if (err != nil) != tc.wantErr {
+					t.Fatalf("selectProviders() = %v, wantErr %t", err, tc.wantErr)
+				}
+				if err == nil && !reflect.DeepEqual(names(got), tc.want) {
+					t.Fatalf("selectProviders() = %v, wanted %v", names(got), tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestProvideFrom(t *testing.T) {
+	ctx := context.Background()
+	m.Lock()
+	saved := providers
+	providers = map[string]provider{
+		"broken": {Interface: &fakeProvider{enabled: true, err: errors.New("broken")}, name: "broken", priority: PriorityCI},
+		"works":  {Interface: &fakeProvider{enabled: true, token: "token"}, name: "works", priority: DefaultPriority},
+	}
+	m.Unlock()
+	defer func() {
+		m.Lock()
+		providers = saved
+		m.Unlock()
+	}()
+
+	// Falls back to the next provider when one fails.
+	if tok, err := Provide(ctx, "sigstore"); err != nil || tok != "token" {
+		t.Errorf("Provide() = %q, %v, wanted token", tok, err)
+	}
+	if _, err := ProvideFrom(ctx, "broken", "sigstore"); err == nil {
+		t.Error("ProvideFrom(broken) succeeded")
+	}
+	if got, want := Detected(ctx), []string{"broken", "works"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Detected() = %v, wanted %v", got, want)
+	}
+}
+
+type fakeCIProvider struct {
+	fakeProvider
+	build *Build
+}
+
+func (f *fakeCIProvider) DescribeBuild(context.Context) (Build, bool) {
+	if f.build == nil {
+		return Build{}, false
+	}
+	return *f.build, true
+}
+
+func TestDescribeBuild(t *testing.T) {
+	ctx := context.Background()
+	gitlab := Build{RunURL: "https://gitlab.example.com/jobs/1", CommitSHA: "abc"}
+	registered := map[string]provider{
+		"filesystem":     {Interface: &fakeProvider{enabled: true}, name: "filesystem", priority: PriorityFile},
+		"github-actions": {Interface: &fakeCIProvider{}, name: "github-actions", priority: PriorityCI},
+		// Builds are described even where no OIDC token is available.
+		"gitlab-ci": {Interface: &fakeCIProvider{build: &gitlab}, name: "gitlab-ci", priority: PriorityCI},
+	}
+	got, ok := describeBuild(ctx, registered)
+	if !ok || got != gitlab {
+		t.Errorf("describeBuild() = %v, %t, wanted %v", got, ok, gitlab)
+	}
+
+	delete(registered, "gitlab-ci")
+	if got, ok := describeBuild(ctx, registered); ok {
+		t.Errorf("describeBuild() = %v, wanted no build", got)
+	}
+}
@@ -0,0 +1,115 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sigstore/cosign/pkg/providers"
+)
+
+func init() {
+	providers.RegisterWithPriority("kubernetes", providers.PriorityFile, &projectedToken{})
+}
+
+type projectedToken struct{}
+
+var _ providers.Interface = (*projectedToken)(nil)
+
+const (
+	// TokenPathEnvKey overrides the path the projected service account
+	// token is read from.
+	TokenPathEnvKey = "COSIGN_KUBERNETES_TOKEN_PATH"
+	// AudienceEnvKey overrides the audience the projected service account
+	// token must have, which is otherwise the one requested.
+	AudienceEnvKey = "COSIGN_KUBERNETES_TOKEN_AUDIENCE"
+
+	// DefaultTokenPath is where the projected service account token is read
+	// from when COSIGN_KUBERNETES_TOKEN_PATH isn't set.
+	// nolint
+	DefaultTokenPath = "/var/run/sigstore/cosign/serviceaccount/token"
+)
+
+func tokenPath() string {
+	if p := os.Getenv(TokenPathEnvKey); p != "" {
+		return p
+	}
+	return DefaultTokenPath
+}
+
+// Enabled implements providers.Interface
+func (pt *projectedToken) Enabled(ctx context.Context) bool {
+	// If we can stat the file without error then this is enabled.
+	_, err := os.Stat(tokenPath())
+	return err == nil
+}
+
+// Provide implements providers.Interface
+// The token is projected with its audience, so Provide only checks it is the
+// expected one, instead of handing out a token Fulcio would reject.
+func (pt *projectedToken) Provide(ctx context.Context, audience string) (string, error) {
+	b, err := os.ReadFile(tokenPath())
+	if err != nil {
+		return "", err
+	}
+	tok := strings.TrimSpace(string(b))
+	if want := os.Getenv(AudienceEnvKey); want != "" {
+		audience = want
+	}
+	auds, err := audiences(tok)
+	if err != nil {
+		return "", err
+	}
+	for _, aud := range auds {
+		if aud == audience {
+			return tok, nil
+		}
+	}
+	return "", fmt.Errorf("projected service account token %s has audiences %v, expected %q", tokenPath(), auds, audience)
+}
+
+// audiences returns the aud claim of the JWT tok, without verifying it.
+func audiences(tok string) ([]string, error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("projected service account token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding projected service account token: %w", err)
+	}
+	var claims struct {
+		Audience json.RawMessage `json:"aud"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("decoding projected service account token: %w", err)
+	}
+	// aud is either a single string or an array of strings.
+	var aud string
+	if err := json.Unmarshal(claims.Audience, &aud); err == nil {
+		return []string{aud}, nil
+	}
+	var auds []string
+	if err := json.Unmarshal(claims.Audience, &auds); err != nil {
+		return nil, fmt.Errorf("decoding audiences of projected service account token: %w", err)
+	}
+	return auds, nil
+}
@@ -0,0 +1,87 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func jwt(claims string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"RS256"}`)) + "." + enc.EncodeToString([]byte(claims)) + "." + enc.EncodeToString([]byte("signature"))
+}
+
+func TestProvide(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "token")
+	t.Setenv(TokenPathEnvKey, path)
+	t.Setenv(AudienceEnvKey, "")
+	pt := &projectedToken{}
+	if pt.Enabled(ctx) {
+		t.Error("Enabled() without a token")
+	}
+
+	tests := []struct {
+		name     string
+		claims   string
+		audience string
+		wantErr  bool
+	}{{
+		name:   "audience",
+		claims: `{"aud":"sigstore","sub":"system:serviceaccount:default:default"}`,
+	}, {
+		name:   "audiences",
+		claims: `{"aud":["other","sigstore"]}`,
+	}, {
+		name:    "wrong audience",
+		claims:  `{"aud":["https://kubernetes.default.svc"]}`,
+		wantErr: true,
+	}, {
+		name:     "configured audience",
+		claims:   `{"aud":"https://fulcio.example.com"}`,
+		audience: "https://fulcio.example.com",
+	}, {
+		name:    "not a JWT",
+		claims:  "",
+		wantErr: true,
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tok := "not a JWT"
+			if tc.claims != "" {
+				tok = jwt(tc.claims)
+			}
+			if err := os.WriteFile(path, []byte(tok+"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(AudienceEnvKey, tc.audience)
+			if !pt.Enabled(ctx) {
+				t.Fatal("Enabled() = false, wanted true")
+			}
+			got, err := pt.Provide(ctx, "sigstore")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Provide() = %v, wantErr %t", err, tc.wantErr)
+			}
+			if err == nil && got != tok {
+				t.Errorf("Provide() = %q, wanted %q", got, tok)
+			}
+		})
+	}
+}
@@ -26,7 +26,7 @@ import (
 )
 
 func init() {
-	providers.Register("spiffe", &spiffe{})
+	providers.RegisterWithPriority("spiffe", providers.PriorityWorkload, &spiffe{})
 }
 
 type spiffe struct{}
@@ -98,14 +98,11 @@ func (sg *SecureGet) Do(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	layers, err := img.Layers()
+	layer, err := cosign.ArtifactLayer(img)
 	if err != nil {
-		return err
-	}
-	if len(layers) != 1 {
-		return errors.New("invalid artifact")
+		return errors.Wrap(err, "invalid artifact")
 	}
-	rc, err := layers[0].Compressed()
+	rc, err := layer.Compressed()
 	if err != nil {
 		return err
 	}
@@ -18,34 +18,33 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
-	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/pkg/errors"
 
 	"github.com/sigstore/cosign/pkg/blob"
 	"github.com/sigstore/cosign/pkg/cosign"
-	"github.com/sigstore/cosign/pkg/cosign/git"
-	"github.com/sigstore/cosign/pkg/cosign/git/gitlab"
-	"github.com/sigstore/cosign/pkg/cosign/kubernetes"
-	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/signature/kms"
 )
 
-// LoadPublicKey is a wrapper for VerifierForKeyRef, hardcoding SHA256 as the hash algorithm
+// LoadPublicKey is a wrapper for VerifierForKeyRef, using the hash algorithm matching the key
 func LoadPublicKey(ctx context.Context, keyRef string) (verifier signature.Verifier, err error) {
-	return VerifierForKeyRef(ctx, keyRef, crypto.SHA256)
+	return VerifierForKeyRef(ctx, keyRef, 0)
 }
 
 // VerifierForKeyRef parses the given keyRef, loads the key and returns an appropriate
-// verifier using the provided hash algorithm
+// verifier using the provided hash algorithm. A zero hashAlgorithm selects the one
+// matching the key, see cosign.HashAlgorithmForKey.
 func VerifierForKeyRef(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (verifier signature.Verifier, err error) {
 	// The key could be plaintext, in a file, at a URL, or in KMS.
-	if kmsKey, err := kms.Get(ctx, keyRef, hashAlgorithm); err == nil {
+	kmsHashAlgorithm := hashAlgorithm
+	if kmsHashAlgorithm == 0 {
+		kmsHashAlgorithm = crypto.SHA256
+	}
+	if kmsKey, err := kms.Get(ctx, keyRef, kmsHashAlgorithm); err == nil {
 		// KMS specified
 		return kmsKey, nil
 	}
@@ -62,6 +61,15 @@ func VerifierForKeyRef(ctx context.Context, keyRef string, hashAlgorithm crypto.
 		return nil, errors.Wrap(err, "pem to public key")
 	}
 
+	return loadVerifier(pubKey, hashAlgorithm)
+}
+
+// loadVerifier returns a verifier for pubKey, picking the hash algorithm matching
+// the key if hashAlgorithm is zero.
+func loadVerifier(pubKey crypto.PublicKey, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
+	if hashAlgorithm == 0 {
+		return cosign.LoadVerifier(pubKey)
+	}
 	return signature.LoadVerifier(pubKey, hashAlgorithm)
 }
 
@@ -77,137 +85,43 @@ func loadKey(keyPath string, pf cosign.PassFunc) (signature.SignerVerifier, erro
 	return cosign.LoadPrivateKey(kb, pass)
 }
 
-// LoadPublicKeyRaw loads a verifier from a raw public key passed in
+// LoadPublicKeyRaw loads a verifier from a raw public key passed in. A zero
+// hashAlgorithm selects the one matching the key.
 func LoadPublicKeyRaw(raw []byte, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
 	// PEM encoded file.
-	ed, err := cosign.PemToECDSAKey(raw)
+	pubKey, err := cryptoutils.UnmarshalPEMToPublicKey(raw)
 	if err != nil {
-		return nil, errors.Wrap(err, "pem to ecdsa")
+		return nil, errors.Wrap(err, "pem to public key")
 	}
-	return signature.LoadECDSAVerifier(ed, hashAlgorithm)
+	return loadVerifier(pubKey, hashAlgorithm)
 }
 
 func SignerFromKeyRef(ctx context.Context, keyRef string, pf cosign.PassFunc) (signature.Signer, error) {
 	return SignerVerifierFromKeyRef(ctx, keyRef, pf)
 }
 
+// SignerVerifierFromKeyRef returns a signer verifier for the private key keyRef
+// refers to, through the key reference scheme registered for its prefix, or
+// from the file or URL keyRef names.
 func SignerVerifierFromKeyRef(ctx context.Context, keyRef string, pf cosign.PassFunc) (signature.SignerVerifier, error) {
-	switch {
-	case strings.HasPrefix(keyRef, pkcs11key.ReferenceScheme):
-		pkcs11UriConfig := pkcs11key.NewPkcs11UriConfig()
-		err := pkcs11UriConfig.Parse(keyRef)
-		if err != nil {
-			return nil, errors.Wrap(err, "parsing pkcs11 uri")
-		}
-
-		// Since we'll be signing, we need to set askForPinIsNeeded to true
-		// because we need access to the private key.
-		sk, err := pkcs11key.GetKeyWithURIConfig(pkcs11UriConfig, true)
-		if err != nil {
-			return nil, errors.Wrap(err, "opening pkcs11 token key")
-		}
-
-		sv, err := sk.SignerVerifier()
-		if err != nil {
-			return nil, errors.Wrap(err, "initializing pkcs11 token signer verifier")
-		}
-
-		return sv, nil
-	case strings.HasPrefix(keyRef, kubernetes.KeyReference):
-		s, err := kubernetes.GetKeyPairSecret(ctx, keyRef)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(s.Data) > 0 {
-			return cosign.LoadPrivateKey(s.Data["cosign.key"], s.Data["cosign.password"])
-		}
-	case strings.HasPrefix(keyRef, gitlab.ReferenceScheme):
-		split := strings.Split(keyRef, "://")
-
-		if len(split) < 2 {
-			return nil, errors.New("could not parse scheme, use <scheme>://<ref> format")
-		}
-
-		provider, targetRef := split[0], split[1]
-
-		pk, err := git.GetProvider(provider).GetSecret(ctx, targetRef, "COSIGN_PRIVATE_KEY")
-		if err != nil {
-			return nil, err
-		}
-
-		pass, err := git.GetProvider(provider).GetSecret(ctx, targetRef, "COSIGN_PASSWORD")
-		if err != nil {
-			return nil, err
-		}
-
-		return cosign.LoadPrivateKey([]byte(pk), []byte(pass))
-	}
-
-	sv, err := kms.Get(ctx, keyRef, crypto.SHA256)
-	if err == nil {
-		return sv, nil
+	if s, ok := keyRefScheme(keyRef); ok {
+		return s.SignerVerifier(ctx, keyRef, pf)
 	}
-
-	fmt.Fprintf(os.Stderr, "an error occurred: %v, will try to load key from disk...\n", err)
 	return loadKey(keyRef, pf)
 }
 
+// PublicKeyFromKeyRef is like PublicKeyFromKeyRefWithHashAlgo, using the hash algorithm matching the key.
 func PublicKeyFromKeyRef(ctx context.Context, keyRef string) (signature.Verifier, error) {
-	return PublicKeyFromKeyRefWithHashAlgo(ctx, keyRef, crypto.SHA256)
+	return PublicKeyFromKeyRefWithHashAlgo(ctx, keyRef, 0)
 }
 
+// PublicKeyFromKeyRefWithHashAlgo loads a verifier for keyRef using hashAlgorithm, or the
+// hash algorithm matching the key if it is zero, through the key reference scheme
+// registered for its prefix, or from the file or URL keyRef names.
 func PublicKeyFromKeyRefWithHashAlgo(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
-	if strings.HasPrefix(keyRef, kubernetes.KeyReference) {
-		s, err := kubernetes.GetKeyPairSecret(ctx, keyRef)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(s.Data) > 0 {
-			return LoadPublicKeyRaw(s.Data["cosign.pub"], hashAlgorithm)
-		}
+	if s, ok := keyRefScheme(keyRef); ok {
+		return s.Verifier(ctx, keyRef, hashAlgorithm)
 	}
-
-	if strings.HasPrefix(keyRef, pkcs11key.ReferenceScheme) {
-		pkcs11UriConfig := pkcs11key.NewPkcs11UriConfig()
-		err := pkcs11UriConfig.Parse(keyRef)
-		if err != nil {
-			return nil, errors.Wrap(err, "parsing pkcs11 uri")
-		}
-
-		// Since we'll be verifying a signature, we do not need to set askForPinIsNeeded to true
-		// because we only need access to the public key.
-		sk, err := pkcs11key.GetKeyWithURIConfig(pkcs11UriConfig, false)
-		if err != nil {
-			return nil, errors.Wrap(err, "opening pkcs11 token key")
-		}
-
-		v, err := sk.Verifier()
-		if err != nil {
-			return nil, errors.Wrap(err, "initializing pkcs11 token verifier")
-		}
-
-		return v, nil
-	} else if strings.HasPrefix(keyRef, gitlab.ReferenceScheme) {
-		split := strings.Split(keyRef, "://")
-
-		if len(split) < 2 {
-			return nil, errors.New("could not parse scheme, use <scheme>://<ref> format")
-		}
-
-		provider, targetRef := split[0], split[1]
-
-		pubKey, err := git.GetProvider(provider).GetSecret(ctx, targetRef, "COSIGN_PUBLIC_KEY")
-		if err != nil {
-			return nil, err
-		}
-
-		if len(pubKey) > 0 {
-			return LoadPublicKeyRaw([]byte(pubKey), hashAlgorithm)
-		}
-	}
-
 	return VerifierForKeyRef(ctx, keyRef, hashAlgorithm)
 }
 
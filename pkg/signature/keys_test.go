@@ -15,11 +15,16 @@
 package signature
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/sha256"
 	"os"
 	"testing"
 
 	"github.com/sigstore/cosign/pkg/cosign"
+	sigsig "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 func generateKeyFile(t *testing.T, tmpDir string, pf cosign.PassFunc) (privFile, pubFile string) {
@@ -105,8 +110,106 @@ func TestPublicKeyFromFileRef(t *testing.T) {
 	}
 }
 
+// testScheme furnishes the key it was created with for any key reference.
+type testScheme struct {
+	sv sigsig.SignerVerifier
+}
+
+func (s testScheme) SignerVerifier(context.Context, string, cosign.PassFunc) (sigsig.SignerVerifier, error) {
+	return s.sv, nil
+}
+
+func (s testScheme) Verifier(context.Context, string, crypto.Hash) (sigsig.Verifier, error) {
+	return s.sv, nil
+}
+
+func TestRegisterKeyRefScheme(t *testing.T) {
+	ctx := context.Background()
+	priv, err := cosign.GeneratePrivateKey()
+	if err != nil {
+		t.Fatalf("GeneratePrivateKey() = %v", err)
+	}
+	sv, err := sigsig.LoadECDSASignerVerifier(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("LoadECDSASignerVerifier() = %v", err)
+	}
+	RegisterKeyRefScheme("cosign-test://", testScheme{sv: sv})
+
+	if got, err := SignerVerifierFromKeyRef(ctx, "cosign-test://key", pass("whatever")); err != nil || got != sv {
+		t.Errorf("SignerVerifierFromKeyRef() = %v, %v, wanted the registered key", got, err)
+	}
+	if got, err := PublicKeyFromKeyRef(ctx, "cosign-test://key"); err != nil || got != sv {
+		t.Errorf("PublicKeyFromKeyRef() = %v, %v, wanted the registered key", got, err)
+	}
+	// Other references aren't handed to the scheme.
+	if _, err := PublicKeyFromKeyRef(ctx, "cosign-test:/key"); err == nil {
+		t.Error("PublicKeyFromKeyRef() of a missing file succeeded")
+	}
+
+	found := false
+	for _, prefix := range KeyRefSchemes() {
+		found = found || prefix == "cosign-test://"
+	}
+	if !found {
+		t.Errorf("KeyRefSchemes() = %v, wanted it to include cosign-test://", KeyRefSchemes())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate scheme didn't panic")
+		}
+	}()
+	RegisterKeyRefScheme("cosign-test://", testScheme{sv: sv})
+}
+
 func pass(s string) cosign.PassFunc {
 	return func(_ bool) ([]byte, error) {
 		return []byte(s), nil
 	}
 }
+
+func TestLoadPublicKeysRaw(t *testing.T) {
+	var pems [][]byte
+	var signers []sigsig.Signer
+	for i := 0; i < 2; i++ {
+		keys, err := cosign.GenerateKeyPair(pass("hello"))
+		if err != nil {
+			t.Fatalf("GenerateKeyPair() = %v", err)
+		}
+		sv, err := cosign.LoadPrivateKey(keys.PrivateBytes, []byte("hello"))
+		if err != nil {
+			t.Fatalf("LoadPrivateKey() = %v", err)
+		}
+		pems = append(pems, keys.PublicBytes)
+		signers = append(signers, sv)
+	}
+	msg := []byte("payload")
+	sig, err := signers[1].SignMessage(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatalf("SignMessage() = %v", err)
+	}
+
+	single, err := loadPublicKeysRaw(pems[0], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("loadPublicKeysRaw() = %v", err)
+	}
+	if err := single.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)); err == nil {
+		t.Error("VerifySignature() with another key succeeded")
+	}
+
+	multi, err := loadPublicKeysRaw(bytes.Join(pems, []byte("\n")), crypto.SHA256)
+	if err != nil {
+		t.Fatalf("loadPublicKeysRaw() = %v", err)
+	}
+	if err := multi.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg)); err != nil {
+		t.Errorf("VerifySignature() = %v", err)
+	}
+	digest := sha256.Sum256(msg)
+	if err := multi.VerifySignature(bytes.NewReader(sig), nil, options.WithDigest(digest[:]), options.WithCryptoSignerOpts(crypto.SHA256)); err != nil {
+		t.Errorf("VerifySignature() with a digest = %v", err)
+	}
+
+	if _, err := loadPublicKeysRaw([]byte("not a key"), crypto.SHA256); err == nil {
+		t.Error("loadPublicKeysRaw() without keys succeeded")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// loadPublicKeysRaw is like LoadPublicKeyRaw, but accepts several PEM encoded
+// public keys, e.g. the current and rotated keys of a key pair, returning a
+// verifier accepting the signatures of any of them.
+func loadPublicKeysRaw(raw []byte, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
+	var verifiers multiVerifier
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			break
+		}
+		pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "pem to public key")
+		}
+		v, err := loadVerifier(pubKey, hashAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		verifiers = append(verifiers, v)
+	}
+	switch len(verifiers) {
+	case 0:
+		return nil, errors.New("pem to public key: PEM decoding failed")
+	case 1:
+		return verifiers[0], nil
+	default:
+		return verifiers, nil
+	}
+}
+
+// multiVerifier accepts the signatures any of its verifiers accepts. Its
+// public key is the one of the first verifier, callers needing the key that
+// made a signature check it against each of Verifiers instead.
+type multiVerifier []signature.Verifier
+
+var _ signature.Verifier = multiVerifier(nil)
+
+// Verifiers returns the verifiers of each key of the ring.
+func (m multiVerifier) Verifiers() []signature.Verifier {
+	return m
+}
+
+// PublicKey implements signature.Verifier
+func (m multiVerifier) PublicKey(opts ...signature.PublicKeyOption) (crypto.PublicKey, error) {
+	return m[0].PublicKey(opts...)
+}
+
+// VerifySignature implements signature.Verifier
+func (m multiVerifier) VerifySignature(sig, message io.Reader, opts ...signature.VerifyOption) error {
+	// Each verifier consumes the readers.
+	sigBytes, err := io.ReadAll(sig)
+	if err != nil {
+		return err
+	}
+	// Signatures over a digest given in opts come without a message.
+	var msgBytes []byte
+	if message != nil {
+		if msgBytes, err = io.ReadAll(message); err != nil {
+			return err
+		}
+	}
+	for _, v := range m {
+		var msg io.Reader
+		if message != nil {
+			msg = bytes.NewReader(msgBytes)
+		}
+		if err = v.VerifySignature(bytes.NewReader(sigBytes), msg, opts...); err == nil {
+			return nil
+		}
+	}
+	return err
+}
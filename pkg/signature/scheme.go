@@ -0,0 +1,86 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+var (
+	m       sync.Mutex
+	schemes = make(map[string]KeyRefScheme)
+)
+
+// KeyRefScheme is what key reference schemes need to implement to furnish
+// the signers and verifiers of the key references they were registered for.
+type KeyRefScheme interface {
+	// SignerVerifier returns a signer verifier for the private key keyRef
+	// refers to, using pf to decrypt it if needed.
+	SignerVerifier(ctx context.Context, keyRef string, pf cosign.PassFunc) (signature.SignerVerifier, error)
+
+	// Verifier returns a verifier for the public key keyRef refers to, using
+	// hashAlgorithm, or the hash algorithm matching the key if it is zero.
+	Verifier(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (signature.Verifier, error)
+}
+
+// RegisterKeyRefScheme is used by key reference schemes to furnish the signers
+// and verifiers of the key references starting with prefix, e.g. "vault://".
+// Out-of-tree schemes can call it from the init function of a package the
+// binary imports.
+func RegisterKeyRefScheme(prefix string, s KeyRefScheme) {
+	m.Lock()
+	defer m.Unlock()
+
+	if prev, ok := schemes[prefix]; ok {
+		panic(fmt.Sprintf("duplicate key reference scheme for prefix %q, %T and %T", prefix, prev, s))
+	}
+	schemes[prefix] = s
+}
+
+// KeyRefSchemes returns the sorted prefixes of the registered key reference schemes.
+func KeyRefSchemes() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	prefixes := make([]string, 0, len(schemes))
+	for prefix := range schemes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// keyRefScheme returns the scheme registered for the longest prefix of keyRef.
+func keyRefScheme(keyRef string) (KeyRefScheme, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	var match string
+	for prefix := range schemes {
+		if strings.HasPrefix(keyRef, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	s, ok := schemes[match]
+	return s, ok && match != ""
+}
@@ -0,0 +1,226 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signature
+
+import (
+	"context"
+	"crypto"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/cosign/pkg/cosign"
+	"github.com/sigstore/cosign/pkg/cosign/git"
+	"github.com/sigstore/cosign/pkg/cosign/git/github"
+	"github.com/sigstore/cosign/pkg/cosign/git/gitlab"
+	"github.com/sigstore/cosign/pkg/cosign/kubernetes"
+	"github.com/sigstore/cosign/pkg/cosign/pkcs11key"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/kms"
+	"github.com/sigstore/sigstore/pkg/signature/kms/aws"
+	"github.com/sigstore/sigstore/pkg/signature/kms/azure"
+	"github.com/sigstore/sigstore/pkg/signature/kms/gcp"
+	"github.com/sigstore/sigstore/pkg/signature/kms/hashivault"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func init() {
+	RegisterKeyRefScheme(pkcs11key.ReferenceScheme, pkcs11Scheme{})
+	RegisterKeyRefScheme(kubernetes.KeyReference, kubernetesScheme{})
+	RegisterKeyRefScheme(github.ReferenceScheme+"://", gitScheme{})
+	RegisterKeyRefScheme(gitlab.ReferenceScheme+"://", gitScheme{})
+	for _, prefix := range []string{aws.ReferenceScheme, azure.ReferenceScheme, gcp.ReferenceScheme, hashivault.ReferenceScheme} {
+		RegisterKeyRefScheme(prefix, kmsScheme{})
+	}
+}
+
+// pkcs11Scheme furnishes the keys of PKCS11 tokens, referred to by PKCS11 URIs.
+type pkcs11Scheme struct{}
+
+// SignerVerifier implements KeyRefScheme
+func (pkcs11Scheme) SignerVerifier(_ context.Context, keyRef string, _ cosign.PassFunc) (signature.SignerVerifier, error) {
+	pkcs11UriConfig := pkcs11key.NewPkcs11UriConfig()
+	err := pkcs11UriConfig.Parse(keyRef)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing pkcs11 uri")
+	}
+
+	// Since we'll be signing, we need to set askForPinIsNeeded to true
+	// because we need access to the private key.
+	sk, err := pkcs11key.GetKeyWithURIConfig(pkcs11UriConfig, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening pkcs11 token key")
+	}
+
+	sv, err := sk.SignerVerifier()
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing pkcs11 token signer verifier")
+	}
+
+	return sv, nil
+}
+
+// Verifier implements KeyRefScheme
+func (pkcs11Scheme) Verifier(_ context.Context, keyRef string, _ crypto.Hash) (signature.Verifier, error) {
+	pkcs11UriConfig := pkcs11key.NewPkcs11UriConfig()
+	err := pkcs11UriConfig.Parse(keyRef)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing pkcs11 uri")
+	}
+
+	// Since we'll be verifying a signature, we do not need to set askForPinIsNeeded to true
+	// because we only need access to the public key.
+	sk, err := pkcs11key.GetKeyWithURIConfig(pkcs11UriConfig, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening pkcs11 token key")
+	}
+
+	v, err := sk.Verifier()
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing pkcs11 token verifier")
+	}
+
+	return v, nil
+}
+
+// kubernetesScheme furnishes the keys stored in Kubernetes secrets, referred
+// to as k8s://<namespace>/<name>.
+type kubernetesScheme struct{}
+
+// SignerVerifier implements KeyRefScheme
+func (kubernetesScheme) SignerVerifier(ctx context.Context, keyRef string, _ cosign.PassFunc) (signature.SignerVerifier, error) {
+	s, err := kubernetes.GetKeyPairSecret(ctx, keyRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.Data) == 0 {
+		return nil, errors.Errorf("secret %s has no data", keyRef)
+	}
+	return cosign.LoadPrivateKey(s.Data["cosign.key"], s.Data["cosign.password"])
+}
+
+// Verifier implements KeyRefScheme
+func (kubernetesScheme) Verifier(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
+	s, err := kubernetes.GetKeyPairSecret(ctx, keyRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.Data) == 0 {
+		return nil, errors.Errorf("secret %s has no data", keyRef)
+	}
+	// Accept the keys cosign.pub was rotated from until they expire.
+	pub, err := kubernetes.PublicKeys(s.Data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return loadPublicKeysRaw(pub, hashAlgorithm)
+}
+
+// gitScheme furnishes the keys stored in the secrets or variables of git
+// forges, referred to as <provider>://<project>.
+type gitScheme struct{}
+
+func splitGitRef(keyRef string) (git.Git, string, error) {
+	split := strings.Split(keyRef, "://")
+
+	if len(split) < 2 {
+		return nil, "", errors.New("could not parse scheme, use <scheme>://<ref> format")
+	}
+
+	provider, targetRef := split[0], split[1]
+	g := git.GetProvider(provider)
+	if g == nil {
+		return nil, "", errors.Errorf("unsupported git provider %q", provider)
+	}
+	return g, targetRef, nil
+}
+
+// SignerVerifier implements KeyRefScheme
+func (gitScheme) SignerVerifier(ctx context.Context, keyRef string, _ cosign.PassFunc) (signature.SignerVerifier, error) {
+	provider, targetRef, err := splitGitRef(keyRef)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := provider.GetSecret(ctx, targetRef, "COSIGN_PRIVATE_KEY")
+	if err != nil {
+		return nil, err
+	}
+	if pk == "" {
+		return nil, errors.Errorf("%s has an empty COSIGN_PRIVATE_KEY", keyRef)
+	}
+
+	pass, err := provider.GetSecret(ctx, targetRef, "COSIGN_PASSWORD")
+	if err != nil {
+		return nil, err
+	}
+
+	return cosign.LoadPrivateKey([]byte(pk), []byte(pass))
+}
+
+// Verifier implements KeyRefScheme
+func (gitScheme) Verifier(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
+	provider, targetRef, err := splitGitRef(keyRef)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := provider.GetPublicKey(ctx, targetRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pubKey) == 0 {
+		return nil, errors.Errorf("%s has an empty public key", keyRef)
+	}
+	return loadPublicKeysRaw(pubKey, hashAlgorithm)
+}
+
+// kmsScheme furnishes the keys of the KMS services sigstore supports.
+type kmsScheme struct{}
+
+// SignerVerifier implements KeyRefScheme
+func (kmsScheme) SignerVerifier(ctx context.Context, keyRef string, _ cosign.PassFunc) (signature.SignerVerifier, error) {
+	return kmsKey(ctx, keyRef, 0)
+}
+
+// Verifier implements KeyRefScheme
+func (kmsScheme) Verifier(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (signature.Verifier, error) {
+	return kmsKey(ctx, keyRef, hashAlgorithm)
+}
+
+// kmsKey returns the KMS key keyRef refers to, using hashAlgorithm, or if it is
+// zero, the hash algorithm matching the type of the key, see cosign.HashAlgorithmForKey.
+func kmsKey(ctx context.Context, keyRef string, hashAlgorithm crypto.Hash) (kms.SignerVerifier, error) {
+	if hashAlgorithm != 0 {
+		return kms.Get(ctx, keyRef, hashAlgorithm)
+	}
+	sv, err := kms.Get(ctx, keyRef, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := sv.PublicKey(options.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching KMS public key")
+	}
+	// KMS keys all sign digests, fall back to SHA-256 for any that wouldn't.
+	if h := cosign.HashAlgorithmForKey(pub); h != crypto.SHA256 && h != 0 {
+		return kms.Get(ctx, keyRef, h)
+	}
+	return sv, nil
+}
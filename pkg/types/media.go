@@ -22,4 +22,12 @@ const (
 	SPDXMediaType          = "text/spdx"
 	WasmLayerMediaType     = "application/vnd.wasm.content.layer.v1+wasm"
 	WasmConfigMediaType    = "application/vnd.wasm.config.v1+json"
+
+	HelmChartConfigMediaType          = "application/vnd.cncf.helm.config.v1+json"
+	HelmChartContentLayerMediaType    = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	HelmChartProvenanceLayerMediaType = "application/vnd.cncf.helm.chart.provenance.v1.prov"
+	OPABundleConfigMediaType          = "application/vnd.cncf.openpolicyagent.config.v1+json"
+	OPABundleLayerMediaType           = "application/vnd.cncf.openpolicyagent.layer.v1.tar+gzip"
+
+	RevocationListMediaType = "application/vnd.dev.cosign.revocation-list.v1+json"
 )
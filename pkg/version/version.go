@@ -47,6 +47,11 @@ type Info struct {
 	GoVersion    string
 	Compiler     string
 	Platform     string
+
+	// OIDCProviders are the providers of ambient OIDC credentials detected
+	// in this environment, in the order they are tried. String only lists
+	// them when they were set.
+	OIDCProviders []string
 }
 
 func GetVersionInfo() Info {
@@ -73,6 +78,13 @@ func (i *Info) String() string {
 	fmt.Fprintf(w, "GoVersion:\t%s\n", i.GoVersion)
 	fmt.Fprintf(w, "Compiler:\t%s\n", i.Compiler)
 	fmt.Fprintf(w, "Platform:\t%s\n", i.Platform)
+	if i.OIDCProviders != nil {
+		providers := "none"
+		if len(i.OIDCProviders) > 0 {
+			providers = strings.Join(i.OIDCProviders, ", ")
+		}
+		fmt.Fprintf(w, "OIDCProviders:\t%s\n", providers)
+	}
 
 	w.Flush()
 	return b.String()
@@ -19,9 +19,13 @@
 package test
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -50,6 +54,7 @@ import (
 	"github.com/sigstore/cosign/cmd/cosign/cli/generate"
 	"github.com/sigstore/cosign/cmd/cosign/cli/options"
 	"github.com/sigstore/cosign/cmd/cosign/cli/publickey"
+	"github.com/sigstore/cosign/cmd/cosign/cli/revocation"
 	"github.com/sigstore/cosign/cmd/cosign/cli/sign"
 	"github.com/sigstore/cosign/cmd/cosign/cli/upload"
 	cliverify "github.com/sigstore/cosign/cmd/cosign/cli/verify"
@@ -59,6 +64,8 @@ import (
 	ociremote "github.com/sigstore/cosign/pkg/oci/remote"
 	"github.com/sigstore/cosign/pkg/sget"
 	sigs "github.com/sigstore/cosign/pkg/signature"
+	"github.com/sigstore/cosign/pkg/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature/payload"
 )
 
@@ -768,6 +775,176 @@ func TestUploadBlob(t *testing.T) {
 	}
 }
 
+func TestUploadHelm(t *testing.T) {
+	repo, stop := reg(t)
+	defer stop()
+	td := t.TempDir()
+	ctx := context.Background()
+
+	imgName := path.Join(repo, "cosign-helm-e2e")
+	chartPath := filepath.Join(td, "mychart-0.1.0.tgz")
+	chart := &bytes.Buffer{}
+	gz := gzip.NewWriter(chart)
+	tw := tar.NewWriter(gz)
+	for _, f := range []struct{ name, content string }{
+		{"mychart/Chart.yaml", "apiVersion: v2\nname: mychart\nversion: 0.1.0\n"},
+		{"mychart/charts/dep/Chart.yaml", "apiVersion: v2\nname: dep\nversion: 1.0.0\n"},
+	} {
+		must(tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content)), Typeflag: tar.TypeReg}), t)
+		if _, err := tw.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(tw.Close(), t)
+	must(gz.Close(), t)
+	must(os.WriteFile(chartPath, chart.Bytes(), 0600), t)
+	provPath := mkfile("provenance", td, t)
+
+	// Upload it!
+	must(upload.HelmCmd(ctx, options.RegistryOptions{}, chartPath, provPath, imgName), t)
+
+	ref, err := name.ParseReference(imgName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := remote.Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(`{"apiVersion":"v2","name":"mychart","version":"0.1.0"}`, string(config)); diff != "" {
+		t.Error(diff)
+	}
+	dgst, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sign it, and verify it is a Helm chart
+	_, privKeyPath, pubKeyPath := keypair(t, td)
+	ko := sign.KeyOpts{KeyRef: privKeyPath, PassFunc: passFunc}
+	must(sign.SignCmd(ctx, ko, options.RegistryOptions{}, nil, []string{imgName}, "", true, "", "", "", false, false, ""), t)
+	verifyMediaType := func(mediaType string) error {
+		cmd := cliverify.VerifyCommand{
+			KeyRef:        pubKeyPath,
+			CheckClaims:   true,
+			HashAlgorithm: crypto.SHA256,
+			MediaType:     mediaType,
+		}
+		return cmd.Exec(ctx, []string{imgName})
+	}
+	must(verifyMediaType("helm"), t)
+	must(verifyMediaType(types.HelmChartProvenanceLayerMediaType), t)
+	mustErr(verifyMediaType("wasm"), t)
+	mustErr(verifyMediaType(types.SPDXMediaType), t)
+
+	// sget picks the chart out of the chart and provenance layers
+	result := &bytes.Buffer{}
+	must(sget.New(imgName+"@"+dgst.String(), "", result).Do(ctx), t)
+	if !bytes.Equal(result.Bytes(), chart.Bytes()) {
+		t.Error("expected sget to download the chart")
+	}
+}
+
+func TestSignVerifyRecursive(t *testing.T) {
+	repo, stop := reg(t)
+	defer stop()
+	td := t.TempDir()
+	ctx := context.Background()
+
+	imgName := path.Join(repo, "cosign-recursive-e2e")
+	_, _, cleanup := mkimageindex(t, imgName)
+	defer cleanup()
+
+	_, privKeyPath, pubKeyPath := keypair(t, td)
+	verifyRecursive := func(recursive bool) error {
+		cmd := cliverify.VerifyCommand{
+			KeyRef:        pubKeyPath,
+			CheckClaims:   true,
+			HashAlgorithm: crypto.SHA256,
+			Recursive:     recursive,
+		}
+		return cmd.Exec(ctx, []string{imgName})
+	}
+
+	// Sign only the index: its children are unsigned
+	ko := sign.KeyOpts{KeyRef: privKeyPath, PassFunc: passFunc}
+	must(sign.SignCmd(ctx, ko, options.RegistryOptions{}, nil, []string{imgName}, "", true, "", "", "", false, false, ""), t)
+	must(verifyRecursive(false), t)
+	mustErr(verifyRecursive(true), t)
+
+	// Now sign each child too
+	must(sign.SignCmd(ctx, ko, options.RegistryOptions{}, nil, []string{imgName}, "", true, "", "", "", false, true, ""), t)
+	must(verifyRecursive(true), t)
+}
+
+func TestSignVerifyRevocationList(t *testing.T) {
+	repo, stop := reg(t)
+	defer stop()
+	td := t.TempDir()
+	ctx := context.Background()
+
+	imgName := path.Join(repo, "cosign-revocation-e2e")
+	_, _, cleanup := mkimage(t, imgName)
+	defer cleanup()
+
+	_, privKeyPath, pubKeyPath := keypair(t, td)
+	ko := sign.KeyOpts{KeyRef: privKeyPath, PassFunc: passFunc}
+	must(sign.SignCmd(ctx, ko, options.RegistryOptions{}, nil, []string{imgName}, "", true, "", "", "", false, false, ""), t)
+
+	// Revoke everything signed with that key, in a list signed with another one
+	pemBytes, err := os.ReadFile(pubKeyPath)
+	must(err, t)
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(pemBytes)
+	must(err, t)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	must(err, t)
+	list := fmt.Sprintf(`{"revocations": [{"keyId": "%x", "reason": "key leaked"}]}`, sha256.Sum256(der))
+	listPath := filepath.Join(td, "revocations.json")
+	must(os.WriteFile(listPath, []byte(list), 0600), t)
+
+	listKeysDir := t.TempDir()
+	_, listPrivKeyPath, listPubKeyPath := keypair(t, listKeysDir)
+	var signed bytes.Buffer
+	must(revocation.SignCmd(ctx, sign.KeyOpts{KeyRef: listPrivKeyPath, PassFunc: passFunc}, listPath, cosign.DefaultRevocationListExpiry, &signed), t)
+	signedPath := filepath.Join(td, "revocations.signed.json")
+	must(os.WriteFile(signedPath, signed.Bytes(), 0600), t)
+
+	listName := path.Join(repo, "cosign-revocation-list-e2e")
+	must(revocation.UploadCmd(ctx, options.RegistryOptions{}, signedPath, listName), t)
+
+	verifyRevoked := func(o options.RevocationListOptions) error {
+		cmd := cliverify.VerifyCommand{
+			KeyRef:         pubKeyPath,
+			CheckClaims:    true,
+			HashAlgorithm:  crypto.SHA256,
+			RevocationList: o,
+		}
+		return cmd.Exec(ctx, []string{imgName})
+	}
+	must(verifyRevoked(options.RevocationListOptions{}), t)
+	mustErr(verifyRevoked(options.RevocationListOptions{Source: signedPath, Key: listPubKeyPath}), t)
+	mustErr(verifyRevoked(options.RevocationListOptions{Source: cosign.RevocationListSchemeOCI + listName, Key: listPubKeyPath}), t)
+
+	// A list that isn't signed by the given key is rejected too
+	mustErr(verifyRevoked(options.RevocationListOptions{Source: signedPath, Key: pubKeyPath}), t)
+
+	// A list revoking nothing accepts the signature, unless it is older than one already seen
+	emptyListPath := filepath.Join(td, "empty.json")
+	must(os.WriteFile(emptyListPath, []byte(`{"revocations": []}`), 0600), t)
+	var emptySigned bytes.Buffer
+	must(revocation.SignCmd(ctx, sign.KeyOpts{KeyRef: listPrivKeyPath, PassFunc: passFunc}, emptyListPath, cosign.DefaultRevocationListExpiry, &emptySigned), t)
+	emptySignedPath := filepath.Join(td, "empty.signed.json")
+	must(os.WriteFile(emptySignedPath, emptySigned.Bytes(), 0600), t)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	must(verifyRevoked(options.RevocationListOptions{Source: emptySignedPath, Key: listPubKeyPath, MinIssued: past}), t)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	mustErr(verifyRevoked(options.RevocationListOptions{Source: emptySignedPath, Key: listPubKeyPath, MinIssued: future}), t)
+}
+
 func TestSaveLoad(t *testing.T) {
 	tests := []struct {
 		description     string
@@ -810,8 +987,13 @@ func TestSaveLoad(t *testing.T) {
 
 			// load the image from the temp dir into a new image and verify the new image
 			imgName2 := path.Join(repo, fmt.Sprintf("save-load-%d-2", i))
-			must(cli.LoadCmd(ctx, options.LoadOptions{Directory: imageDir}, imgName2), t)
+			must(cli.LoadCmd(ctx, options.LoadOptions{Directory: imageDir, Key: pubKeyPath}, imgName2), t)
 			must(verify(pubKeyPath, imgName2, true, nil, ""), t)
+
+			// loading with a key that did not sign the image should fail
+			_, _, otherPubKeyPath := keypair(t, t.TempDir())
+			imgName3 := path.Join(repo, fmt.Sprintf("save-load-%d-3", i))
+			mustErr(cli.LoadCmd(ctx, options.LoadOptions{Directory: imageDir, Key: otherPubKeyPath}, imgName3), t)
 		})
 	}
 }
@@ -927,6 +1109,55 @@ func TestAttachSBOM(t *testing.T) {
 	mustErr(verify(pubKeyPath2, imgName, true, nil, "sbom"), t)
 }
 
+func TestAttachSignedSBOM(t *testing.T) {
+	repo, stop := reg(t)
+	defer stop()
+	ctx := context.Background()
+
+	imgName := path.Join(repo, "signed-sbom-image")
+	_, _, cleanup := mkimage(t, imgName)
+	defer cleanup()
+
+	td1 := t.TempDir()
+	td2 := t.TempDir()
+	_, privKeyPath1, pubKeyPath1 := keypair(t, td1)
+	_, _, pubKeyPath2 := keypair(t, td2)
+	verifySBOM := func(pubKeyPath string) ([]string, error) {
+		v := &cliverify.VerifyCommand{KeyRef: pubKeyPath, CheckClaims: true}
+		return download.VerifiedSBOMCmd(ctx, v, imgName, &bytes.Buffer{})
+	}
+
+	// Attach and sign the sbom with one key
+	ko1 := sign.KeyOpts{KeyRef: privKeyPath1, PassFunc: passFunc}
+	must(attach.SignedSBOMCmd(ctx, ko1, options.RegistryOptions{}, "./testdata/bom-go-mod.spdx", "spdx", imgName, "", false), t)
+
+	// Download should verify with that key, but not the other
+	sboms, err := verifySBOM(pubKeyPath1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("./testdata/bom-go-mod.spdx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sboms) != 1 || sboms[0] != string(want) {
+		t.Fatalf("Expected the attached sbom, got %v", sboms)
+	}
+	_, err = verifySBOM(pubKeyPath2)
+	mustErr(err, t)
+	must(verify(pubKeyPath1, imgName, true, nil, "sbom"), t)
+
+	// Replacing the sbom without signing it should fail verification
+	must(attach.SBOMCmd(ctx, options.RegistryOptions{}, "swapped", "spdx", imgName), t)
+	out := bytes.Buffer{}
+	if _, err := download.VerifiedSBOMCmd(ctx, &cliverify.VerifyCommand{KeyRef: pubKeyPath1, CheckClaims: true}, imgName, &out); err == nil {
+		t.Fatal("Expected error")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing written out, got %q", out.String())
+	}
+}
+
 func setenv(t *testing.T, k, v string) func() {
 	if err := os.Setenv(k, v); err != nil {
 		t.Fatalf("error setting env: %v", err)
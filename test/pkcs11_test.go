@@ -40,15 +40,22 @@ package test
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/hex"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	// Import the functions directly for testing.
 
@@ -274,6 +281,140 @@ func TestSignAndVerify(t *testing.T) {
 	}
 }
 
+func findTokenSlot(t *testing.T) uint {
+	t.Helper()
+
+	tokens, err := GetTokens(context.Background(), modulePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range tokens {
+		if token.TokenInfo.Label == tokenLabel {
+			return token.Slot
+		}
+	}
+	t.Fatalf("token with label '%s' not found", tokenLabel)
+	return 0
+}
+
+func TestGenerateKeyCmd(t *testing.T) {
+	ctx := context.Background()
+	slotID := findTokenSlot(t)
+
+	for _, alg := range pkcs11key.KeyAlgorithms {
+		t.Run(alg, func(t *testing.T) {
+			if err := GenerateKeyCmd(ctx, modulePath, slotID, pin, keyLabel, keyID, alg); err != nil {
+				t.Fatal(err)
+			}
+			defer deleteKey(slotID)
+
+			// The key is not replaced.
+			if err := GenerateKeyCmd(ctx, modulePath, slotID, pin, keyLabel, keyID, alg); err == nil {
+				t.Fatal("GenerateKeyCmd() replaced an existing key")
+			}
+
+			pkcs11UriConfig := pkcs11key.NewPkcs11UriConfig()
+			must(pkcs11UriConfig.Parse(uri), t)
+			sk, err := pkcs11key.GetKeyWithURIConfig(pkcs11UriConfig, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sk.Close()
+
+			sv, err := sk.SignerVerifier()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig, err := sv.SignMessage(bytes.NewReader([]byte("hello, world!")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sv.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte("hello, world!"))); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestImportCertCmd(t *testing.T) {
+	ctx := context.Background()
+	slotID := findTokenSlot(t)
+
+	if err := GenerateKeyCmd(ctx, modulePath, slotID, pin, keyLabel, keyID, "ecdsa-p256"); err != nil {
+		t.Fatal(err)
+	}
+	defer deleteKey(slotID)
+	defer deleteObjects(slotID, pkcs11.CKO_CERTIFICATE)
+
+	pkcs11UriConfig := pkcs11key.NewPkcs11UriConfig()
+	must(pkcs11UriConfig.Parse(uri), t)
+	sk, err := pkcs11key.GetKeyWithURIConfig(pkcs11UriConfig, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := sk.PublicKey()
+	sk.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootCert, rootKey, err := GenerateRootCa()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCert := func(pub interface{}) string {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "cosign"},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, rootCert, pub, rootKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "cert.pem")
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportCertCmd(ctx, modulePath, slotID, pin, keyLabel, "", writeCert(&other.PublicKey), false); err == nil {
+		t.Fatal("ImportCertCmd() imported the certificate of another key")
+	}
+
+	for _, force := range []bool{false, true} {
+		certPath := writeCert(pub)
+		if err := ImportCertCmd(ctx, modulePath, slotID, pin, keyLabel, "", certPath, force); err != nil {
+			t.Fatal(err)
+		}
+
+		sk, err := pkcs11key.GetKeyWithURIConfig(pkcs11UriConfig, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := sk.Certificate()
+		sk.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := os.ReadFile(certPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cert == nil || !bytes.Equal(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), want) {
+			t.Fatal("imported certificate not found with the key")
+		}
+	}
+}
+
 var newPublicKeyAttrs = []*pkcs11.Attribute{
 	pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
 	pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
@@ -350,7 +491,7 @@ func importKey(slotID uint) error {
 	keyLabelBytes := []byte(keyLabel)
 
 	r := strings.NewReader(rsaPrivKey)
-	pemBytes, err = os.ReadAll(r)
+	pemBytes, err = io.ReadAll(r)
 	if err != nil {
 		return fmt.Errorf("unable to read pem")
 	}
@@ -400,6 +541,55 @@ func importKey(slotID uint) error {
 	return nil
 }
 
+func deleteObjects(slotID uint, class uint) error {
+	ctx, err := initPKCS11(modulePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx.Finalize()
+		ctx.Destroy()
+	}()
+
+	keyIDBytes, err := hex.DecodeString(keyID)
+	if err != nil {
+		return err
+	}
+
+	session, err := ctx.OpenSession(slotID, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return fmt.Errorf("unable to open session")
+	}
+	defer ctx.CloseSession(session)
+	err = ctx.Login(session, pkcs11.CKU_USER, pin)
+	if err != nil {
+		return fmt.Errorf("unable to login")
+	}
+	defer ctx.Logout(session)
+
+	attrs := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, keyIDBytes),
+	}
+	if err = ctx.FindObjectsInit(session, attrs); err != nil {
+		return fmt.Errorf("unable to initialize find objects")
+	}
+	handles, _, err := ctx.FindObjects(session, 20)
+	if err != nil {
+		return fmt.Errorf("unable to find objects")
+	}
+	if err = ctx.FindObjectsFinal(session); err != nil {
+		return fmt.Errorf("unable to finalize find objects")
+	}
+	for _, handle := range handles {
+		if err := ctx.DestroyObject(session, handle); err != nil {
+			return fmt.Errorf("unable to destroy object")
+		}
+	}
+
+	return nil
+}
+
 func deleteKey(slotID uint) error {
 	var handles []pkcs11.ObjectHandle
 